@@ -1,19 +1,21 @@
 package cmd
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	"capi-advisor/pkg/advisor"
 	"capi-advisor/pkg/analyzer"
 	"capi-advisor/pkg/client"
-	"capi-advisor/pkg/tree"
+	"capi-advisor/pkg/render"
+	"capi-advisor/pkg/report"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
+	"sigs.k8s.io/yaml"
 )
 
 var (
@@ -35,87 +37,159 @@ for resolving any issues.`,
 func init() {
 	analyzeCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace to analyze (empty for all namespaces)")
 	analyzeCmd.Flags().StringVarP(&clusterName, "cluster", "c", "", "CAPI cluster name to analyze (empty for all clusters)")
-	analyzeCmd.Flags().StringVarP(&outputFormat, "output", "o", "report", "Output format: report, json, yaml")
+	analyzeCmd.Flags().StringVarP(&outputFormat, "output", "o", "report", "Output format: report, json, yaml, sarif, junit, html, markdown")
 	analyzeCmd.Flags().BoolVar(&showTree, "tree", false, "Show component dependency tree")
+	addDiscoveryFlags(analyzeCmd)
+	addGateFlags(analyzeCmd)
 }
 
 func runAnalyze(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cmd.Context()
+	if _, err := failOnSeverity(); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	// Keep stdout parseable for structured formats
+	progress := progressWriter(outputFormat)
 
 	// Create Kubernetes client
-	fmt.Println("🔗 Connecting to Kubernetes cluster...")
+	fmt.Fprintln(progress, "🔗 Connecting to Kubernetes cluster...")
 	k8sClient, err := client.NewK8sClient()
 	if err != nil {
-		return fmt.Errorf("failed to create Kubernetes client: %v", err)
+		return &ExitError{Code: ExitConnectionError, Err: fmt.Errorf("failed to create Kubernetes client: %v", err)}
 	}
 
 	// Get cluster info
 	clusterInfo, err := k8sClient.GetClusterInfo(ctx)
 	if err != nil {
-		fmt.Printf("Warning: could not get cluster info: %v\n", err)
+		fmt.Fprintf(progress, "Warning: could not get cluster info: %v\n", err)
 	} else {
-		fmt.Printf("📡 Connected to %s\n\n", clusterInfo)
+		fmt.Fprintf(progress, "📡 Connected to %s\n\n", clusterInfo)
 	}
 
-	// Discover components
-	fmt.Println("🔍 Discovering Cluster API and Metal3 components...")
-	discovery := analyzer.NewComponentDiscovery(k8sClient.Client)
-	components, err := discovery.DiscoverComponents(ctx, namespace, clusterName)
-	if err != nil {
-		return fmt.Errorf("failed to discover components: %v", err)
+	run, gateErr := analyzeUntilReady(ctx, k8sClient, progress, progress)
+	if run == nil {
+		return gateErr
 	}
 
-	if len(components) == 0 {
-		fmt.Println("ℹ️  No Cluster API or Metal3 components found in the specified namespace")
-		return nil
+	if len(run.components) == 0 && progress == stdout {
+		fmt.Fprintln(stdout, "ℹ️  No Cluster API or Metal3 components found in the specified namespace")
+		printIncompleteDiscovery(stdout, run.outcomes)
+		return gateErr
 	}
 
-	fmt.Printf("✅ Found %d components\n\n", len(components))
-
-	// Build dependency tree
-	fmt.Println("🌳 Building component dependency tree...")
-	treeBuilder := tree.NewTreeBuilder()
-	rootComponents := treeBuilder.BuildDependencyTree(components)
-
-	// Analyze components
-	fmt.Println("🔬 Analyzing component conditions...")
-	advisor := advisor.NewAdvisor()
-	result := advisor.AnalyzeComponents(components)
-
 	// Output results
+	result := run.result
 	switch outputFormat {
 	case "json":
-		return outputJSON(result)
+		err = outputJSON(newDocument(result, run.roots, k8sClient.Context))
 	case "yaml":
-		return outputYAML(result)
+		err = outputYAML(newDocument(result, run.roots, k8sClient.Context))
+	case "sarif":
+		err = outputSARIF(result, run.advisor)
+	case "junit":
+		err = outputJUnit(result)
+	case "html":
+		// Show the recent events of every component with an issue
+		var issueComponents []*analyzer.Component
+		for _, issue := range result.Issues {
+			issueComponents = append(issueComponents, issue.Component)
+		}
+		newDiscovery(k8sClient.Client).ResolveEvents(ctx, issueComponents)
+		err = outputHTML(run, k8sClient.Context)
+	case "markdown":
+		outputMarkdown(run, k8sClient.Context)
 	case "report":
 		fallthrough
 	default:
-		report := advisor.GenerateReport(result)
-		fmt.Print(report)
+		report := run.advisor.GenerateReport(result)
+		fmt.Fprint(stdout, report)
 
 		if showTree {
-			fmt.Println("\n🌳 COMPONENT DEPENDENCY TREE")
-			fmt.Println(strings.Repeat("=", 50))
-			tree := treeBuilder.PrintTree(rootComponents)
-			fmt.Print(tree)
+			fmt.Fprintln(stdout, "\n🌳 COMPONENT DEPENDENCY TREE")
+			fmt.Fprintln(stdout, strings.Repeat("=", 50))
+			tree := run.treeBuilder.PrintTree(run.roots)
+			fmt.Fprint(stdout, tree)
 		}
 	}
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return gateErr
+}
+
+// newDocument wraps a result in the versioned output document.
+func newDocument(result *analyzer.AnalysisResult, roots []*analyzer.Component, kubeContext string) *report.Document {
+	return report.New(result, roots, newMetadata(kubeContext))
+}
+
+// newMetadata describes the current run for the output documents.
+func newMetadata(kubeContext string) report.Metadata {
+	return report.Metadata{
+		GeneratedAt: time.Now().UTC(),
+		KubeContext: kubeContext,
+		Filters: report.Filters{
+			Namespace: namespace,
+			Cluster:   clusterName,
+		},
+	}
 }
 
-func outputJSON(result *analyzer.AnalysisResult) error {
+func outputJSON(doc *report.Document) error {
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(result)
+	return encoder.Encode(doc)
+}
+
+func outputSARIF(result *analyzer.AnalysisResult, adv *advisor.Advisor) error {
+	data, err := report.SARIF(result, adv)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func outputHTML(run *analysisRun, kubeContext string) error {
+	data, err := report.HTML(run.result, run.treeBuilder, run.roots, newMetadata(kubeContext))
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+func outputMarkdown(run *analysisRun, kubeContext string) {
+	fmt.Print(report.Markdown(run.result, run.treeBuilder, run.roots, newMetadata(kubeContext)))
+}
+
+func outputJUnit(result *analyzer.AnalysisResult) error {
+	data, err := report.JUnit(result)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(data))
+	return nil
 }
 
-func outputYAML(result *analyzer.AnalysisResult) error {
-	data, err := yaml.Marshal(result)
+// progressWriter returns where progress messages go: stderr for machine
+// readable formats so stdout stays parseable.
+func progressWriter(format string) io.Writer {
+	switch format {
+	case "json", "yaml", "sarif", "junit", "html", "markdown":
+		return render.NewWriter(os.Stderr)
+	default:
+		return stdout
+	}
+}
+
+func outputYAML(doc *report.Document) error {
+	data, err := yaml.Marshal(doc)
 	if err != nil {
 		return err
 	}
 	fmt.Print(string(data))
 	return nil
-}
\ No newline at end of file
+}
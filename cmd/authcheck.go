@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/auth"
+	"capi-advisor/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+var authCheckCmd = &cobra.Command{
+	Use:   "auth-check",
+	Short: "Check the permissions the advisor needs",
+	Long: `Check with SelfSubjectAccessReviews whether the current identity may get,
+list and watch every kind the advisor discovers, read BMC credential Secrets,
+and read the events, controller logs and nodes that resolutions point to.
+Prints a matrix of allowed and denied verbs per namespace and a ClusterRole
+or Role manifest covering the missing permissions.
+
+Exits with code 4 when permissions are missing, as an analysis would be
+incomplete without them.`,
+	RunE: runAuthCheck,
+}
+
+var (
+	authNamespaces   []string
+	authOutputFormat string
+	authRoleName     string
+)
+
+func init() {
+	authCheckCmd.Flags().StringSliceVarP(&authNamespaces, "namespace", "n", nil, "Namespaces to check, repeatable (empty for all namespaces)")
+	authCheckCmd.Flags().StringVarP(&authOutputFormat, "output", "o", "table", "Output format: table, json, yaml (manifest only)")
+	authCheckCmd.Flags().StringVar(&authRoleName, "role-name", "capi-advisor", "Name of the ClusterRole and Roles in the manifest")
+	authCheckCmd.Flags().IntVar(&discoveryConcurrency, "concurrency", analyzer.DefaultConcurrency, "Number of access reviews issued in parallel")
+}
+
+// authCheckReport is the JSON representation of the auth-check output.
+type authCheckReport struct {
+	Checks       []auth.Check `json:"checks"`
+	NotInstalled []string     `json:"notInstalled,omitempty"`
+	Manifest     string       `json:"manifest,omitempty"`
+}
+
+func runAuthCheck(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	switch authOutputFormat {
+	case "table", "json", "yaml":
+	default:
+		return fmt.Errorf("invalid output format %q: must be table, json or yaml", authOutputFormat)
+	}
+	cmd.SilenceUsage = true
+
+	// Create Kubernetes client
+	k8sClient, err := client.NewK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+
+	requirements := auth.Requirements(k8sClient.Client.RESTMapper())
+	checks := auth.CheckPermissions(ctx, k8sClient.Clientset, requirements, authNamespaces, discoveryConcurrency)
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("permission check interrupted: %v", err)
+	}
+
+	manifest, err := auth.Manifest(checks, authRoleName)
+	if err != nil {
+		return fmt.Errorf("failed to render manifest: %v", err)
+	}
+
+	var notInstalled []string
+	for _, requirement := range requirements {
+		if requirement.NotInstalled {
+			notInstalled = append(notInstalled, requirement.Kind)
+		}
+	}
+	missing := 0
+	for _, check := range checks {
+		missing += len(check.Missing())
+	}
+	// Without the missing permissions an analysis is incomplete
+	var missingErr error
+	if missing > 0 {
+		missingErr = &ExitError{Code: ExitIncomplete, Err: fmt.Errorf("%d permission(s) missing", missing)}
+	}
+
+	switch authOutputFormat {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(authCheckReport{Checks: checks, NotInstalled: notInstalled, Manifest: string(manifest)}); err != nil {
+			return err
+		}
+		return missingErr
+	case "yaml":
+		fmt.Print(string(manifest))
+		return missingErr
+	}
+
+	fmt.Fprintln(stdout, "🔐 PERMISSION CHECK")
+	fmt.Fprintln(stdout, "===================")
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tRESOURCE\tFEATURE\tGET\tLIST\tWATCH")
+	for _, check := range checks {
+		resource := check.FullResource()
+		if check.Group != "" {
+			resource += "." + check.Group
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", namespaceOrAll(check), resource, check.Feature,
+			verbCell(check, "get"), verbCell(check, "list"), verbCell(check, "watch"))
+	}
+	w.Flush()
+
+	for _, check := range checks {
+		verbs := make([]string, 0, len(check.Errors))
+		for verb := range check.Errors {
+			verbs = append(verbs, verb)
+		}
+		sort.Strings(verbs)
+		for _, verb := range verbs {
+			fmt.Fprintf(stdout, "⚠️  Could not review %s %s: %s\n", verb, check.FullResource(), check.Errors[verb])
+		}
+	}
+
+	if len(notInstalled) > 0 {
+		fmt.Fprintf(stdout, "\nℹ️  Skipped %d kinds whose CRD is not installed: %s\n", len(notInstalled), strings.Join(notInstalled, ", "))
+	}
+
+	if missing == 0 {
+		fmt.Fprintln(stdout, "\n✅ All required permissions are granted")
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "\n❌ %d permission(s) missing. Apply this manifest and bind it to the identity running the advisor,\n", missing)
+	fmt.Fprintf(stdout, "   e.g. kubectl create clusterrolebinding %s --clusterrole=%s --serviceaccount=<namespace>:<name>\n\n", authRoleName, authRoleName)
+	fmt.Fprint(stdout, string(manifest))
+
+	return missingErr
+}
+
+func namespaceOrAll(check auth.Check) string {
+	switch {
+	case !check.Namespaced:
+		return "(cluster)"
+	case check.Namespace == "":
+		return "(all)"
+	default:
+		return check.Namespace
+	}
+}
+
+func verbCell(check auth.Check, verb string) string {
+	for _, required := range check.Verbs {
+		if required == verb {
+			if check.Allowed[verb] {
+				return "✅"
+			}
+			return "❌"
+		}
+	}
+	return "-"
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"capi-advisor/pkg/advisor"
+	"capi-advisor/pkg/analyzer"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	discoveryConcurrency int
+	discoveryPageSize    int64
+	requestTimeout       time.Duration
+)
+
+// addDiscoveryFlags adds the flags tuning component discovery to a command.
+func addDiscoveryFlags(c *cobra.Command) {
+	c.Flags().IntVar(&discoveryConcurrency, "concurrency", analyzer.DefaultConcurrency, "Number of kinds listed in parallel")
+	c.Flags().Int64Var(&discoveryPageSize, "page-size", analyzer.DefaultPageSize, "Number of objects requested per list call (0 disables pagination)")
+	c.Flags().DurationVar(&requestTimeout, "request-timeout", analyzer.DefaultRequestTimeout, "Timeout of a single API request (0 for no timeout)")
+}
+
+// newDiscovery creates a component discovery configured from the flags.
+func newDiscovery(c client.Client) *analyzer.ComponentDiscovery {
+	discovery := analyzer.NewComponentDiscovery(c)
+	discovery.Concurrency = discoveryConcurrency
+	discovery.PageSize = discoveryPageSize
+	discovery.RequestTimeout = requestTimeout
+	return discovery
+}
+
+// printIncompleteDiscovery warns that kinds could not be listed, so the
+// output lacks their objects.
+func printIncompleteDiscovery(w io.Writer, outcomes []analyzer.GVKDiscovery) {
+	if len(analyzer.IncompleteDiscovery(outcomes)) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprint(w, advisor.DiscoveryReport(outcomes))
+	}
+}
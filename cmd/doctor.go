@@ -1,12 +1,11 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
+	"io"
 
-	"capi-advisor/pkg/advisor"
-	"capi-advisor/pkg/analyzer"
 	"capi-advisor/pkg/client"
+	"capi-advisor/pkg/render"
 
 	"github.com/spf13/cobra"
 )
@@ -19,114 +18,109 @@ This command focuses on identifying and providing solutions for issues.`,
 	RunE: runDoctor,
 }
 
+var doctorOutputFormat string
+
 func init() {
+	doctorCmd.Flags().StringVarP(&doctorOutputFormat, "output", "o", "text", "Output format: text, sarif, junit, markdown")
 	doctorCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace to analyze (empty for all namespaces)")
 	doctorCmd.Flags().StringVarP(&clusterName, "cluster", "c", "", "CAPI cluster name to analyze (empty for all clusters)")
+	addDiscoveryFlags(doctorCmd)
+	addGateFlags(doctorCmd)
 }
 
 func runDoctor(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cmd.Context()
+	if _, err := failOnSeverity(); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	progress := progressWriter(doctorOutputFormat)
 
-	fmt.Println("🏥 Running cluster health diagnostics...")
-	fmt.Println("======================================")
+	fmt.Fprintln(progress, "🏥 Running cluster health diagnostics...")
+	fmt.Fprintln(progress, "======================================")
 
 	// Create Kubernetes client
 	k8sClient, err := client.NewK8sClient()
 	if err != nil {
-		return fmt.Errorf("failed to create Kubernetes client: %v", err)
+		return &ExitError{Code: ExitConnectionError, Err: fmt.Errorf("failed to create Kubernetes client: %v", err)}
 	}
 
 	// Get cluster info
 	clusterInfo, err := k8sClient.GetClusterInfo(ctx)
 	if err != nil {
-		fmt.Printf("⚠️  Warning: could not get cluster info: %v\n", err)
+		fmt.Fprintf(progress, "⚠️  Warning: could not get cluster info: %v\n", err)
 	} else {
-		fmt.Printf("📡 Cluster: %s\n", clusterInfo)
+		fmt.Fprintf(progress, "📡 Cluster: %s\n", clusterInfo)
 	}
 
-	// Discover components
-	discovery := analyzer.NewComponentDiscovery(k8sClient.Client)
-	components, err := discovery.DiscoverComponents(ctx, namespace, clusterName)
-	if err != nil {
-		return fmt.Errorf("failed to discover components: %v", err)
+	run, gateErr := analyzeUntilReady(ctx, k8sClient, io.Discard, progress)
+	if run == nil {
+		return gateErr
 	}
+	components, outcomes, result := run.components, run.outcomes, run.result
 
-	if len(components) == 0 {
-		fmt.Println("\n✅ No Cluster API or Metal3 components found - nothing to diagnose")
-		return nil
+	if len(components) == 0 && progress == stdout {
+		fmt.Fprintln(stdout, "\n✅ No Cluster API or Metal3 components found - nothing to diagnose")
+		printIncompleteDiscovery(stdout, outcomes)
+		return gateErr
 	}
 
-	// Analyze components
-	advisor := advisor.NewAdvisor()
-	result := advisor.AnalyzeComponents(components)
+	switch doctorOutputFormat {
+	case "sarif":
+		if err := outputSARIF(result, run.advisor); err != nil {
+			return err
+		}
+		return gateErr
+	case "junit":
+		if err := outputJUnit(result); err != nil {
+			return err
+		}
+		return gateErr
+	case "markdown":
+		outputMarkdown(run, k8sClient.Context)
+		return gateErr
+	}
 
 	// Generate focused health report
-	fmt.Printf("\n🔍 Analyzed %d components\n", len(components))
+	fmt.Fprintf(stdout, "\n🔍 Analyzed %d components\n", len(components))
+	printIncompleteDiscovery(stdout, outcomes)
 
 	if len(result.Issues) == 0 {
-		fmt.Println("\n🎉 Excellent! No issues found.")
-		fmt.Println("All Cluster API and Metal3 components are healthy.")
+		fmt.Fprintln(stdout, "\n🎉 Excellent! No issues found.")
+		fmt.Fprintln(stdout, "All Cluster API and Metal3 components are healthy.")
 	} else {
-		fmt.Printf("\n🚨 Found %d issue(s) that need attention:\n", len(result.Issues))
+		fmt.Fprintf(stdout, "\n🚨 Found %d issue(s) that need attention:\n", len(result.Issues))
 
 		for i, issue := range result.Issues {
-			severityIcon := getSeverityIcon(issue.Severity)
-			fmt.Printf("\n%d. %s %s\n", i+1, severityIcon, issue.Description)
-			fmt.Printf("   📍 Component: %s/%s (namespace: %s)\n",
+			severityIcon := render.SeverityIcon(issue.Severity)
+			fmt.Fprintf(stdout, "\n%d. %s %s\n", i+1, severityIcon, render.Severity(issue.Severity, issue.Description))
+			fmt.Fprintf(stdout, "   📍 Component: %s/%s (namespace: %s)\n",
 				issue.Component.Type, issue.Component.Name, issue.Component.Namespace)
 
 			if issue.Condition.Message != "" {
-				fmt.Printf("   📝 Message: %s\n", issue.Condition.Message)
+				fmt.Fprintln(stdout, render.Wrap("   📝 Message: ", issue.Condition.Message, "      "))
 			}
 
-			fmt.Printf("   🔍 Cause: %s\n", issue.Cause)
-			fmt.Printf("   💡 Resolution: %s\n", issue.Resolution)
+			fmt.Fprintln(stdout, render.Wrap("   🔍 Cause: ", issue.Cause, "      "))
+			fmt.Fprintln(stdout, render.Wrap("   💡 Resolution: ", render.Resolution(issue.Resolution), "      "))
 
 			if len(issue.Dependencies) > 0 {
-				fmt.Println("   🔗 Dependencies to check:")
+				fmt.Fprintln(stdout, "   🔗 Dependencies to check:")
 				for _, dep := range issue.Dependencies {
-					depStatus := getStatusIcon(dep.Status)
-					fmt.Printf("      %s %s/%s\n", depStatus, dep.Type, dep.Name)
+					depStatus := render.StatusIcon(dep.Status)
+					fmt.Fprintf(stdout, "      %s %s/%s\n", depStatus, dep.Type, render.Status(dep.Status, dep.Name))
 				}
 			}
 		}
 
-		fmt.Printf("\n📊 Summary by severity:\n")
+		fmt.Fprintf(stdout, "\n📊 Summary by severity:\n")
 		for severity, count := range result.Summary.SeverityCounts {
 			if count > 0 {
-				icon := getSeverityIcon(severity)
-				fmt.Printf("   %s %s: %d\n", icon, severity, count)
+				icon := render.SeverityIcon(severity)
+				fmt.Fprintf(stdout, "   %s %s: %d\n", icon, render.Severity(severity, string(severity)), count)
 			}
 		}
 	}
 
-	return nil
+	return gateErr
 }
-
-func getSeverityIcon(severity analyzer.ConditionSeverity) string {
-	switch severity {
-	case analyzer.SeverityCritical:
-		return "🔴"
-	case analyzer.SeverityWarning:
-		return "🟡"
-	case analyzer.SeverityInfo:
-		return "🔵"
-	default:
-		return "⚪"
-	}
-}
-
-func getStatusIcon(status analyzer.ComponentStatus) string {
-	switch status {
-	case analyzer.StatusHealthy:
-		return "✅"
-	case analyzer.StatusDegraded:
-		return "⚠️"
-	case analyzer.StatusFailed:
-		return "❌"
-	case analyzer.StatusPending:
-		return "⏳"
-	default:
-		return "❓"
-	}
-}
\ No newline at end of file
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"capi-advisor/pkg/advisor"
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/client"
+	"capi-advisor/pkg/tree"
+
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <kind>/<name>",
+	Short: "Explain the state of a single component",
+	Long: `Deep dive into a single component: walk up to its Cluster and down to the
+objects it depends on, such as the BareMetalHost and bootstrap config of a
+Machine, and print its conditions with their age, the events of the chain,
+the knowledge base rules that matched or nearly matched, the controller
+responsible for it and a step-by-step resolution.
+
+The kind is matched like the type of get, e.g. machine/worker-0 or
+bmh/host-3.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExplain,
+}
+
+func init() {
+	explainCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace of the component (empty to search all namespaces)")
+	addDiscoveryFlags(explainCmd)
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if _, _, err := parseComponentRef(args[0]); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	// Create Kubernetes client
+	k8sClient, err := client.NewK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+
+	// The whole namespace is discovered since the chain may include objects
+	// of no Cluster such as BareMetalHosts, and checks span components
+	discovery := newDiscovery(k8sClient.Client)
+	components, outcomes, err := discovery.DiscoverComponents(ctx, namespace, "")
+	if err != nil {
+		return fmt.Errorf("failed to discover components: %v", err)
+	}
+	treeBuilder := tree.NewTreeBuilder()
+	treeBuilder.BuildDependencyTree(components)
+
+	comp, err := findComponentRef(args[0], components)
+	if err != nil {
+		printIncompleteDiscovery(stdout, outcomes)
+		return err
+	}
+
+	adv := advisor.NewAdvisor()
+	adv.SetLinks(treeBuilder)
+	adv.SetDiscovery(outcomes)
+	result := adv.AnalyzeComponents(components)
+	explanation := adv.Explain(comp, result)
+	discovery.ResolveEvents(ctx, explanation.Chain())
+
+	fmt.Fprint(stdout, explanation.Report())
+	printIncompleteDiscovery(stdout, outcomes)
+
+	return nil
+}
+
+// parseComponentRef splits a "kind/name" argument.
+func parseComponentRef(ref string) (kind, name string, err error) {
+	kind, name, found := strings.Cut(ref, "/")
+	if !found || kind == "" || name == "" {
+		return "", "", fmt.Errorf("invalid component %q: must be <kind>/<name>", ref)
+	}
+	return kind, name, nil
+}
+
+// findComponentRef returns the component named by a "kind/name" argument.
+// A name found in several namespaces needs --namespace to pick one.
+func findComponentRef(ref string, components []*analyzer.Component) (*analyzer.Component, error) {
+	kind, name, err := parseComponentRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	compType, err := resolveComponentType(kind, components)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*analyzer.Component
+	for _, comp := range components {
+		if comp.Type == compType && comp.Name == name {
+			found = append(found, comp)
+		}
+	}
+	switch len(found) {
+	case 0:
+		if namespace != "" {
+			return nil, fmt.Errorf("%s/%s not found in namespace %s", compType, name, namespace)
+		}
+		return nil, fmt.Errorf("%s/%s not found", compType, name)
+	case 1:
+		return found[0], nil
+	}
+	namespaces := make([]string, 0, len(found))
+	for _, comp := range found {
+		namespaces = append(namespaces, comp.Namespace)
+	}
+	return nil, fmt.Errorf("%s/%s exists in namespaces %s, select one with --namespace",
+		compType, name, strings.Join(namespaces, ", "))
+}
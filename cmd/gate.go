@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"capi-advisor/pkg/advisor"
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/client"
+	"capi-advisor/pkg/tree"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of analyze, doctor and auth-check, so pipelines can gate on the
+// result.
+const (
+	ExitHealthy         = 0
+	ExitFailure         = 1
+	ExitWarnings        = 2
+	ExitCritical        = 3
+	ExitIncomplete      = 4
+	ExitConnectionError = 5
+)
+
+// ExitError ends the command with a specific exit code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	failOn       string
+	waitReady    bool
+	waitTimeout  time.Duration
+	waitInterval time.Duration
+)
+
+// addGateFlags adds the flags deciding the exit code of a command.
+func addGateFlags(c *cobra.Command) {
+	c.Flags().StringVar(&failOn, "fail-on", "critical", "Lowest issue severity that fails the command: critical, warning, info (failing warning and info issues both exit with code 2)")
+	c.Flags().BoolVar(&waitReady, "wait", false, "Re-run the analysis until no issue fails it or --timeout passes")
+	c.Flags().DurationVar(&waitTimeout, "timeout", 30*time.Minute, "How long --wait re-runs the analysis")
+	c.Flags().DurationVar(&waitInterval, "interval", 30*time.Second, "Time between analyses with --wait")
+}
+
+// severityRank orders severities from the most to the least severe.
+var severityRank = map[analyzer.ConditionSeverity]int{
+	analyzer.SeverityCritical: 0,
+	analyzer.SeverityWarning:  1,
+	analyzer.SeverityInfo:     2,
+}
+
+func failOnSeverity() (analyzer.ConditionSeverity, error) {
+	switch failOn {
+	case "critical":
+		return analyzer.SeverityCritical, nil
+	case "warning":
+		return analyzer.SeverityWarning, nil
+	case "info":
+		return analyzer.SeverityInfo, nil
+	default:
+		return "", fmt.Errorf("invalid --fail-on %q: must be critical, warning or info", failOn)
+	}
+}
+
+// analysisRun is the outcome of discovering and analyzing the cluster once.
+type analysisRun struct {
+	components  []*analyzer.Component
+	roots       []*analyzer.Component
+	treeBuilder *tree.TreeBuilder
+	advisor     *advisor.Advisor
+	result      *analyzer.AnalysisResult
+	outcomes    []analyzer.GVKDiscovery
+}
+
+// analyzeCluster discovers, links and analyzes all components, reporting
+// each step to steps.
+func analyzeCluster(ctx context.Context, k8sClient *client.K8sClient, steps io.Writer) (*analysisRun, error) {
+	fmt.Fprintln(steps, "🔍 Discovering Cluster API and Metal3 components...")
+	discovery := newDiscovery(k8sClient.Client)
+	components, outcomes, err := discovery.DiscoverComponents(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover components: %v", err)
+	}
+	fmt.Fprintf(steps, "✅ Found %d components\n\n", len(components))
+
+	// Build dependency tree
+	fmt.Fprintln(steps, "🌳 Building component dependency tree...")
+	treeBuilder := tree.NewTreeBuilder()
+	roots := treeBuilder.BuildDependencyTree(components)
+
+	// Analyze components
+	fmt.Fprintln(steps, "🔬 Analyzing component conditions...")
+	adv := advisor.NewAdvisor()
+	adv.SetLinks(treeBuilder)
+	adv.SetDiscovery(outcomes)
+	result := adv.AnalyzeComponents(components)
+	adv.AnalyzeDiscovery(result, outcomes)
+
+	return &analysisRun{
+		components:  components,
+		roots:       roots,
+		treeBuilder: treeBuilder,
+		advisor:     adv,
+		result:      result,
+		outcomes:    outcomes,
+	}, nil
+}
+
+// analyzeUntilReady analyzes the cluster once, or with --wait until the
+// analysis passes the gate or the timeout expires. The timeout also bounds
+// an analysis in progress. The last analysis is returned with its gate
+// error, nil when it passed.
+func analyzeUntilReady(ctx context.Context, k8sClient *client.K8sClient, steps, progress io.Writer) (*analysisRun, error) {
+	threshold, err := failOnSeverity()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(waitTimeout)
+	if waitReady {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	var last *analysisRun
+	var lastGateErr error
+	for {
+		run, err := analyzeCluster(ctx, k8sClient, steps)
+		if err != nil {
+			if waitReady && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				if last != nil {
+					return last, fmt.Errorf("timed out after %s: %w", waitTimeout, lastGateErr)
+				}
+				return nil, fmt.Errorf("timed out after %s: %v", waitTimeout, err)
+			}
+			return nil, err
+		}
+
+		gateErr := run.gate(threshold)
+		if !waitReady {
+			return run, gateErr
+		}
+		// With --wait nothing found yet means the cluster is not created yet
+		if gateErr == nil && len(run.components) == 0 {
+			gateErr = &ExitError{Code: ExitIncomplete, Err: fmt.Errorf("no components found")}
+		}
+		if gateErr == nil {
+			return run, nil
+		}
+		last, lastGateErr = run, gateErr
+
+		if time.Now().Add(waitInterval).After(deadline) {
+			return run, fmt.Errorf("timed out after %s: %w", waitTimeout, gateErr)
+		}
+		fmt.Fprintf(progress, "⏳ Not ready: %v, analyzing again in %s\n", gateErr, waitInterval)
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return run, fmt.Errorf("timed out after %s: %w", waitTimeout, gateErr)
+			}
+			return run, fmt.Errorf("wait interrupted: %w", gateErr)
+		case <-time.After(waitInterval):
+		}
+	}
+}
+
+// gate decides whether an analysis passes with issues up to threshold. A
+// cluster that cannot be reached fails first, then Critical issues, then an
+// incomplete analysis since it may hide issues, then issues at threshold.
+func (run *analysisRun) gate(threshold analyzer.ConditionSeverity) error {
+	if unreachable(run.outcomes) {
+		return &ExitError{Code: ExitConnectionError, Err: fmt.Errorf("could not list any kind from the API server")}
+	}
+
+	counts := run.result.Summary.SeverityCounts
+	if counts[analyzer.SeverityCritical] > 0 {
+		return &ExitError{Code: ExitCritical, Err: fmt.Errorf("%d critical issue(s) found", counts[analyzer.SeverityCritical])}
+	}
+
+	if incomplete := analyzer.IncompleteDiscovery(run.outcomes); len(incomplete) > 0 {
+		return &ExitError{Code: ExitIncomplete, Err: fmt.Errorf("analysis incomplete, %d kind(s) could not be listed", len(incomplete))}
+	}
+
+	failing := 0
+	for severity, count := range counts {
+		if severityRank[severity] <= severityRank[threshold] {
+			failing += count
+		}
+	}
+	if failing > 0 {
+		return &ExitError{Code: ExitWarnings, Err: fmt.Errorf("%d issue(s) at or above %s found", failing, threshold)}
+	}
+
+	return nil
+}
+
+// unreachable reports whether listing failed for every kind, which means
+// the API server could not be reached rather than single kinds failing.
+func unreachable(outcomes []analyzer.GVKDiscovery) bool {
+	if len(outcomes) == 0 {
+		return false
+	}
+	for _, outcome := range outcomes {
+		if outcome.Outcome != analyzer.DiscoveryFailed && outcome.Outcome != analyzer.DiscoveryTimeout {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"capi-advisor/pkg/analyzer"
+)
+
+func TestGate(t *testing.T) {
+	listed := []analyzer.GVKDiscovery{
+		{Type: analyzer.ClusterType, Outcome: analyzer.DiscoveryOK},
+		{Type: analyzer.BareMetalHostType, Outcome: analyzer.DiscoveryNotInstalled},
+	}
+	incomplete := []analyzer.GVKDiscovery{
+		{Type: analyzer.ClusterType, Outcome: analyzer.DiscoveryOK},
+		{Type: analyzer.MachineType, Outcome: analyzer.DiscoveryForbidden},
+	}
+	unreachable := []analyzer.GVKDiscovery{
+		{Type: analyzer.ClusterType, Outcome: analyzer.DiscoveryFailed},
+		{Type: analyzer.MachineType, Outcome: analyzer.DiscoveryTimeout},
+	}
+
+	tests := []struct {
+		name      string
+		outcomes  []analyzer.GVKDiscovery
+		counts    map[analyzer.ConditionSeverity]int
+		threshold analyzer.ConditionSeverity
+		wantCode  int
+	}{
+		{name: "no issues", outcomes: listed, threshold: analyzer.SeverityCritical, wantCode: ExitHealthy},
+		{name: "no outcomes", threshold: analyzer.SeverityCritical, wantCode: ExitHealthy},
+		{name: "warnings below threshold", outcomes: listed, counts: map[analyzer.ConditionSeverity]int{analyzer.SeverityWarning: 2}, threshold: analyzer.SeverityCritical, wantCode: ExitHealthy},
+		{name: "warnings at threshold", outcomes: listed, counts: map[analyzer.ConditionSeverity]int{analyzer.SeverityWarning: 2}, threshold: analyzer.SeverityWarning, wantCode: ExitWarnings},
+		{name: "info below threshold", outcomes: listed, counts: map[analyzer.ConditionSeverity]int{analyzer.SeverityInfo: 1}, threshold: analyzer.SeverityWarning, wantCode: ExitHealthy},
+		{name: "info at threshold", outcomes: listed, counts: map[analyzer.ConditionSeverity]int{analyzer.SeverityInfo: 1}, threshold: analyzer.SeverityInfo, wantCode: ExitWarnings},
+		{name: "critical", outcomes: listed, counts: map[analyzer.ConditionSeverity]int{analyzer.SeverityCritical: 1, analyzer.SeverityWarning: 1}, threshold: analyzer.SeverityWarning, wantCode: ExitCritical},
+		{name: "incomplete", outcomes: incomplete, counts: map[analyzer.ConditionSeverity]int{analyzer.SeverityWarning: 1}, threshold: analyzer.SeverityWarning, wantCode: ExitIncomplete},
+		{name: "critical before incomplete", outcomes: incomplete, counts: map[analyzer.ConditionSeverity]int{analyzer.SeverityCritical: 1}, threshold: analyzer.SeverityCritical, wantCode: ExitCritical},
+		{name: "unreachable", outcomes: unreachable, counts: map[analyzer.ConditionSeverity]int{analyzer.SeverityCritical: 1}, threshold: analyzer.SeverityCritical, wantCode: ExitConnectionError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := tt.counts
+			if counts == nil {
+				counts = map[analyzer.ConditionSeverity]int{}
+			}
+			run := &analysisRun{
+				result:   &analyzer.AnalysisResult{Summary: analyzer.Summary{SeverityCounts: counts}},
+				outcomes: tt.outcomes,
+			}
+
+			err := run.gate(tt.threshold)
+			code := ExitHealthy
+			var exitErr *ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.Code
+			} else if err != nil {
+				t.Fatalf("gate() = %v, want an *ExitError", err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("gate() exit code = %d, want %d (%v)", code, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestFailOnSeverity(t *testing.T) {
+	tests := []struct {
+		failOn  string
+		want    analyzer.ConditionSeverity
+		wantErr bool
+	}{
+		{failOn: "critical", want: analyzer.SeverityCritical},
+		{failOn: "warning", want: analyzer.SeverityWarning},
+		{failOn: "info", want: analyzer.SeverityInfo},
+		{failOn: "Warning", wantErr: true},
+		{failOn: "", wantErr: true},
+	}
+
+	defer func(saved string) { failOn = saved }(failOn)
+	for _, tt := range tests {
+		t.Run(tt.failOn, func(t *testing.T) {
+			failOn = tt.failOn
+			got, err := failOnSeverity()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("failOnSeverity() error = %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("failOnSeverity() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
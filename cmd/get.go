@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/client"
+	"capi-advisor/pkg/render"
+	"capi-advisor/pkg/tree"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+var (
+	getOutputFormat string
+	getSortBy       string
+	getSelector     string
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get [type]",
+	Short: "List components in a table",
+	Long: `List discovered Cluster API and Metal3 components in a kubectl-style
+table with their cluster, status, phase and age, without the advisory report.
+
+The type is a kind such as Machine, its plural or a short name: bmh, m3m,
+kcp, md, ms, mp. Without a type all components are listed.
+
+Examples:
+  capi-advisor get machines -c my-cluster --sort-by status
+  capi-advisor get bmh -o wide -l rack=r12`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runGet,
+}
+
+func init() {
+	getCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace to list (empty for all namespaces)")
+	getCmd.Flags().StringVarP(&clusterName, "cluster", "c", "", "CAPI cluster name to list (empty for all clusters)")
+	getCmd.Flags().StringVarP(&getOutputFormat, "output", "o", "", "Output format: wide adds failing conditions and bindings")
+	getCmd.Flags().StringVar(&getSortBy, "sort-by", "", "Sort by column: namespace, cluster, type, name, status, phase, age")
+	getCmd.Flags().StringVarP(&getSelector, "selector", "l", "", "Label selector to filter on, e.g. rack=r12,!maintenance")
+	addDiscoveryFlags(getCmd)
+}
+
+// shortNames are the abbreviations accepted as type of get.
+var shortNames = map[string]analyzer.ComponentType{
+	"bmh": analyzer.BareMetalHostType,
+	"m3m": analyzer.Metal3MachineType,
+	"kcp": analyzer.KubeadmControlPlaneType,
+	"md":  analyzer.MachineDeploymentType,
+	"ms":  analyzer.MachineSetType,
+	"mp":  analyzer.MachinePoolType,
+	"cc":  analyzer.ClusterClassType,
+}
+
+// componentRow is a row of the get table.
+type componentRow struct {
+	comp    *analyzer.Component
+	cluster string
+	phase   string
+	created time.Time
+	failing []string
+	binding string
+}
+
+func runGet(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	selector, err := labels.Parse(getSelector)
+	if err != nil {
+		return fmt.Errorf("invalid --selector %q: %v", getSelector, err)
+	}
+	switch getOutputFormat {
+	case "", "wide":
+	default:
+		return fmt.Errorf("invalid output format %q: must be wide or empty", getOutputFormat)
+	}
+	less, err := rowOrder(getSortBy)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	// Create Kubernetes client
+	k8sClient, err := client.NewK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+
+	// Discover components and link them to find clusters and bindings
+	discovery := newDiscovery(k8sClient.Client)
+	components, outcomes, err := discovery.DiscoverComponents(ctx, namespace, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to discover components: %v", err)
+	}
+	tree.NewTreeBuilder().BuildDependencyTree(components)
+
+	var compType analyzer.ComponentType
+	if len(args) > 0 {
+		if compType, err = resolveComponentType(args[0], components); err != nil {
+			return err
+		}
+	}
+
+	var rows []componentRow
+	for _, comp := range components {
+		if compType != "" && comp.Type != compType {
+			continue
+		}
+		if !selector.Matches(labels.Set(analyzer.Labels(comp))) {
+			continue
+		}
+		rows = append(rows, newComponentRow(comp))
+	}
+
+	if len(rows) == 0 {
+		fmt.Fprintln(stdout, "No resources found")
+		printIncompleteDiscovery(stdout, outcomes)
+		return nil
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return less(rows[i], rows[j])
+	})
+
+	wide := getOutputFormat == "wide"
+	w := tabwriter.NewWriter(stdout, 0, 0, 3, ' ', 0)
+	header := []string{"NAMESPACE", "CLUSTER"}
+	if compType == "" {
+		header = append(header, "TYPE")
+	}
+	header = append(header, "NAME", "STATUS", "PHASE", "AGE")
+	if wide {
+		header = append(header, "FAILING CONDITIONS", "BINDING")
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	now := time.Now()
+	for _, row := range rows {
+		cells := []string{valueOrDash(row.comp.Namespace), valueOrDash(row.cluster)}
+		if compType == "" {
+			cells = append(cells, string(row.comp.Type))
+		}
+		age := "<unknown>"
+		if !row.created.IsZero() {
+			age = duration.HumanDuration(now.Sub(row.created))
+		}
+		cells = append(cells, row.comp.Name, render.Status(row.comp.Status, string(row.comp.Status)),
+			valueOrDash(row.phase), age)
+		if wide {
+			cells = append(cells, valueOrDash(strings.Join(row.failing, ",")), valueOrDash(row.binding))
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+	printIncompleteDiscovery(stdout, outcomes)
+
+	return nil
+}
+
+// resolveComponentType returns the kind named by a kind, its plural or a
+// short name, matched case-insensitively.
+func resolveComponentType(name string, components []*analyzer.Component) (analyzer.ComponentType, error) {
+	if compType, found := shortNames[strings.ToLower(name)]; found {
+		return compType, nil
+	}
+
+	// Kinds fetched through references are not in SupportedGVKs
+	known := make(map[analyzer.ComponentType]bool)
+	for compType := range analyzer.SupportedGVKs {
+		known[compType] = true
+	}
+	for _, comp := range components {
+		known[comp.Type] = true
+	}
+	for compType := range known {
+		kind := strings.ToLower(string(compType))
+		switch strings.ToLower(name) {
+		case kind, kind + "s", kind + "es":
+			return compType, nil
+		}
+	}
+	return "", fmt.Errorf("unknown component type %q", name)
+}
+
+func newComponentRow(comp *analyzer.Component) componentRow {
+	row := componentRow{
+		comp:    comp,
+		cluster: analyzer.ClusterName(comp),
+		phase:   analyzer.Phase(comp),
+		created: analyzer.CreationTimestamp(comp),
+		binding: componentBinding(comp),
+	}
+	// Objects without clusterName belong to the Cluster they are linked to
+	for ancestor := comp.Parent; row.cluster == "" && ancestor != nil; ancestor = ancestor.Parent {
+		if ancestor.Type == analyzer.ClusterType {
+			row.cluster = ancestor.Name
+		}
+	}
+	for _, condition := range comp.Conditions {
+		if condition.Status == metav1.ConditionFalse {
+			row.failing = append(row.failing, condition.Type)
+		}
+	}
+	return row
+}
+
+// componentBinding returns what a Machine, Metal3Machine or BareMetalHost
+// is bound to: the Node of a Machine, the host of a Metal3Machine and the
+// consumer of a host.
+func componentBinding(comp *analyzer.Component) string {
+	switch comp.Type {
+	case analyzer.MachineType:
+		if node := analyzer.NodeName(comp); node != "" {
+			return "Node/" + node
+		}
+	case analyzer.Metal3MachineType:
+		if _, host := analyzer.AnnotatedHost(comp); host != "" {
+			return string(analyzer.BareMetalHostType) + "/" + host
+		}
+		// Fall back to the host linked in the tree through its consumerRef
+		for _, child := range comp.Children {
+			if child.Type == analyzer.BareMetalHostType {
+				return string(analyzer.BareMetalHostType) + "/" + child.Name
+			}
+		}
+	case analyzer.BareMetalHostType:
+		if kind, _, name := analyzer.HostConsumer(comp); name != "" {
+			return kind + "/" + name
+		}
+	}
+	return ""
+}
+
+// statusOrder sorts the statuses needing attention first.
+var statusOrder = map[analyzer.ComponentStatus]int{
+	analyzer.StatusFailed:   0,
+	analyzer.StatusDegraded: 1,
+	analyzer.StatusPending:  2,
+	analyzer.StatusUnknown:  3,
+	analyzer.StatusHealthy:  4,
+}
+
+// rowOrder returns how rows are sorted by a column. Ties and the default
+// order are namespace, type and name.
+func rowOrder(column string) (func(a, b componentRow) bool, error) {
+	byIdentity := func(a, b componentRow) bool {
+		if a.comp.Namespace != b.comp.Namespace {
+			return a.comp.Namespace < b.comp.Namespace
+		}
+		if a.comp.Type != b.comp.Type {
+			return a.comp.Type < b.comp.Type
+		}
+		return a.comp.Name < b.comp.Name
+	}
+	then := func(less func(a, b componentRow) bool, equal func(a, b componentRow) bool) func(a, b componentRow) bool {
+		return func(a, b componentRow) bool {
+			if !equal(a, b) {
+				return less(a, b)
+			}
+			return byIdentity(a, b)
+		}
+	}
+
+	switch column {
+	case "", "namespace":
+		return byIdentity, nil
+	case "cluster":
+		return then(func(a, b componentRow) bool { return a.cluster < b.cluster },
+			func(a, b componentRow) bool { return a.cluster == b.cluster }), nil
+	case "type":
+		return then(func(a, b componentRow) bool { return a.comp.Type < b.comp.Type },
+			func(a, b componentRow) bool { return a.comp.Type == b.comp.Type }), nil
+	case "name":
+		return then(func(a, b componentRow) bool { return a.comp.Name < b.comp.Name },
+			func(a, b componentRow) bool { return a.comp.Name == b.comp.Name }), nil
+	case "status":
+		return then(func(a, b componentRow) bool { return statusOrder[a.comp.Status] < statusOrder[b.comp.Status] },
+			func(a, b componentRow) bool { return a.comp.Status == b.comp.Status }), nil
+	case "phase":
+		return then(func(a, b componentRow) bool { return a.phase < b.phase },
+			func(a, b componentRow) bool { return a.phase == b.phase }), nil
+	case "age":
+		// Oldest first, like kubectl --sort-by=.metadata.creationTimestamp
+		return then(func(a, b componentRow) bool { return a.created.Before(b.created) },
+			func(a, b componentRow) bool { return a.created.Equal(b.created) }), nil
+	default:
+		return nil, fmt.Errorf("invalid --sort-by %q: must be namespace, cluster, type, name, status, phase or age", column)
+	}
+}
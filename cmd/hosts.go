@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/client"
+	"capi-advisor/pkg/inventory"
+	"capi-advisor/pkg/render"
+
+	"github.com/spf13/cobra"
+)
+
+var hostsCmd = &cobra.Command{
+	Use:   "hosts",
+	Short: "Show BareMetalHost hardware inventory and host fit",
+	Long: `Display the hardware inventory of all BareMetalHosts (CPU, RAM, disks, NICs,
+BMC, firmware, provisioning state and consumer) and explain for every
+Metal3Machine without a host which available hosts match its hostSelector
+and root device hints. With --firmware, firmware versions are listed and
+compared between hosts of the same hardware model.`,
+	RunE: runHosts,
+}
+
+var (
+	hostsOutputFormat string
+	hostsFirmware     bool
+)
+
+func init() {
+	hostsCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace to analyze (empty for all namespaces)")
+	hostsCmd.Flags().StringVarP(&hostsOutputFormat, "output", "o", "table", "Output format: table, json")
+	hostsCmd.Flags().BoolVar(&hostsFirmware, "firmware", false, "Show firmware versions and drift between hosts of the same model")
+	addDiscoveryFlags(hostsCmd)
+}
+
+// hostsReport is the JSON representation of the hosts command output.
+type hostsReport struct {
+	Hosts    []*inventory.Host       `json:"hosts"`
+	Fits     []machineFit            `json:"fits"`
+	Firmware map[string]hostFirmware `json:"firmware,omitempty"`
+	Drift    []inventory.Drift       `json:"drift,omitempty"`
+	// Discovery lists the kinds that could not be listed
+	Discovery []analyzer.GVKDiscovery `json:"discovery,omitempty"`
+}
+
+type hostFirmware struct {
+	Versions map[string]string `json:"versions"`
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+type machineFit struct {
+	Namespace     string   `json:"namespace"`
+	Metal3Machine string   `json:"metal3Machine"`
+	Summary       string   `json:"summary"`
+	MatchingHosts []string `json:"matchingHosts"`
+	inventory.Fit
+}
+
+func runHosts(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	switch hostsOutputFormat {
+	case "table", "json":
+	default:
+		return fmt.Errorf("invalid output format %q: must be table or json", hostsOutputFormat)
+	}
+	cmd.SilenceUsage = true
+
+	// Create Kubernetes client
+	k8sClient, err := client.NewK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+
+	// Discover components
+	discovery := newDiscovery(k8sClient.Client)
+	components, outcomes, err := discovery.DiscoverComponents(ctx, namespace, "")
+	if err != nil {
+		return fmt.Errorf("failed to discover components: %v", err)
+	}
+
+	hosts := inventory.BuildInventory(components)
+	var fits []machineFit
+	for _, machine := range inventory.UnboundMachines(components) {
+		fit := inventory.CheckFit(machine, hosts)
+		var matching []string
+		for _, host := range fit.Matching {
+			matching = append(matching, host.Name)
+		}
+		fits = append(fits, machineFit{
+			Namespace:     machine.Namespace,
+			Metal3Machine: machine.Name,
+			Summary:       fit.Summary(),
+			MatchingHosts: matching,
+			Fit:           fit,
+		})
+	}
+
+	report := hostsReport{Hosts: hosts, Fits: fits, Discovery: analyzer.IncompleteDiscovery(outcomes)}
+	if hostsFirmware {
+		report.Firmware = make(map[string]hostFirmware)
+		for _, host := range hosts {
+			report.Firmware[host.Namespace+"/"+host.Name] = hostFirmware{
+				Versions: inventory.FirmwareVersions(host, components),
+				Settings: inventory.FirmwareSettings(host, components),
+			}
+		}
+		report.Drift = inventory.FirmwareDrift(hosts, components)
+	}
+
+	if hostsOutputFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	if len(hosts) == 0 {
+		fmt.Fprintln(stdout, "ℹ️  No BareMetalHosts found")
+		printIncompleteDiscovery(stdout, outcomes)
+		return nil
+	}
+
+	fmt.Fprintln(stdout, "🖥️  BAREMETALHOST INVENTORY")
+	fmt.Fprintln(stdout, "==========================")
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tSTATE\tCONSUMER\tBMC\tMODEL\tCPU\tRAM\tDISKS\tNICS\tFIRMWARE")
+	for _, host := range hosts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			host.Namespace, host.Name, valueOrDash(host.ProvisioningState), valueOrDash(host.Consumer),
+			valueOrDash(host.BMCType), valueOrDash(host.Model()), formatCPU(host), formatRAM(host),
+			formatDisks(host), formatNICs(host), valueOrDash(strings.TrimSpace(host.BIOSVendor+" "+host.BIOSVersion)))
+	}
+	w.Flush()
+
+	if len(fits) > 0 {
+		fmt.Fprintln(stdout, "\n🔎 HOST FIT FOR UNBOUND METAL3MACHINES")
+		fmt.Fprintln(stdout, "======================================")
+		for _, fit := range fits {
+			icon := render.StatusIcon(analyzer.StatusHealthy)
+			if len(fit.MatchingHosts) == 0 {
+				icon = render.StatusIcon(analyzer.StatusFailed)
+			}
+			fmt.Fprintf(stdout, "%s Metal3Machine/%s (namespace: %s)\n", icon, fit.Metal3Machine, fit.Namespace)
+			fmt.Fprintf(stdout, "   %s\n", fit.Summary)
+			for _, e := range fit.Errors {
+				fmt.Fprintf(stdout, "   ⚠️  Invalid hostSelector: %s\n", e)
+			}
+			if len(fit.MatchingHosts) > 0 {
+				fmt.Fprintf(stdout, "   Matching hosts: %s\n", strings.Join(fit.MatchingHosts, ", "))
+			}
+		}
+	}
+
+	if hostsFirmware {
+		printFirmware(hosts, report)
+	}
+	printIncompleteDiscovery(stdout, outcomes)
+
+	return nil
+}
+
+func printFirmware(hosts []*inventory.Host, report hostsReport) {
+	fmt.Fprintln(stdout, "\n🔧 FIRMWARE VERSIONS")
+	fmt.Fprintln(stdout, "====================")
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tMODEL\tFIRMWARE")
+	for _, host := range hosts {
+		versions := report.Firmware[host.Namespace+"/"+host.Name].Versions
+		items := make([]string, 0, len(versions))
+		for item, version := range versions {
+			items = append(items, item+"="+version)
+		}
+		sort.Strings(items)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", host.Namespace, host.Name, valueOrDash(host.Model()), valueOrDash(strings.Join(items, ", ")))
+	}
+	w.Flush()
+
+	if len(report.Drift) == 0 {
+		fmt.Fprintln(stdout, "\n✅ No firmware drift between hosts of the same model")
+		return
+	}
+
+	fmt.Fprintln(stdout, "\n⚠️  FIRMWARE DRIFT")
+	fmt.Fprintln(stdout, "=================")
+	for _, drift := range report.Drift {
+		fmt.Fprintf(stdout, "%s in pool %s (majority: %s)\n", drift.Item, drift.Pool, drift.Majority)
+		values := make([]string, 0, len(drift.Values))
+		for value := range drift.Values {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			fmt.Fprintf(stdout, "   %s: %s\n", value, strings.Join(drift.Values[value], ", "))
+		}
+	}
+}
+
+func formatCPU(host *inventory.Host) string {
+	if !host.HasInventory {
+		return "-"
+	}
+	return fmt.Sprintf("%dx %s", host.CPUCount, valueOrDash(host.CPUArch))
+}
+
+func formatRAM(host *inventory.Host) string {
+	if !host.HasInventory {
+		return "-"
+	}
+	return fmt.Sprintf("%dGi", host.RAMMebibytes/1024)
+}
+
+func formatDisks(host *inventory.Host) string {
+	if !host.HasInventory {
+		return "-"
+	}
+	var sizes []string
+	for _, disk := range host.Disks {
+		sizes = append(sizes, formatBytes(disk.SizeBytes))
+	}
+	return valueOrDash(strings.Join(sizes, "+"))
+}
+
+func formatNICs(host *inventory.Host) string {
+	if !host.HasInventory {
+		return "-"
+	}
+	var nics []string
+	for _, nic := range host.NICs {
+		if nic.SpeedGbps > 0 {
+			nics = append(nics, fmt.Sprintf("%s(%dG)", nic.Name, nic.SpeedGbps))
+		} else {
+			nics = append(nics, nic.Name)
+		}
+	}
+	return valueOrDash(strings.Join(nics, ","))
+}
+
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.0f%ci", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
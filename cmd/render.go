@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"capi-advisor/pkg/render"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	noEmoji   bool
+	colorMode string
+)
+
+// stdout is where commands write text output, rendered in the style chosen
+// by the flags. Machine readable output is written to os.Stdout directly.
+var stdout io.Writer = os.Stdout
+
+// AddRenderFlags adds the flags deciding how text output looks to the root
+// command, for all commands.
+func AddRenderFlags(root *cobra.Command) {
+	root.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false, "Print plain words instead of emoji icons")
+	root.PersistentFlags().StringVar(&colorMode, "color", render.ColorAuto, "Colour output: auto (when writing to a terminal and NO_COLOR is unset), always, never")
+	root.PersistentPreRunE = configureRendering
+}
+
+func configureRendering(cmd *cobra.Command, args []string) error {
+	switch colorMode {
+	case render.ColorAuto, render.ColorAlways, render.ColorNever:
+	default:
+		return fmt.Errorf("invalid --color %q: must be auto, always or never", colorMode)
+	}
+
+	render.Configure(render.Detect(os.Stdout, colorMode, !noEmoji))
+	stdout = render.NewWriter(os.Stdout)
+	return nil
+}
@@ -2,7 +2,12 @@ package cmd
 
 // Export commands for main.go
 var (
-	AnalyzeCmd = analyzeCmd
-	DoctorCmd  = doctorCmd
-	TreeCmd    = treeCmd
+	AnalyzeCmd   = analyzeCmd
+	DoctorCmd    = doctorCmd
+	TreeCmd      = treeCmd
+	HostsCmd     = hostsCmd
+	AuthCheckCmd = authCheckCmd
+	SchemaCmd    = schemaCmd
+	GetCmd       = getCmd
+	ExplainCmd   = explainCmd
 )
\ No newline at end of file
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"os"
+
+	"capi-advisor/pkg/report"
+
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the JSON and YAML output",
+	Long: `Print the JSON Schema of the document written by analyze -o json and
+analyze -o yaml, so downstream tooling can validate it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := os.Stdout.Write(report.JSONSchema())
+		return err
+	},
+}
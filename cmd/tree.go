@@ -1,31 +1,106 @@
 package cmd
 
 import (
-	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"capi-advisor/pkg/analyzer"
 	"capi-advisor/pkg/client"
+	"capi-advisor/pkg/render"
+	"capi-advisor/pkg/report"
 	"capi-advisor/pkg/tree"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	treeOutputFormat string
+	treeGroupBy      string
+	treePathTo       string
+	treeImpact       string
+	treeOptions      tree.PrintOptions
+	treeTypes        []string
+	treeExcludeTypes []string
+)
+
 var treeCmd = &cobra.Command{
 	Use:   "tree",
 	Short: "Show component dependency tree",
 	Long: `Display the dependency tree of Cluster API and Metal3 components,
-showing the hierarchical relationships between clusters, machines, and infrastructure.`,
+showing the hierarchical relationships between clusters, machines, and infrastructure.
+
+The tree can be exported as a Graphviz DOT or Mermaid diagram, or as a JSON
+graph of nodes and labelled edges, for incident docs and wiki pages:
+
+  capi-advisor tree -o dot | dot -Tsvg > tree.svg
+  capi-advisor tree -o mermaid --group-by cluster
+
+Queries on the tree print the chain from a component up to its Cluster, or
+what fails along with a component:
+
+  capi-advisor tree --path-to machine/worker-0
+  capi-advisor tree --impact bmh/host-3
+
+On large clusters the text tree can be narrowed to what needs attention:
+
+  capi-advisor tree --only-failing --show-conditions=failing
+  capi-advisor tree --collapse-healthy --depth 3 --exclude-type HardwareData`,
 	RunE: runTree,
 }
 
 func init() {
 	treeCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace to analyze (empty for all namespaces)")
 	treeCmd.Flags().StringVarP(&clusterName, "cluster", "c", "", "CAPI cluster name to analyze (empty for all clusters)")
+	treeCmd.Flags().StringVarP(&treeOutputFormat, "output", "o", "text", "Output format: text, dot, mermaid, json-graph")
+	treeCmd.Flags().StringVar(&treeGroupBy, "group-by", "", "Cluster the nodes of dot, mermaid and json-graph output by: namespace, cluster")
+	treeCmd.Flags().StringVar(&treePathTo, "path-to", "", "Print the chain from a <kind>/<name> component up to its Cluster")
+	treeCmd.Flags().StringVar(&treeImpact, "impact", "", "Print the components affected if a <kind>/<name> component fails or is deleted")
+	treeCmd.Flags().BoolVar(&treeOptions.OnlyFailing, "only-failing", false, "Print only the paths leading to components that are not Healthy")
+	treeCmd.Flags().BoolVar(&treeOptions.CollapseHealthy, "collapse-healthy", false, "Replace subtrees without unhealthy components by a count")
+	treeCmd.Flags().IntVar(&treeOptions.Depth, "depth", 0, "Number of tree levels to print (0 for all)")
+	treeCmd.Flags().StringVar(&treeOptions.ShowConditions, "show-conditions", tree.ShowConditionsAll, "Conditions to print: none, failing, all")
+	treeCmd.Flags().StringSliceVar(&treeTypes, "type", nil, "Print only the paths leading to components of these kinds")
+	treeCmd.Flags().StringSliceVar(&treeExcludeTypes, "exclude-type", nil, "Hide components of these kinds with their subtrees")
+	addDiscoveryFlags(treeCmd)
 }
 
 func runTree(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cmd.Context()
+
+	switch treeOutputFormat {
+	case "text", "dot", "mermaid", "json-graph":
+	default:
+		return fmt.Errorf("invalid output format %q: must be text, dot, mermaid or json-graph", treeOutputFormat)
+	}
+	switch treeGroupBy {
+	case "", report.GroupByNamespace, report.GroupByCluster:
+	default:
+		return fmt.Errorf("invalid --group-by %q: must be namespace or cluster", treeGroupBy)
+	}
+	switch treeOptions.ShowConditions {
+	case tree.ShowConditionsAll, tree.ShowConditionsFailing, tree.ShowConditionsNone:
+	default:
+		return fmt.Errorf("invalid --show-conditions %q: must be none, failing or all", treeOptions.ShowConditions)
+	}
+	if treeOptions.Depth < 0 {
+		return fmt.Errorf("invalid --depth %d: must not be negative", treeOptions.Depth)
+	}
+	if treePathTo != "" && treeImpact != "" {
+		return fmt.Errorf("--path-to and --impact cannot be combined")
+	}
+	if (treePathTo != "" || treeImpact != "") && treeOutputFormat != "text" {
+		return fmt.Errorf("--path-to and --impact only support text output")
+	}
+	for _, ref := range []string{treePathTo, treeImpact} {
+		if ref == "" {
+			continue
+		}
+		if _, _, err := parseComponentRef(ref); err != nil {
+			return err
+		}
+	}
+	cmd.SilenceUsage = true
 
 	// Create Kubernetes client
 	k8sClient, err := client.NewK8sClient()
@@ -34,25 +109,92 @@ func runTree(cmd *cobra.Command, args []string) error {
 	}
 
 	// Discover components
-	discovery := analyzer.NewComponentDiscovery(k8sClient.Client)
-	components, err := discovery.DiscoverComponents(ctx, namespace, clusterName)
+	discovery := newDiscovery(k8sClient.Client)
+	components, outcomes, err := discovery.DiscoverComponents(ctx, namespace, clusterName)
 	if err != nil {
 		return fmt.Errorf("failed to discover components: %v", err)
 	}
 
+	// Build dependency tree
+	treeBuilder := tree.NewTreeBuilder()
+	rootComponents := treeBuilder.BuildDependencyTree(components)
+
+	if treeOutputFormat != "text" {
+		// Keep stdout a valid diagram, the warnings go to stderr
+		printIncompleteDiscovery(render.NewWriter(os.Stderr), outcomes)
+		return outputGraph(report.NewGraph(treeBuilder, rootComponents, treeGroupBy))
+	}
+
+	if treePathTo != "" || treeImpact != "" {
+		return outputTreeQuery(treeBuilder, components, outcomes)
+	}
+
 	if len(components) == 0 {
-		fmt.Println("ℹ️  No Cluster API or Metal3 components found")
+		fmt.Fprintln(stdout, "ℹ️  No Cluster API or Metal3 components found")
+		printIncompleteDiscovery(stdout, outcomes)
 		return nil
 	}
 
-	// Build and print dependency tree
-	treeBuilder := tree.NewTreeBuilder()
-	rootComponents := treeBuilder.BuildDependencyTree(components)
+	for _, name := range treeTypes {
+		compType, err := resolveComponentType(name, components)
+		if err != nil {
+			return err
+		}
+		treeOptions.Types = append(treeOptions.Types, compType)
+	}
+	for _, name := range treeExcludeTypes {
+		compType, err := resolveComponentType(name, components)
+		if err != nil {
+			return err
+		}
+		treeOptions.ExcludeTypes = append(treeOptions.ExcludeTypes, compType)
+	}
+	treeBuilder.PrintOptions = treeOptions
 
-	fmt.Println("🌳 COMPONENT DEPENDENCY TREE")
-	fmt.Println("============================")
+	fmt.Fprintln(stdout, "🌳 COMPONENT DEPENDENCY TREE")
+	fmt.Fprintln(stdout, "============================")
 	tree := treeBuilder.PrintTree(rootComponents)
-	fmt.Print(tree)
+	fmt.Fprint(stdout, tree)
+	printIncompleteDiscovery(stdout, outcomes)
+
+	return nil
+}
 
+func outputGraph(graph *report.Graph) error {
+	switch treeOutputFormat {
+	case "dot":
+		fmt.Print(graph.DOT())
+	case "mermaid":
+		fmt.Print(graph.Mermaid())
+	case "json-graph":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(graph)
+	}
+	return nil
+}
+
+// outputTreeQuery prints the result of --path-to or --impact.
+func outputTreeQuery(treeBuilder *tree.TreeBuilder, components []*analyzer.Component, outcomes []analyzer.GVKDiscovery) error {
+	ref := treePathTo
+	if ref == "" {
+		ref = treeImpact
+	}
+	comp, err := findComponentRef(ref, components)
+	if err != nil {
+		printIncompleteDiscovery(stdout, outcomes)
+		return err
+	}
+
+	if treePathTo != "" {
+		fmt.Fprintf(stdout, "🔗 PATH TO %s/%s\n", comp.Type, comp.Name)
+		fmt.Fprintln(stdout, "============================")
+		fmt.Fprint(stdout, treeBuilder.PrintPath(treeBuilder.PathTo(comp)))
+	} else {
+		fmt.Fprintf(stdout, "💥 IMPACT OF %s/%s\n", comp.Type, comp.Name)
+		fmt.Fprintln(stdout, "============================")
+		fmt.Fprint(stdout, treeBuilder.PrintImpact(treeBuilder.Impact(comp)))
+	}
+	printIncompleteDiscovery(stdout, outcomes)
 	return nil
-}
\ No newline at end of file
+}
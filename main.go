@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 
 	"capi-advisor/cmd"
 
@@ -20,6 +23,11 @@ Available commands:
   analyze  - Comprehensive analysis with recommendations
   doctor   - Focus on health diagnostics and issue resolution
   tree     - Show component dependency relationships
+  get      - List components in a table
+  explain  - Deep dive into a single component
+  hosts    - Show BareMetalHost inventory and host fit
+  auth-check - Check the permissions the advisor needs
+  schema   - Print the JSON Schema of the JSON and YAML output
 
 Examples:
   # Analyze all components and get recommendations
@@ -31,19 +39,47 @@ Examples:
   # Show component dependency tree
   capi-advisor tree
 
+  # List Machines with the failing ones first
+  capi-advisor get machines --sort-by status
+
+  # Show why Metal3Machines find no BareMetalHost
+  capi-advisor hosts
+
+  # Check the permissions of the current identity
+  capi-advisor auth-check
+
   # Get detailed analysis as JSON
-  capi-advisor analyze -o json`,
+  capi-advisor analyze -o json
+
+  # Plain ASCII output for log pipelines and serial consoles
+  capi-advisor doctor --no-emoji --color=never`,
 }
 
 func init() {
+	cmd.AddRenderFlags(rootCmd)
 	rootCmd.AddCommand(cmd.AnalyzeCmd)
 	rootCmd.AddCommand(cmd.DoctorCmd)
 	rootCmd.AddCommand(cmd.TreeCmd)
+	rootCmd.AddCommand(cmd.HostsCmd)
+	rootCmd.AddCommand(cmd.AuthCheckCmd)
+	rootCmd.AddCommand(cmd.SchemaCmd)
+	rootCmd.AddCommand(cmd.GetCmd)
+	rootCmd.AddCommand(cmd.ExplainCmd)
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	// Cancel API requests in flight on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		// Exit codes of analyze and doctor tell pipelines what was found,
+		// the error was printed by cobra already
+		var exitErr *cmd.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(cmd.ExitFailure)
 	}
 }
\ No newline at end of file
@@ -6,11 +6,28 @@ import (
 	"strings"
 
 	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/inventory"
+	"capi-advisor/pkg/render"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// Links are all relationships between components, of which the tree shows
+// one parent per component. tree.TreeBuilder implements it.
+type Links interface {
+	Parents(comp *analyzer.Component) []*analyzer.Component
+	Children(comp *analyzer.Component) []*analyzer.Component
+}
+
 type Advisor struct {
 	knowledgeBase map[string]KnowledgeEntry
+	links         Links
+	discovery     []analyzer.GVKDiscovery
+	components    map[string]*analyzer.Component
+	hosts         []*inventory.Host
+	unbound       map[*analyzer.Component]bool
+	drifts        []inventory.Drift
+	// waitingClaims counts the unbound IPClaims by the key of their IPPool
+	waitingClaims map[string]int
 }
 
 type KnowledgeEntry struct {
@@ -24,35 +41,58 @@ type KnowledgeEntry struct {
 func NewAdvisor() *Advisor {
 	advisor := &Advisor{
 		knowledgeBase: make(map[string]KnowledgeEntry),
+		components:    make(map[string]*analyzer.Component),
+		unbound:       make(map[*analyzer.Component]bool),
+		waitingClaims: make(map[string]int),
 	}
 	advisor.loadKnowledgeBase()
 	return advisor
 }
 
+// SetLinks makes dependency lookups follow all relationships between
+// components instead of the tree only. Call it before AnalyzeComponents.
+func (a *Advisor) SetLinks(links Links) {
+	a.links = links
+}
+
+// SetDiscovery tells checks that look for missing objects which kinds were
+// listed completely, so that objects of kinds that could not be listed are
+// not reported as missing. Call it before AnalyzeComponents.
+func (a *Advisor) SetDiscovery(outcomes []analyzer.GVKDiscovery) {
+	a.discovery = outcomes
+}
+
+// Rule returns the knowledge base entry of a rule ID. Issues found by checks
+// rather than by the knowledge base have no entry.
+func (a *Advisor) Rule(id string) (analyzer.Rule, bool) {
+	entry, found := a.knowledgeBase[id]
+	return analyzer.Rule(entry), found
+}
+
 func (a *Advisor) loadKnowledgeBase() {
 	// Cluster API conditions
 	a.knowledgeBase["Cluster.Ready.False"] = KnowledgeEntry{
 		Condition:  "Cluster Ready is False",
 		Severity:   analyzer.SeverityCritical,
 		Cause:      "Infrastructure or control plane is not ready",
-		Resolution: "1. Check if InfrastructureReady condition is True\n   2. Verify ControlPlaneReady condition is True\n   3. Inspect Metal3Cluster and KubeadmControlPlane resources\n   4. Review cluster events: kubectl describe cluster <name>",
-		Dependencies: []string{"Metal3Cluster", "KubeadmControlPlane"},
+		Resolution: "1. Check if InfrastructureReady condition is True\n   2. Verify ControlPlaneReady condition is True\n   3. Inspect the infrastructure cluster and control plane resources\n   4. Review cluster events: kubectl describe cluster <name>",
+		Dependencies: []string{"infrastructureRef", "controlPlaneRef"},
 	}
 
 	a.knowledgeBase["Cluster.InfrastructureReady.False"] = KnowledgeEntry{
 		Condition:  "Cluster InfrastructureReady is False",
 		Severity:   analyzer.SeverityCritical,
 		Cause:      "Infrastructure provider is not ready",
-		Resolution: "1. Check Metal3Cluster resource: kubectl describe metal3cluster <name>\n   2. Verify network configuration in Metal3Cluster spec\n   3. Check infrastructure provider controller logs\n   4. Ensure required networks (provisioning, external) are configured",
-		Dependencies: []string{"Metal3Cluster"},
+		Resolution: "1. Check the infrastructure cluster referenced by spec.infrastructureRef: kubectl describe <kind> <name>\n   2. Verify network configuration in its spec\n   3. Check infrastructure provider controller logs\n   4. For Metal3Cluster, ensure required networks (provisioning, external) are configured",
+		Dependencies: []string{"infrastructureRef"},
 	}
 
 	a.knowledgeBase["Cluster.ControlPlaneReady.False"] = KnowledgeEntry{
 		Condition:  "Cluster ControlPlaneReady is False",
 		Severity:   analyzer.SeverityCritical,
 		Cause:      "Control plane nodes are not ready",
-		Resolution: "1. Check KubeadmControlPlane status: kubectl describe kcp <name>\n   2. Verify control plane replicas are scheduled\n   3. Check control plane Machine resources status\n   4. Review etcd pod logs if cluster is partially up\n   5. Check for sufficient control plane nodes matching desired replicas",
-		Dependencies: []string{"KubeadmControlPlane", "Machine"},
+		Resolution: "1. Check the control plane referenced by spec.controlPlaneRef, e.g. kubectl describe kcp <name>\n   2. Verify control plane replicas are scheduled\n   3. Check control plane Machine resources status\n   4. Review etcd pod logs if cluster is partially up\n   5. Check for sufficient control plane nodes matching desired replicas",
+		Dependencies: []string{"controlPlaneRef", "Machine"},
 	}
 
 	// Machine conditions
@@ -60,24 +100,24 @@ func (a *Advisor) loadKnowledgeBase() {
 		Condition:  "Machine Ready is False",
 		Severity:   analyzer.SeverityCritical,
 		Cause:      "Machine infrastructure or bootstrap is not ready",
-		Resolution: "1. Check Machine status: kubectl describe machine <name>\n   2. Verify InfrastructureReady condition status\n   3. Check BootstrapReady condition status\n   4. Review Metal3Machine and KubeadmConfig resources\n   5. Check node status if partially provisioned",
-		Dependencies: []string{"Metal3Machine", "KubeadmConfig"},
+		Resolution: "1. Check Machine status: kubectl describe machine <name>\n   2. Verify InfrastructureReady condition status\n   3. Check BootstrapReady condition status\n   4. Review the infrastructure machine and bootstrap config resources\n   5. Check node status if partially provisioned",
+		Dependencies: []string{"infrastructureRef", "bootstrap.configRef"},
 	}
 
 	a.knowledgeBase["Machine.InfrastructureReady.False"] = KnowledgeEntry{
 		Condition:  "Machine InfrastructureReady is False",
 		Severity:   analyzer.SeverityCritical,
-		Cause:      "Metal3Machine is not ready",
-		Resolution: "1. Check Metal3Machine: kubectl describe metal3machine <name>\n   2. Verify BareMetalHost association and status\n   3. Check if BareMetalHost is in 'provisioned' state\n   4. Review BMC credentials and connectivity\n   5. Check baremetal-operator logs for provisioning errors",
-		Dependencies: []string{"Metal3Machine", "BareMetalHost"},
+		Cause:      "Infrastructure machine is not ready",
+		Resolution: "1. Check the infrastructure machine referenced by spec.infrastructureRef: kubectl describe <kind> <name>\n   2. For Metal3Machine, verify BareMetalHost association and that it is in 'provisioned' state\n   3. For Metal3Machine, review BMC credentials and connectivity\n   4. Check infrastructure provider controller logs for provisioning errors",
+		Dependencies: []string{"infrastructureRef", "BareMetalHost"},
 	}
 
 	a.knowledgeBase["Machine.BootstrapReady.False"] = KnowledgeEntry{
 		Condition:  "Machine BootstrapReady is False",
 		Severity:   analyzer.SeverityCritical,
 		Cause:      "Bootstrap configuration is not ready",
-		Resolution: "1. Check KubeadmConfig: kubectl describe kubeadmconfig <name>\n   2. For control plane: verify API server is accessible\n   3. For workers: ensure control plane is ready\n   4. Check cluster connectivity and certificates\n   5. Review bootstrap provider controller logs",
-		Dependencies: []string{"KubeadmConfig"},
+		Resolution: "1. Check the bootstrap config referenced by spec.bootstrap.configRef, e.g. kubectl describe kubeadmconfig <name>\n   2. For control plane: verify API server is accessible\n   3. For workers: ensure control plane is ready\n   4. Check cluster connectivity and certificates\n   5. Review bootstrap provider controller logs",
+		Dependencies: []string{"bootstrap.configRef"},
 	}
 
 	// Metal3Machine conditions
@@ -163,6 +203,169 @@ func (a *Advisor) loadKnowledgeBase() {
 		Resolution: "1. Check if bootstrap data secret exists\n   2. Verify KubeadmConfig reconciliation status\n   3. Ensure control plane is accessible for worker nodes\n   4. Review bootstrap provider controller logs\n   5. Check for any errors in KubeadmConfig status",
 		Dependencies: []string{},
 	}
+
+	// MachinePool conditions
+	a.knowledgeBase["MachinePool.Ready.False"] = KnowledgeEntry{
+		Condition:  "MachinePool Ready is False",
+		Severity:   analyzer.SeverityCritical,
+		Cause:      "MachinePool infrastructure or bootstrap is not ready",
+		Resolution: "1. Check MachinePool status: kubectl describe machinepool <name>\n   2. Verify BootstrapReady and InfrastructureReady conditions\n   3. Inspect the infrastructure machine pool referenced in spec.template.spec.infrastructureRef\n   4. Review Cluster API controller logs (MachinePool feature gate must be enabled)",
+		Dependencies: []string{"KubeadmConfig", "DockerMachinePool", "AWSMachinePool", "AzureMachinePool"},
+	}
+
+	a.knowledgeBase["MachinePool.BootstrapReady.False"] = KnowledgeEntry{
+		Condition:  "MachinePool BootstrapReady is False",
+		Severity:   analyzer.SeverityCritical,
+		Cause:      "Bootstrap data for the MachinePool has not been generated",
+		Resolution: "1. Check the bootstrap config in spec.template.spec.bootstrap.configRef\n   2. Verify the bootstrap data secret was created\n   3. Ensure the control plane is initialized and reachable\n   4. Review bootstrap provider controller logs",
+		Dependencies: []string{"KubeadmConfig"},
+	}
+
+	a.knowledgeBase["MachinePool.InfrastructureReady.False"] = KnowledgeEntry{
+		Condition:  "MachinePool InfrastructureReady is False",
+		Severity:   analyzer.SeverityCritical,
+		Cause:      "Infrastructure machine pool is not ready",
+		Resolution: "1. Check the infrastructure machine pool: kubectl describe <kind> <name>\n   2. Verify the provider can create the scale set / auto scaling group\n   3. Check provider credentials and quotas\n   4. Review infrastructure provider controller logs",
+		Dependencies: []string{"DockerMachinePool", "AWSMachinePool", "AzureMachinePool"},
+	}
+
+	a.knowledgeBase["MachinePool.ReplicasReady.False"] = KnowledgeEntry{
+		Condition:  "MachinePool ReplicasReady is False",
+		Severity:   analyzer.SeverityWarning,
+		Cause:      "Not all MachinePool replicas are ready",
+		Resolution: "1. Compare spec.replicas with status.readyReplicas: kubectl get machinepool <name>\n   2. Check status.nodeRefs for nodes that did not join\n   3. Inspect MachinePool machines: kubectl get machines -l cluster.x-k8s.io/pool-name=<name>\n   4. Check the infrastructure machine pool for instance failures\n   5. Review node bootstrap logs on instances that did not join",
+		Dependencies: []string{"Machine", "DockerMachinePool", "AWSMachinePool", "AzureMachinePool"},
+	}
+
+	// Infrastructure machine pool conditions
+	a.knowledgeBase["DockerMachinePool.ReplicasReady.False"] = KnowledgeEntry{
+		Condition:  "DockerMachinePool ReplicasReady is False",
+		Severity:   analyzer.SeverityWarning,
+		Cause:      "Not all Docker containers of the pool are running",
+		Resolution: "1. Check DockerMachinePool: kubectl describe dockermachinepool <name>\n   2. List pool containers on the Docker host: docker ps -a\n   3. Review CAPD controller logs",
+		Dependencies: []string{},
+	}
+
+	a.knowledgeBase["AWSMachinePool.ASGReady.False"] = KnowledgeEntry{
+		Condition:  "AWSMachinePool ASGReady is False",
+		Severity:   analyzer.SeverityCritical,
+		Cause:      "Auto Scaling Group could not be created or updated",
+		Resolution: "1. Check AWSMachinePool: kubectl describe awsmachinepool <name>\n   2. Verify the Auto Scaling Group in the AWS console or CLI\n   3. Check subnets, instance types and service quotas\n   4. Review CAPA controller logs",
+		Dependencies: []string{},
+	}
+
+	a.knowledgeBase["AWSMachinePool.LaunchTemplateReady.False"] = KnowledgeEntry{
+		Condition:  "AWSMachinePool LaunchTemplateReady is False",
+		Severity:   analyzer.SeverityCritical,
+		Cause:      "Launch template could not be created or updated",
+		Resolution: "1. Check AWSMachinePool: kubectl describe awsmachinepool <name>\n   2. Verify AMI, instance type and IAM instance profile exist\n   3. Check bootstrap data size limits for user data\n   4. Review CAPA controller logs",
+		Dependencies: []string{},
+	}
+
+	a.knowledgeBase["AzureMachinePool.ScaleSetRunning.False"] = KnowledgeEntry{
+		Condition:  "AzureMachinePool ScaleSetRunning is False",
+		Severity:   analyzer.SeverityCritical,
+		Cause:      "Virtual machine scale set is not running",
+		Resolution: "1. Check AzureMachinePool: kubectl describe azuremachinepool <name>\n   2. Verify the scale set in the Azure portal or CLI\n   3. Check VM SKU availability and subscription quotas\n   4. Review CAPZ controller logs",
+		Dependencies: []string{},
+	}
+
+	// ClusterClass and managed topology conditions
+	a.knowledgeBase["Cluster.TopologyReconciled.False"] = KnowledgeEntry{
+		Condition:  "Cluster TopologyReconciled is False",
+		Severity:   analyzer.SeverityCritical,
+		Cause:      "The managed topology could not be reconciled from the ClusterClass",
+		Resolution: "1. Check the condition message: kubectl get cluster <name> -o jsonpath='{.status.conditions[?(@.type==\"TopologyReconciled\")]}'\n   2. Verify the ClusterClass and all referenced templates exist\n   3. Validate spec.topology.variables against the ClusterClass variable schemas\n   4. Review the topology controller logs in capi-controller-manager",
+		Dependencies: []string{"ClusterClass"},
+	}
+
+	a.knowledgeBase["Cluster.TopologyReconciled.False.TopologyReconcileFailed"] = KnowledgeEntry{
+		Condition:  "Cluster topology reconciliation failed",
+		Severity:   analyzer.SeverityCritical,
+		Cause:      "Computing or applying the desired state from the ClusterClass failed, usually because a patch or variable is invalid",
+		Resolution: "1. Read the full error in the TopologyReconciled condition message\n   2. Check the patches and variables listed in the cause above\n   3. Verify JSON patch paths exist in the referenced templates\n   4. For external patches, check the runtime extension is reachable: kubectl get extensionconfig\n   5. Fix spec.topology.variables or the ClusterClass patches and wait for the next reconcile",
+		Dependencies: []string{"ClusterClass"},
+	}
+
+	a.knowledgeBase["Cluster.TopologyReconciled.False.ClusterClassNotReconciled"] = KnowledgeEntry{
+		Condition:  "ClusterClass of the Cluster is not reconciled",
+		Severity:   analyzer.SeverityWarning,
+		Cause:      "The topology controller waits for the ClusterClass to be reconciled before updating the Cluster",
+		Resolution: "1. Check the ClusterClass: kubectl describe clusterclass <name>\n   2. Verify VariablesReconciled and RefVersionsUpToDate conditions on the ClusterClass\n   3. Ensure all templates referenced by the ClusterClass exist",
+		Dependencies: []string{"ClusterClass"},
+	}
+
+	a.knowledgeBase["Cluster.TopologyReconciled.False.TopologyReconciledControlPlaneUpgradePending"] = KnowledgeEntry{
+		Condition:  "Control plane upgrade is pending",
+		Severity:   analyzer.SeverityInfo,
+		Cause:      "The control plane is not yet upgraded to spec.topology.version",
+		Resolution: "1. Wait for the control plane rollout to start; it is held while the control plane is scaling or already upgrading\n   2. Check KubeadmControlPlane status: kubectl describe kcp <name>\n   3. Check for blocking lifecycle hooks (BeforeClusterUpgrade)",
+		Dependencies: []string{"KubeadmControlPlane"},
+	}
+
+	a.knowledgeBase["Cluster.TopologyReconciled.False.TopologyReconciledMachineDeploymentsUpgradePending"] = KnowledgeEntry{
+		Condition:  "MachineDeployment upgrade is pending",
+		Severity:   analyzer.SeverityInfo,
+		Cause:      "MachineDeployments wait for the control plane to finish upgrading before they are upgraded",
+		Resolution: "1. Wait for the control plane upgrade to complete\n   2. Check for the topology.cluster.x-k8s.io/hold-upgrade-sequence annotation on MachineDeployment topologies\n   3. Review the TopologyReconciled condition message for the MachineDeployments involved",
+		Dependencies: []string{"KubeadmControlPlane", "MachineDeployment"},
+	}
+
+	a.knowledgeBase["Cluster.TopologyReconciled.False.TopologyReconciledMachineDeploymentsUpgradeDeferred"] = KnowledgeEntry{
+		Condition:  "MachineDeployment upgrade is deferred",
+		Severity:   analyzer.SeverityInfo,
+		Cause:      "MachineDeployment upgrades are deferred by annotation",
+		Resolution: "1. Find MachineDeployment topologies with topology.cluster.x-k8s.io/defer-upgrade or hold-upgrade-sequence annotations\n   2. Remove the annotation from spec.topology.workers.machineDeployments[].metadata.annotations to continue the upgrade",
+		Dependencies: []string{"MachineDeployment"},
+	}
+
+	a.knowledgeBase["Cluster.TopologyReconciled.False.TopologyReconciledHookBlocking"] = KnowledgeEntry{
+		Condition:  "Lifecycle hook is blocking the topology",
+		Severity:   analyzer.SeverityWarning,
+		Cause:      "A runtime extension lifecycle hook asked the topology controller to wait",
+		Resolution: "1. Identify the hook from the TopologyReconciled condition message\n   2. Check the runtime extension: kubectl get extensionconfig -o wide\n   3. Review the runtime extension logs for the retry reason",
+		Dependencies: []string{},
+	}
+
+	a.knowledgeBase["ClusterClass.VariablesReconciled.False"] = KnowledgeEntry{
+		Condition:  "ClusterClass VariablesReconciled is False",
+		Severity:   analyzer.SeverityCritical,
+		Cause:      "Variable definitions of the ClusterClass could not be reconciled",
+		Resolution: "1. Check the ClusterClass: kubectl describe clusterclass <name>\n   2. Validate the OpenAPI schemas in spec.variables\n   3. For external variable discovery, check the runtime extension is reachable\n   4. Review capi-controller-manager logs",
+		Dependencies: []string{},
+	}
+
+	a.knowledgeBase["ClusterClass.RefVersionsUpToDate.False"] = KnowledgeEntry{
+		Condition:  "ClusterClass RefVersionsUpToDate is False",
+		Severity:   analyzer.SeverityWarning,
+		Cause:      "Template references of the ClusterClass use outdated API versions",
+		Resolution: "1. Check the condition message for the outdated references\n   2. Update spec template refs to the current API version of each provider\n   3. Verify the providers were upgraded correctly: clusterctl describe provider",
+		Dependencies: []string{},
+	}
+
+	// Host firmware conditions
+	a.knowledgeBase["HostFirmwareSettings.Valid.False"] = KnowledgeEntry{
+		Condition:  "HostFirmwareSettings Valid is False",
+		Severity:   analyzer.SeverityCritical,
+		Cause:      "Requested BIOS settings are rejected by the FirmwareSchema of the host",
+		Resolution: "1. Check the condition message: kubectl describe hostfirmwaresettings <name>\n   2. Compare spec.settings with the FirmwareSchema: kubectl get firmwareschema -o yaml\n   3. Remove unknown or read-only settings and use allowed values\n   4. Review baremetal-operator logs",
+		Dependencies: []string{"FirmwareSchema"},
+	}
+
+	a.knowledgeBase["HostFirmwareComponents.Valid.False"] = KnowledgeEntry{
+		Condition:  "HostFirmwareComponents Valid is False",
+		Severity:   analyzer.SeverityCritical,
+		Cause:      "Requested firmware updates are invalid",
+		Resolution: "1. Check the condition message: kubectl describe hostfirmwarecomponents <name>\n   2. Only bios, bmc and nic components can be updated\n   3. Verify every update has a reachable URL\n   4. Review baremetal-operator logs",
+		Dependencies: []string{},
+	}
+
+	// Rules contributed by infrastructure, bootstrap and control plane providers
+	for _, provider := range analyzer.Providers() {
+		for key, rule := range provider.Rules() {
+			a.knowledgeBase[key] = KnowledgeEntry(rule)
+		}
+	}
 }
 
 func (a *Advisor) AnalyzeComponents(components []*analyzer.Component) *analyzer.AnalysisResult {
@@ -170,6 +373,22 @@ func (a *Advisor) AnalyzeComponents(components []*analyzer.Component) *analyzer.
 	statusCounts := make(map[analyzer.ComponentStatus]int)
 	severityCounts := make(map[analyzer.ConditionSeverity]int)
 
+	// Index components for cross-component checks
+	for _, comp := range components {
+		a.components[a.getComponentKey(comp.Namespace, comp.Name, comp.Type)] = comp
+	}
+	a.hosts = inventory.BuildInventory(components)
+	for _, machine := range inventory.UnboundMachines(components) {
+		a.unbound[machine] = true
+	}
+	a.drifts = inventory.FirmwareDrift(a.hosts, components)
+	for _, comp := range components {
+		if comp.Type == analyzer.IPClaimType && !ipClaimBound(comp) {
+			poolNamespace, poolName := ipClaimPool(comp)
+			a.waitingClaims[a.getComponentKey(poolNamespace, poolName, analyzer.IPPoolType)]++
+		}
+	}
+
 	for _, comp := range components {
 		statusCounts[comp.Status]++
 		componentIssues := a.analyzeComponent(comp)
@@ -180,18 +399,10 @@ func (a *Advisor) AnalyzeComponents(components []*analyzer.Component) *analyzer.
 		}
 	}
 
-	// Sort issues by severity
-	sort.Slice(issues, func(i, j int) bool {
-		severityOrder := map[analyzer.ConditionSeverity]int{
-			analyzer.SeverityCritical: 0,
-			analyzer.SeverityWarning:  1,
-			analyzer.SeverityInfo:     2,
-		}
-		return severityOrder[issues[i].Severity] < severityOrder[issues[j].Severity]
-	})
+	sortIssues(issues)
 
 	// Determine overall cluster health
-	clusterHealth := a.determineClusterHealth(statusCounts, severityCounts)
+	clusterHealth := ClusterHealth(statusCounts, severityCounts)
 
 	return &analyzer.AnalysisResult{
 		Components: components,
@@ -205,14 +416,34 @@ func (a *Advisor) AnalyzeComponents(components []*analyzer.Component) *analyzer.
 	}
 }
 
+// sortIssues sorts issues by severity.
+func sortIssues(issues []*analyzer.Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		severityOrder := map[analyzer.ConditionSeverity]int{
+			analyzer.SeverityCritical: 0,
+			analyzer.SeverityWarning:  1,
+			analyzer.SeverityInfo:     2,
+		}
+		return severityOrder[issues[i].Severity] < severityOrder[issues[j].Severity]
+	})
+}
+
 func (a *Advisor) analyzeComponent(comp *analyzer.Component) []*analyzer.Issue {
 	var issues []*analyzer.Issue
 
 	for _, condition := range comp.Conditions {
 		if condition.Status == metav1.ConditionFalse {
 			key := fmt.Sprintf("%s.%s.%s", comp.Type, condition.Type, condition.Status)
-			if knowledge, exists := a.knowledgeBase[key]; exists {
+			// Prefer knowledge specific to the condition reason
+			ruleID := key + "." + condition.Reason
+			knowledge, exists := a.knowledgeBase[ruleID]
+			if !exists {
+				ruleID = key
+				knowledge, exists = a.knowledgeBase[key]
+			}
+			if exists {
 				issue := &analyzer.Issue{
+					RuleID:      ruleID,
 					Component:   comp,
 					Condition:   condition,
 					Severity:    knowledge.Severity,
@@ -227,6 +458,7 @@ func (a *Advisor) analyzeComponent(comp *analyzer.Component) []*analyzer.Issue {
 			} else {
 				// Enhanced generic issue for unknown conditions
 				issue := &analyzer.Issue{
+					RuleID:      key,
 					Component:   comp,
 					Condition:   condition,
 					Severity:    analyzer.SeverityWarning,
@@ -239,9 +471,53 @@ func (a *Advisor) analyzeComponent(comp *analyzer.Component) []*analyzer.Issue {
 		}
 	}
 
+	switch comp.Type {
+	case analyzer.MachinePoolType:
+		issues = append(issues, a.analyzeMachinePool(comp)...)
+	case analyzer.ClusterType:
+		issues = a.analyzeTopology(comp, issues)
+	case analyzer.ClusterClassType:
+		issues = append(issues, a.analyzeClusterClass(comp)...)
+	case analyzer.IPPoolType:
+		issues = append(issues, a.analyzeIPPool(comp)...)
+	case analyzer.IPClaimType:
+		issues = append(issues, a.analyzeIPClaim(comp)...)
+	case analyzer.Metal3DataType, analyzer.Metal3DataClaimType:
+		issues = append(issues, a.analyzeMetal3Data(comp)...)
+	case analyzer.Metal3MachineType:
+		issues = append(issues, a.analyzeMetal3Machine(comp)...)
+	case analyzer.BareMetalHostType:
+		issues = append(issues, a.analyzeBMC(comp)...)
+		issues = append(issues, a.analyzeHostConfigurationDrift(comp)...)
+	case analyzer.HostFirmwareSettingsType:
+		issues = append(issues, a.analyzeHostFirmwareSettings(comp)...)
+	case analyzer.HostFirmwareComponentsType:
+		issues = append(issues, a.analyzeHostFirmwareComponents(comp)...)
+	default:
+		if analyzer.ReferencedBy(comp) != "" {
+			issues = append(issues, a.analyzeReferencedObject(comp)...)
+		}
+	}
+
+	for _, issue := range issues {
+		setRuleID(issue)
+	}
 	return issues
 }
 
+// setRuleID names the rule of an issue found by a check rather than the
+// knowledge base after its synthetic condition, following the keys of the
+// knowledge base, e.g. "BareMetalHost.BMCConfigured.False.MissingBMCAddress".
+func setRuleID(issue *analyzer.Issue) {
+	if issue.RuleID != "" {
+		return
+	}
+	issue.RuleID = fmt.Sprintf("%s.%s.%s", issue.Component.Type, issue.Condition.Type, issue.Condition.Status)
+	if issue.Condition.Reason != "" {
+		issue.RuleID += "." + issue.Condition.Reason
+	}
+}
+
 func (a *Advisor) enhanceCause(baseCause string, condition metav1.Condition) string {
 	if condition.Reason != "" && condition.Message != "" {
 		return fmt.Sprintf("%s\nReason: %s\nDetails: %s", baseCause, condition.Reason, condition.Message)
@@ -259,7 +535,7 @@ func (a *Advisor) enhanceResolution(baseResolution string, condition metav1.Cond
 	// Add specific guidance based on reason
 	specificGuidance := a.getSpecificGuidanceFromReason(condition.Reason, condition.Message, comp)
 	if specificGuidance != "" {
-		resolution = fmt.Sprintf("%s\n\n💡 Specific guidance based on current state:\n%s", resolution, specificGuidance)
+		resolution = fmt.Sprintf("%s\n\n%s current state:\n%s", resolution, analyzer.GuidanceHeading, specificGuidance)
 	}
 
 	return resolution
@@ -272,6 +548,11 @@ func (a *Advisor) getSpecificGuidanceFromReason(reason string, message string, c
 	// BMC/IPMI related issues
 	if strings.Contains(reasonLower, "bmc") || strings.Contains(messageLower, "ipmi") ||
 	   strings.Contains(messageLower, "bmc") || strings.Contains(reasonLower, "connection") {
+		if comp.Type == analyzer.BareMetalHostType {
+			if guidance := a.bmcGuidance(comp); guidance != "" {
+				return guidance
+			}
+		}
 		return "   - BMC connection issue detected. Verify:\n     * BMC IP address is reachable from baremetal-operator pod\n     * BMC credentials are correct in the secret\n     * Firewall rules allow IPMI traffic (port 623)\n     * BMC firmware is up to date"
 	}
 
@@ -332,7 +613,7 @@ func (a *Advisor) buildGenericResolution(comp *analyzer.Component, condition met
 	// Add specific guidance based on reason/message
 	specificGuidance := a.getSpecificGuidanceFromReason(condition.Reason, condition.Message, comp)
 	if specificGuidance != "" {
-		resolution = fmt.Sprintf("%s\n\n💡 Specific guidance based on error:\n%s", resolution, specificGuidance)
+		resolution = fmt.Sprintf("%s\n\n%s error:\n%s", resolution, analyzer.GuidanceHeading, specificGuidance)
 	}
 
 	return resolution
@@ -342,19 +623,27 @@ func (a *Advisor) findDependencies(comp *analyzer.Component, depTypes []string)
 	var deps []*analyzer.Component
 
 	for _, depType := range depTypes {
-		// Look in children first
-		for _, child := range comp.Children {
-			if string(child.Type) == depType {
-				deps = append(deps, child)
+		// Dependencies ending in "Ref" name a reference field of the
+		// component, resolving to whichever provider kind it points to
+		if strings.HasSuffix(depType, "Ref") {
+			if ref, found := analyzer.NestedRef(comp, strings.Split(depType, ".")...); found {
+				if dep := a.findComponent(ref.Name, ref.Namespace, analyzer.ComponentType(ref.Kind)); dep != nil {
+					deps = append(deps, dep)
+				}
 			}
+			continue
 		}
 
-		// Look in parent's children
-		if comp.Parent != nil {
-			for _, sibling := range comp.Parent.Children {
-				if string(sibling.Type) == depType && sibling != comp {
-					deps = append(deps, sibling)
-				}
+		// Look in children first, then in the children of all parents
+		seen := map[*analyzer.Component]bool{comp: true}
+		candidates := a.children(comp)
+		for _, parent := range a.parents(comp) {
+			candidates = append(candidates, a.children(parent)...)
+		}
+		for _, candidate := range candidates {
+			if string(candidate.Type) == depType && !seen[candidate] {
+				seen[candidate] = true
+				deps = append(deps, candidate)
 			}
 		}
 	}
@@ -362,7 +651,38 @@ func (a *Advisor) findDependencies(comp *analyzer.Component, depTypes []string)
 	return deps
 }
 
-func (a *Advisor) determineClusterHealth(statusCounts map[analyzer.ComponentStatus]int, severityCounts map[analyzer.ConditionSeverity]int) analyzer.ComponentStatus {
+// children returns the components a component links to, through every
+// link when the advisor was given them, otherwise through the tree.
+func (a *Advisor) children(comp *analyzer.Component) []*analyzer.Component {
+	if a.links != nil {
+		return a.links.Children(comp)
+	}
+	return comp.Children
+}
+
+// parents returns the components linking to a component, through every
+// link when the advisor was given them, otherwise through the tree.
+func (a *Advisor) parents(comp *analyzer.Component) []*analyzer.Component {
+	if a.links != nil {
+		return a.links.Parents(comp)
+	}
+	if comp.Parent != nil {
+		return []*analyzer.Component{comp.Parent}
+	}
+	return nil
+}
+
+func (a *Advisor) findComponent(name, namespace string, compType analyzer.ComponentType) *analyzer.Component {
+	return a.components[a.getComponentKey(namespace, name, compType)]
+}
+
+func (a *Advisor) getComponentKey(namespace, name string, compType analyzer.ComponentType) string {
+	return namespace + "/" + name + "/" + string(compType)
+}
+
+// ClusterHealth rates a set of components by the statuses of the components
+// and the severities of their issues.
+func ClusterHealth(statusCounts map[analyzer.ComponentStatus]int, severityCounts map[analyzer.ConditionSeverity]int) analyzer.ComponentStatus {
 	if severityCounts[analyzer.SeverityCritical] > 0 || statusCounts[analyzer.StatusFailed] > 0 {
 		return analyzer.StatusFailed
 	}
@@ -379,42 +699,50 @@ func (a *Advisor) GenerateReport(result *analyzer.AnalysisResult) string {
 	var report strings.Builder
 
 	// Summary
-	report.WriteString("🏥 CLUSTER HEALTH REPORT\n")
+	report.WriteString(render.Heading("🏥 CLUSTER HEALTH REPORT") + "\n")
 	report.WriteString(strings.Repeat("=", 50) + "\n\n")
 
-	healthIcon := a.getHealthIcon(result.Summary.ClusterHealth)
-	report.WriteString(fmt.Sprintf("Overall Health: %s %s\n\n", healthIcon, result.Summary.ClusterHealth))
+	healthIcon := render.StatusIcon(result.Summary.ClusterHealth)
+	report.WriteString(fmt.Sprintf("Overall Health: %s %s\n\n", healthIcon,
+		render.Status(result.Summary.ClusterHealth, string(result.Summary.ClusterHealth))))
 
 	// Component summary
-	report.WriteString("📊 COMPONENT SUMMARY\n")
+	report.WriteString(render.Heading("📊 COMPONENT SUMMARY") + "\n")
 	report.WriteString(fmt.Sprintf("Total Components: %d\n", result.Summary.TotalComponents))
 	report.WriteString("Status Distribution:\n")
 	for status, count := range result.Summary.StatusCounts {
 		if count > 0 {
-			icon := a.getStatusIcon(status)
-			report.WriteString(fmt.Sprintf("  %s %s: %d\n", icon, status, count))
+			icon := render.StatusIcon(status)
+			report.WriteString(fmt.Sprintf("  %s %s: %d\n", icon, render.Status(status, string(status)), count))
 		}
 	}
 	report.WriteString("\n")
 
+	// Discovery outcome
+	if discovery := DiscoveryReport(result.Discovery); discovery != "" {
+		report.WriteString(discovery)
+		report.WriteString("\n")
+	}
+
 	// Issues
 	if len(result.Issues) == 0 {
 		report.WriteString("✅ No issues found! All components are healthy.\n")
 	} else {
-		report.WriteString("🚨 ISSUES FOUND\n")
+		report.WriteString(render.Heading("🚨 ISSUES FOUND") + "\n")
 		report.WriteString(strings.Repeat("-", 30) + "\n")
 
 		for i, issue := range result.Issues {
-			report.WriteString(fmt.Sprintf("\n%d. %s %s\n", i+1, a.getSeverityIcon(issue.Severity), issue.Description))
+			report.WriteString(fmt.Sprintf("\n%d. %s %s\n", i+1, render.SeverityIcon(issue.Severity),
+				render.Severity(issue.Severity, issue.Description)))
 			report.WriteString(fmt.Sprintf("   Component: %s/%s\n", issue.Component.Type, issue.Component.Name))
-			report.WriteString(fmt.Sprintf("   Cause: %s\n", issue.Cause))
-			report.WriteString(fmt.Sprintf("   💡 Resolution: %s\n", issue.Resolution))
+			report.WriteString(render.Wrap("   Cause: ", issue.Cause, "      ") + "\n")
+			report.WriteString(render.Wrap("   💡 Resolution: ", render.Resolution(issue.Resolution), "      ") + "\n")
 
 			if len(issue.Dependencies) > 0 {
 				report.WriteString("   🔗 Check these dependencies:\n")
 				for _, dep := range issue.Dependencies {
-					depStatus := a.getStatusIcon(dep.Status)
-					report.WriteString(fmt.Sprintf("      %s %s/%s\n", depStatus, dep.Type, dep.Name))
+					depStatus := render.StatusIcon(dep.Status)
+					report.WriteString(fmt.Sprintf("      %s %s/%s\n", depStatus, dep.Type, render.Status(dep.Status, dep.Name)))
 				}
 			}
 		}
@@ -422,35 +750,3 @@ func (a *Advisor) GenerateReport(result *analyzer.AnalysisResult) string {
 
 	return report.String()
 }
-
-func (a *Advisor) getHealthIcon(status analyzer.ComponentStatus) string {
-	return a.getStatusIcon(status)
-}
-
-func (a *Advisor) getStatusIcon(status analyzer.ComponentStatus) string {
-	switch status {
-	case analyzer.StatusHealthy:
-		return "✅"
-	case analyzer.StatusDegraded:
-		return "⚠️"
-	case analyzer.StatusFailed:
-		return "❌"
-	case analyzer.StatusPending:
-		return "⏳"
-	default:
-		return "❓"
-	}
-}
-
-func (a *Advisor) getSeverityIcon(severity analyzer.ConditionSeverity) string {
-	switch severity {
-	case analyzer.SeverityCritical:
-		return "🔴"
-	case analyzer.SeverityWarning:
-		return "🟡"
-	case analyzer.SeverityInfo:
-		return "🔵"
-	default:
-		return "⚪"
-	}
-}
\ No newline at end of file
@@ -0,0 +1,272 @@
+package advisor
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/inventory"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// bmcProblem is a misconfiguration of the BMC settings of a BareMetalHost.
+type bmcProblem struct {
+	severity    analyzer.ConditionSeverity
+	reason      string
+	description string
+	detail      string
+	resolution  string
+}
+
+// analyzeBMC reports every BMC misconfiguration of a BareMetalHost as a
+// separate issue.
+func (a *Advisor) analyzeBMC(bmh *analyzer.Component) []*analyzer.Issue {
+	var issues []*analyzer.Issue
+	for _, problem := range a.bmcProblems(bmh) {
+		condition := metav1.Condition{
+			Type:    "BMCConfigured",
+			Status:  metav1.ConditionFalse,
+			Reason:  problem.reason,
+			Message: problem.detail,
+		}
+		issues = append(issues, &analyzer.Issue{
+			Component:   bmh,
+			Condition:   condition,
+			Severity:    problem.severity,
+			Description: problem.description,
+			Cause:       a.enhanceCause("The BMC settings of the BareMetalHost prevent the baremetal-operator from managing it", condition),
+			Resolution:  problem.resolution,
+		})
+	}
+	return issues
+}
+
+// bmcProblems validates the BMC address, driver, credentials Secret and
+// driver-specific requirements of a BareMetalHost.
+func (a *Advisor) bmcProblems(bmh *analyzer.Component) []bmcProblem {
+	host := a.inventoryHost(bmh)
+	if host == nil {
+		return nil
+	}
+
+	var problems []bmcProblem
+	edit := fmt.Sprintf("kubectl edit bmh %s -n %s", bmh.Name, bmh.Namespace)
+
+	if host.BMCAddress == "" {
+		// Externally provisioned hosts may be registered without a BMC
+		if host.ProvisioningState == "externally provisioned" || host.ProvisioningState == "unmanaged" {
+			return nil
+		}
+		return []bmcProblem{{
+			severity:    analyzer.SeverityCritical,
+			reason:      "MissingBMCAddress",
+			description: "BareMetalHost has no BMC address",
+			detail:      "spec.bmc.address is empty",
+			resolution:  fmt.Sprintf("1. Set spec.bmc.address, e.g. redfish-virtualmedia://10.0.0.10/redfish/v1/Systems/1: %s\n   2. Set spec.bmc.credentialsName to a Secret with username and password keys", edit),
+		}}
+	}
+
+	driver, known := inventory.LookupBMCDriver(host.BMCType)
+	if !known {
+		problems = append(problems, bmcProblem{
+			severity:    analyzer.SeverityCritical,
+			reason:      "UnknownBMCDriver",
+			description: fmt.Sprintf("BMC address uses unsupported driver %q", host.BMCType),
+			detail:      fmt.Sprintf("%s is not one of %s", host.BMCAddress, strings.Join(inventory.BMCDriverSchemes(), ", ")),
+			resolution:  fmt.Sprintf("1. Use a supported scheme in spec.bmc.address: %s\n   2. Common choices are ipmi, redfish, redfish-virtualmedia, idrac-virtualmedia and ilo5", edit),
+		})
+	}
+
+	bmcHost, _, path, err := inventory.BMCEndpoint(host.BMCAddress)
+	if err != nil || bmcHost == "" {
+		detail := fmt.Sprintf("%s has no host", host.BMCAddress)
+		if err != nil {
+			detail = err.Error()
+		}
+		problems = append(problems, bmcProblem{
+			severity:    analyzer.SeverityCritical,
+			reason:      "InvalidBMCAddress",
+			description: "BMC address cannot be parsed",
+			detail:      detail,
+			resolution:  fmt.Sprintf("1. Use the form <driver>://<host>[:<port>][/<path>] in spec.bmc.address: %s", edit),
+		})
+	} else if known && driver.Protocol == "redfish" && strings.Trim(path, "/") == "" {
+		problems = append(problems, bmcProblem{
+			severity:    analyzer.SeverityInfo,
+			reason:      "MissingRedfishSystemPath",
+			description: "Redfish BMC address has no system path",
+			detail:      fmt.Sprintf("%s does not name a system such as /redfish/v1/Systems/1", host.BMCAddress),
+			resolution:  fmt.Sprintf("1. Find the system ID: curl -ku <user> https://%s/redfish/v1/Systems\n   2. Append the system path to spec.bmc.address: %s", bmcHost, edit),
+		})
+	}
+
+	problems = append(problems, a.bmcCredentialProblems(bmh, host, edit)...)
+
+	if host.BootMACAddress != "" {
+		if _, err := net.ParseMAC(host.BootMACAddress); err != nil {
+			problems = append(problems, bmcProblem{
+				severity:    analyzer.SeverityCritical,
+				reason:      "InvalidBootMACAddress",
+				description: "bootMACAddress is not a valid MAC address",
+				detail:      err.Error(),
+				resolution:  fmt.Sprintf("1. Set spec.bootMACAddress to the MAC of the provisioning NIC, e.g. 52:54:00:12:34:56: %s", edit),
+			})
+		} else if others := a.hostsWithBootMAC(host); len(others) > 0 {
+			problems = append(problems, bmcProblem{
+				severity:    analyzer.SeverityCritical,
+				reason:      "DuplicateBootMACAddress",
+				description: "bootMACAddress is used by another BareMetalHost",
+				detail:      fmt.Sprintf("%s is also used by %s", host.BootMACAddress, strings.Join(others, ", ")),
+				resolution:  "1. Verify the provisioning NIC MAC of each host in the BMC\n   2. Correct spec.bootMACAddress on the host with the wrong value; the webhook rejects duplicates only on creation",
+			})
+		}
+	} else if known && driver.NeedsMAC {
+		problems = append(problems, bmcProblem{
+			severity:    analyzer.SeverityCritical,
+			reason:      "MissingBootMACAddress",
+			description: fmt.Sprintf("bootMACAddress is required by the %s driver", host.BMCType),
+			detail:      fmt.Sprintf("The %s driver boots over PXE and needs spec.bootMACAddress", host.BMCType),
+			resolution:  fmt.Sprintf("1. Set spec.bootMACAddress to the MAC of the provisioning NIC: %s\n   2. Or use a virtual media driver such as redfish-virtualmedia, which does not need it", edit),
+		})
+	}
+
+	if known && !driver.TLS && host.DisableCertificateVerification {
+		problems = append(problems, bmcProblem{
+			severity:    analyzer.SeverityInfo,
+			reason:      "UnusedCertificateVerificationSetting",
+			description: "disableCertificateVerification has no effect",
+			detail:      fmt.Sprintf("The %s driver does not use TLS", host.BMCType),
+			resolution:  fmt.Sprintf("1. Remove spec.bmc.disableCertificateVerification: %s", edit),
+		})
+	}
+	if known && driver.TLS && !host.DisableCertificateVerification && mentionsCertificate(host.ErrorMessage) {
+		problems = append(problems, bmcProblem{
+			severity:    analyzer.SeverityWarning,
+			reason:      "BMCCertificateNotTrusted",
+			description: "BMC certificate cannot be verified",
+			detail:      host.ErrorMessage,
+			resolution:  fmt.Sprintf("1. Install a BMC certificate signed by a CA trusted by Ironic, valid for %s\n   2. Or set spec.bmc.disableCertificateVerification: true: %s", bmcHost, edit),
+		})
+	}
+
+	return problems
+}
+
+// bmcCredentialProblems checks that the credentials Secret exists and has a
+// username and password.
+func (a *Advisor) bmcCredentialProblems(bmh *analyzer.Component, host *inventory.Host, edit string) []bmcProblem {
+	if host.BMCCredentialsName == "" {
+		return []bmcProblem{{
+			severity:    analyzer.SeverityCritical,
+			reason:      "MissingBMCCredentials",
+			description: "BareMetalHost has no BMC credentials",
+			detail:      "spec.bmc.credentialsName is empty",
+			resolution:  fmt.Sprintf("1. Create a Secret: kubectl create secret generic %s-bmc-secret -n %s --from-literal=username=<user> --from-literal=password=<password>\n   2. Set spec.bmc.credentialsName: %s", bmh.Name, bmh.Namespace, edit),
+		}}
+	}
+
+	credentials, resolved := analyzer.HostBMCCredentials(bmh)
+	if !resolved {
+		return nil
+	}
+	if credentials.Error != "" {
+		return []bmcProblem{{
+			severity:    analyzer.SeverityInfo,
+			reason:      "BMCCredentialsUnverified",
+			description: "BMC credentials Secret could not be checked",
+			detail:      credentials.Error,
+			resolution:  fmt.Sprintf("1. Grant get on secrets in namespace %s to verify BMC credentials\n   2. Or check manually: kubectl get secret %s -n %s", bmh.Namespace, credentials.SecretName, bmh.Namespace),
+		}}
+	}
+	if !credentials.Found {
+		return []bmcProblem{{
+			severity:    analyzer.SeverityCritical,
+			reason:      "BMCSecretNotFound",
+			description: fmt.Sprintf("BMC credentials Secret %s does not exist", credentials.SecretName),
+			detail:      fmt.Sprintf("Secret %s/%s referenced by spec.bmc.credentialsName was not found", bmh.Namespace, credentials.SecretName),
+			resolution:  fmt.Sprintf("1. Create the Secret: kubectl create secret generic %s -n %s --from-literal=username=<user> --from-literal=password=<password>\n   2. Or point spec.bmc.credentialsName to an existing Secret: %s", credentials.SecretName, bmh.Namespace, edit),
+		}}
+	}
+
+	var missing []string
+	for _, key := range []string{"username", "password"} {
+		if !credentials.HasKey(key) {
+			state := "missing"
+			if containsString(credentials.EmptyKeys, key) {
+				state = "empty"
+			}
+			missing = append(missing, fmt.Sprintf("%s is %s", key, state))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return []bmcProblem{{
+		severity:    analyzer.SeverityCritical,
+		reason:      "BMCSecretIncomplete",
+		description: fmt.Sprintf("BMC credentials Secret %s is incomplete", credentials.SecretName),
+		detail:      fmt.Sprintf("In Secret %s/%s %s", bmh.Namespace, credentials.SecretName, strings.Join(missing, ", ")),
+		resolution: fmt.Sprintf("1. Check the keys: kubectl get secret %s -n %s -o jsonpath='{.data}'\n   2. The Secret needs non-empty username and password keys\n   3. Recreate it: kubectl create secret generic %s -n %s --from-literal=username=<user> --from-literal=password=<password> --dry-run=client -o yaml | kubectl apply -f -",
+			credentials.SecretName, bmh.Namespace, credentials.SecretName, bmh.Namespace),
+	}}
+}
+
+// bmcGuidance returns host specific guidance for BMC related conditions,
+// listing the detected misconfigurations or a driver specific checklist.
+func (a *Advisor) bmcGuidance(bmh *analyzer.Component) string {
+	host := a.inventoryHost(bmh)
+	if host == nil {
+		return ""
+	}
+
+	if problems := a.bmcProblems(bmh); len(problems) > 0 {
+		lines := []string{"   - BMC misconfiguration detected on this host:"}
+		for _, problem := range problems {
+			lines = append(lines, fmt.Sprintf("     * %s (%s)", problem.description, problem.detail))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	driver, _ := inventory.LookupBMCDriver(host.BMCType)
+	bmcHost, port, _, _ := inventory.BMCEndpoint(host.BMCAddress)
+	if port == "" {
+		port = driver.DefaultPort
+	}
+	transport := "tcp"
+	if driver.Protocol == "ipmi" {
+		transport = "udp"
+	}
+	return fmt.Sprintf("   - BMC connection issue detected. Verify:\n     * %s is reachable from the baremetal-operator and Ironic pods\n     * Firewall rules allow %s traffic (port %s/%s)\n     * The username and password in Secret %s are accepted by the BMC\n     * BMC firmware is up to date",
+		bmcHost, driver.Protocol, port, transport, host.BMCCredentialsName)
+}
+
+// inventoryHost returns the inventory entry of a BareMetalHost.
+func (a *Advisor) inventoryHost(bmh *analyzer.Component) *inventory.Host {
+	for _, host := range a.hosts {
+		if host.Component == bmh {
+			return host
+		}
+	}
+	return nil
+}
+
+// hostsWithBootMAC returns the other hosts using the boot MAC of host.
+func (a *Advisor) hostsWithBootMAC(host *inventory.Host) []string {
+	mac, _ := net.ParseMAC(host.BootMACAddress)
+	var others []string
+	for _, other := range a.hosts {
+		if other == host || other.BootMACAddress == "" {
+			continue
+		}
+		if otherMAC, err := net.ParseMAC(other.BootMACAddress); err == nil && otherMAC.String() == mac.String() {
+			others = append(others, other.Namespace+"/"+other.Name)
+		}
+	}
+	return others
+}
+
+func mentionsCertificate(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "x509") || strings.Contains(message, "certificate")
+}
@@ -0,0 +1,216 @@
+package advisor
+
+import (
+	"reflect"
+	"testing"
+
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/inventory"
+)
+
+func TestBMCProblems(t *testing.T) {
+	complete := &analyzer.BMCCredentials{SecretName: "bmc", Found: true, Keys: []string{"password", "username"}}
+
+	tests := []struct {
+		name         string
+		address      string
+		mac          string
+		credentials  *analyzer.BMCCredentials
+		disableCerts bool
+		state        string
+		errorMessage string
+		wantReasons  []string
+		wantDetail   string
+	}{
+		{
+			name:        "valid virtual media host",
+			address:     "redfish-virtualmedia://10.0.0.10/redfish/v1/Systems/1",
+			credentials: complete,
+		},
+		{
+			name:        "no address",
+			credentials: complete,
+			state:       "available",
+			wantReasons: []string{"MissingBMCAddress"},
+		},
+		{
+			name:  "no address on an externally provisioned host",
+			state: "externally provisioned",
+		},
+		{
+			name:        "unknown driver",
+			address:     "foo://10.0.0.10",
+			credentials: complete,
+			wantReasons: []string{"UnknownBMCDriver"},
+		},
+		{
+			name:        "unparsable address",
+			address:     "redfish-virtualmedia://[fd00::10/redfish/v1/Systems/1",
+			credentials: complete,
+			wantReasons: []string{"InvalidBMCAddress"},
+		},
+		{
+			name:        "address without host",
+			address:     "redfish-virtualmedia:///redfish/v1/Systems/1",
+			credentials: complete,
+			wantReasons: []string{"InvalidBMCAddress"},
+			wantDetail:  "redfish-virtualmedia:///redfish/v1/Systems/1 has no host",
+		},
+		{
+			name:        "redfish without system path",
+			address:     "redfish://10.0.0.10",
+			mac:         "52:54:00:00:00:10",
+			credentials: complete,
+			wantReasons: []string{"MissingRedfishSystemPath"},
+		},
+		{
+			name:        "ipmi address without scheme",
+			address:     "10.0.0.10:623",
+			mac:         "52:54:00:00:00:10",
+			credentials: complete,
+		},
+		{
+			name:        "no credentials Secret",
+			address:     "redfish-virtualmedia://10.0.0.10/redfish/v1/Systems/1",
+			wantReasons: []string{"MissingBMCCredentials"},
+		},
+		{
+			name:        "credentials Secret not found",
+			address:     "redfish-virtualmedia://10.0.0.10/redfish/v1/Systems/1",
+			credentials: &analyzer.BMCCredentials{SecretName: "bmc"},
+			wantReasons: []string{"BMCSecretNotFound"},
+		},
+		{
+			name:        "credentials Secret forbidden",
+			address:     "redfish-virtualmedia://10.0.0.10/redfish/v1/Systems/1",
+			credentials: &analyzer.BMCCredentials{SecretName: "bmc", Error: "secrets \"bmc\" is forbidden"},
+			wantReasons: []string{"BMCCredentialsUnverified"},
+		},
+		{
+			name:        "password missing",
+			address:     "redfish-virtualmedia://10.0.0.10/redfish/v1/Systems/1",
+			credentials: &analyzer.BMCCredentials{SecretName: "bmc", Found: true, Keys: []string{"username"}},
+			wantReasons: []string{"BMCSecretIncomplete"},
+			wantDetail:  "In Secret metal3/bmc password is missing",
+		},
+		{
+			name:        "password empty",
+			address:     "redfish-virtualmedia://10.0.0.10/redfish/v1/Systems/1",
+			credentials: &analyzer.BMCCredentials{SecretName: "bmc", Found: true, Keys: []string{"username"}, EmptyKeys: []string{"password"}},
+			wantReasons: []string{"BMCSecretIncomplete"},
+			wantDetail:  "In Secret metal3/bmc password is empty",
+		},
+		{
+			name:        "PXE driver without boot MAC",
+			address:     "ipmi://10.0.0.10",
+			credentials: complete,
+			wantReasons: []string{"MissingBootMACAddress"},
+		},
+		{
+			name:        "vendor PXE driver without boot MAC",
+			address:     "ilo5://10.0.0.10",
+			credentials: complete,
+			wantReasons: []string{"MissingBootMACAddress"},
+		},
+		{
+			name:        "vendor virtual media driver without boot MAC",
+			address:     "idrac-virtualmedia://10.0.0.10/redfish/v1/Systems/System.Embedded.1",
+			credentials: complete,
+		},
+		{
+			name:        "invalid boot MAC",
+			address:     "ipmi://10.0.0.10",
+			mac:         "52:54:00:00:00",
+			credentials: complete,
+			wantReasons: []string{"InvalidBootMACAddress"},
+		},
+		{
+			name:        "boot MAC of another host",
+			address:     "ipmi://10.0.0.10",
+			mac:         "52-54-00-00-00-01",
+			credentials: complete,
+			wantReasons: []string{"DuplicateBootMACAddress"},
+			wantDetail:  "52-54-00-00-00-01 is also used by metal3/host-1",
+		},
+		{
+			name:         "certificate verification disabled without TLS",
+			address:      "ipmi://10.0.0.10",
+			mac:          "52:54:00:00:00:10",
+			credentials:  complete,
+			disableCerts: true,
+			wantReasons:  []string{"UnusedCertificateVerificationSetting"},
+		},
+		{
+			name:         "untrusted certificate",
+			address:      "redfish-virtualmedia://10.0.0.10/redfish/v1/Systems/1",
+			credentials:  complete,
+			errorMessage: "Failed to get power state: x509: certificate signed by unknown authority",
+			wantReasons:  []string{"BMCCertificateNotTrusted"},
+		},
+		{
+			name:         "untrusted certificate with verification disabled",
+			address:      "redfish-virtualmedia://10.0.0.10/redfish/v1/Systems/1",
+			credentials:  complete,
+			disableCerts: true,
+			errorMessage: "Failed to get power state: x509: certificate signed by unknown authority",
+		},
+		{
+			name:        "several problems",
+			address:     "ipmi://10.0.0.10",
+			credentials: &analyzer.BMCCredentials{SecretName: "bmc"},
+			wantReasons: []string{"BMCSecretNotFound", "MissingBootMACAddress"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bmc := map[string]interface{}{"disableCertificateVerification": tt.disableCerts}
+			if tt.address != "" {
+				bmc["address"] = tt.address
+			}
+			spec := map[string]interface{}{"bmc": bmc}
+			if tt.mac != "" {
+				spec["bootMACAddress"] = tt.mac
+			}
+			bmh := &analyzer.Component{
+				Name:      "host-0",
+				Namespace: "metal3",
+				Type:      analyzer.BareMetalHostType,
+				Metadata: map[string]interface{}{
+					"spec": spec,
+					"status": map[string]interface{}{
+						"provisioning": map[string]interface{}{"state": tt.state},
+						"errorMessage": tt.errorMessage,
+					},
+				},
+			}
+			if tt.credentials != nil {
+				bmc["credentialsName"] = tt.credentials.SecretName
+				bmh.Metadata[analyzer.BMCCredentialsKey] = *tt.credentials
+			}
+			other := &analyzer.Component{
+				Name:      "host-1",
+				Namespace: "metal3",
+				Type:      analyzer.BareMetalHostType,
+				Metadata: map[string]interface{}{"spec": map[string]interface{}{
+					"bootMACAddress": "52:54:00:00:00:01",
+				}},
+			}
+
+			adv := NewAdvisor()
+			adv.hosts = inventory.BuildInventory([]*analyzer.Component{bmh, other})
+			problems := adv.bmcProblems(bmh)
+
+			var reasons []string
+			for _, problem := range problems {
+				reasons = append(reasons, problem.reason)
+			}
+			if !reflect.DeepEqual(reasons, tt.wantReasons) {
+				t.Fatalf("bmcProblems() reasons = %v, want %v", reasons, tt.wantReasons)
+			}
+			if tt.wantDetail != "" && problems[0].detail != tt.wantDetail {
+				t.Errorf("bmcProblems() detail = %q, want %q", problems[0].detail, tt.wantDetail)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package advisor
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	holdUpgradeSequenceAnnotation = "topology.cluster.x-k8s.io/hold-upgrade-sequence"
+	deferUpgradeAnnotation        = "topology.cluster.x-k8s.io/defer-upgrade"
+)
+
+var (
+	quotedPatchPattern     = regexp.MustCompile(`patch "([^"]+)"`)
+	quotedVariablePattern  = regexp.MustCompile(`variable "([^"]+)"`)
+	indexedVariablePattern = regexp.MustCompile(`variables\[([^\]]+)\]`)
+)
+
+// analyzeClusterClass reports templates referenced by a ClusterClass that do
+// not exist. Only kinds the advisor listed completely are checked, objects
+// of other kinds cannot be told apart from missing ones. A kind whose CRD is
+// not installed is reported as a warning, the CRD may be served under an API
+// group the advisor does not know.
+func (a *Advisor) analyzeClusterClass(class *analyzer.Component) []*analyzer.Issue {
+	var issues []*analyzer.Issue
+
+	for _, ref := range analyzer.ClusterClassTemplateRefs(class) {
+		outcome := a.kindOutcome(ref.Kind)
+		if outcome != analyzer.DiscoveryOK && outcome != analyzer.DiscoveryNotInstalled {
+			continue
+		}
+		if a.findComponent(ref.Name, ref.Namespace, analyzer.ComponentType(ref.Kind)) != nil {
+			continue
+		}
+
+		condition := metav1.Condition{
+			Type:    "TemplatesAvailable",
+			Status:  metav1.ConditionFalse,
+			Reason:  "TemplateNotFound",
+			Message: fmt.Sprintf("%s %s/%s referenced by %s was not found", ref.Kind, ref.Namespace, ref.Name, ref.Role),
+		}
+		issue := &analyzer.Issue{
+			Component:   class,
+			Condition:   condition,
+			Severity:    analyzer.SeverityCritical,
+			Description: fmt.Sprintf("ClusterClass references missing %s", ref.Kind),
+			Cause:       a.enhanceCause("A template referenced by the ClusterClass does not exist, so no Cluster using it can be reconciled", condition),
+			Resolution: fmt.Sprintf("1. Check whether the template exists: kubectl get %s %s -n %s\n   2. Create the missing template or update the %s reference in ClusterClass %s\n   3. Check the ClusterClass RefVersionsUpToDate condition after the change",
+				strings.ToLower(ref.Kind), ref.Name, ref.Namespace, ref.Role, class.Name),
+		}
+		if outcome == analyzer.DiscoveryNotInstalled {
+			issue.Condition.Reason = "TemplateKindNotInstalled"
+			issue.Condition.Message = fmt.Sprintf("%s %s/%s referenced by %s was not found, no CRD for %s is installed in the API groups the advisor lists",
+				ref.Kind, ref.Namespace, ref.Name, ref.Role, ref.Kind)
+			issue.Severity = analyzer.SeverityWarning
+			issue.Cause = a.enhanceCause("The template kind is not installed or is served under an API group the advisor does not list", issue.Condition)
+			issue.Resolution = fmt.Sprintf("1. Check whether the CRD is installed: kubectl api-resources | grep -i %s\n   2. Install the provider that serves %s or update the %s reference in ClusterClass %s",
+				strings.ToLower(ref.Kind), ref.Kind, ref.Role, class.Name)
+		}
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// analyzeTopology checks Clusters with a managed topology. It reports a
+// missing ClusterClass, explains TopologyReconciled failures found by the
+// condition analysis and reports version upgrades that are on hold.
+func (a *Advisor) analyzeTopology(cluster *analyzer.Component, issues []*analyzer.Issue) []*analyzer.Issue {
+	className, classNamespace := analyzer.ClusterClassRef(cluster)
+	if className == "" {
+		return issues
+	}
+
+	class := a.findComponent(className, classNamespace, analyzer.ClusterClassType)
+	if class == nil {
+		condition := metav1.Condition{
+			Type:    "TopologyReconciled",
+			Status:  metav1.ConditionFalse,
+			Reason:  "ClusterClassNotFound",
+			Message: fmt.Sprintf("ClusterClass %s/%s was not found", classNamespace, className),
+		}
+		issues = append(issues, &analyzer.Issue{
+			Component:   cluster,
+			Condition:   condition,
+			Severity:    analyzer.SeverityCritical,
+			Description: "Cluster references a missing ClusterClass",
+			Cause:       a.enhanceCause("The topology controller cannot compute the desired state without the ClusterClass", condition),
+			Resolution: fmt.Sprintf("1. List ClusterClasses: kubectl get clusterclass -n %s\n   2. Create ClusterClass %s or fix spec.topology.class\n   3. Check that the ClusterClass namespace is correct (spec.topology.classNamespace)",
+				classNamespace, className),
+		})
+		return issues
+	}
+
+	for _, issue := range issues {
+		if issue.Component == cluster && issue.Condition.Type == "TopologyReconciled" {
+			if details := a.explainTopologyFailure(cluster, class, issue.Condition); details != "" {
+				issue.Cause = fmt.Sprintf("%s\n%s", issue.Cause, details)
+			}
+		}
+	}
+
+	if issue := a.checkTopologyUpgrade(cluster, issues); issue != nil {
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// explainTopologyFailure lists the variables and patches involved in a
+// topology reconciliation failure, based on the condition message and the
+// definitions in the ClusterClass.
+func (a *Advisor) explainTopologyFailure(cluster, class *analyzer.Component, condition metav1.Condition) string {
+	var lines []string
+
+	classPatches := namedItems(class, "patches")
+	classVariables := namedItems(class, "variables")
+	clusterVariables := namedItems(cluster, "topology", "variables")
+
+	patches := mentionedNames(condition.Message, classPatches, quotedPatchPattern)
+	if len(patches) > 0 {
+		lines = append(lines, fmt.Sprintf("Patches involved: %s", strings.Join(patches, ", ")))
+	}
+	variables := mentionedNames(condition.Message, append(classVariables, clusterVariables...), quotedVariablePattern, indexedVariablePattern)
+	if len(variables) > 0 {
+		lines = append(lines, fmt.Sprintf("Variables involved: %s", strings.Join(variables, ", ")))
+	}
+
+	// Required variables of the ClusterClass that the Cluster does not set
+	if spec, ok := class.Metadata["spec"].(map[string]interface{}); ok {
+		definitions, _, _ := unstructured.NestedSlice(spec, "variables")
+		var missing []string
+		for _, def := range definitions {
+			defMap, ok := def.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := defMap["name"].(string)
+			if required, _ := defMap["required"].(bool); required && !containsString(clusterVariables, name) {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			lines = append(lines, fmt.Sprintf("Required variables not set in spec.topology.variables: %s", strings.Join(missing, ", ")))
+		}
+	}
+
+	// Variables set on the Cluster that the ClusterClass does not define
+	var undefined []string
+	for _, name := range clusterVariables {
+		if !containsString(classVariables, name) {
+			undefined = append(undefined, name)
+		}
+	}
+	if len(undefined) > 0 && len(classVariables) > 0 {
+		lines = append(lines, fmt.Sprintf("Variables not defined by ClusterClass %s: %s", class.Name, strings.Join(undefined, ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// checkTopologyUpgrade reports a Cluster whose control plane has not reached
+// spec.topology.version when no TopologyReconciled issue explains it already.
+func (a *Advisor) checkTopologyUpgrade(cluster *analyzer.Component, issues []*analyzer.Issue) *analyzer.Issue {
+	for _, issue := range issues {
+		if issue.Component == cluster && issue.Condition.Type == "TopologyReconciled" {
+			return nil
+		}
+	}
+
+	spec, ok := cluster.Metadata["spec"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	desiredVersion, _, _ := unstructured.NestedString(spec, "topology", "version")
+	cpName, _, _ := unstructured.NestedString(spec, "controlPlaneRef", "name")
+	cpKind, _, _ := unstructured.NestedString(spec, "controlPlaneRef", "kind")
+	if desiredVersion == "" || cpName == "" {
+		return nil
+	}
+
+	controlPlane := a.findComponent(cpName, cluster.Namespace, analyzer.ComponentType(cpKind))
+	if controlPlane == nil {
+		return nil
+	}
+	cpSpec, _ := controlPlane.Metadata["spec"].(map[string]interface{})
+	currentVersion, _, _ := unstructured.NestedString(cpSpec, "version")
+	if currentVersion == "" || currentVersion == desiredVersion {
+		return nil
+	}
+
+	var held []string
+	workers, _, _ := unstructured.NestedSlice(spec, "topology", "workers", "machineDeployments")
+	for _, worker := range workers {
+		workerMap, ok := worker.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := workerMap["name"].(string)
+		annotations, _, _ := unstructured.NestedStringMap(workerMap, "metadata", "annotations")
+		for _, annotation := range []string{holdUpgradeSequenceAnnotation, deferUpgradeAnnotation} {
+			if _, ok := annotations[annotation]; ok {
+				held = append(held, fmt.Sprintf("%s (%s)", name, annotation))
+			}
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:    "TopologyUpgradeCompleted",
+		Status:  metav1.ConditionFalse,
+		Reason:  "UpgradePending",
+		Message: fmt.Sprintf("spec.topology.version is %s but %s %s is at %s", desiredVersion, cpKind, cpName, currentVersion),
+	}
+	cause := "The topology version was changed and the rollout has not reached the control plane yet"
+	if len(held) > 0 {
+		cause = fmt.Sprintf("%s\nMachineDeployments holding the upgrade: %s", cause, strings.Join(held, ", "))
+	}
+
+	return &analyzer.Issue{
+		Component:    cluster,
+		Condition:    condition,
+		Severity:     analyzer.SeverityInfo,
+		Description:  fmt.Sprintf("Topology upgrade to %s is in progress or on hold", desiredVersion),
+		Cause:        a.enhanceCause(cause, condition),
+		Resolution:   "1. Check the control plane rollout: kubectl describe kcp <name>\n   2. Check for BeforeClusterUpgrade lifecycle hooks blocking the upgrade\n   3. Remove hold-upgrade-sequence or defer-upgrade annotations when the upgrade should continue",
+		Dependencies: []*analyzer.Component{controlPlane},
+	}
+}
+
+// namedItems returns the "name" fields of a list below spec.
+func namedItems(comp *analyzer.Component, fields ...string) []string {
+	spec, ok := comp.Metadata["spec"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	items, _, _ := unstructured.NestedSlice(spec, fields...)
+
+	var names []string
+	for _, item := range items {
+		if itemMap, ok := item.(map[string]interface{}); ok {
+			if name, ok := itemMap["name"].(string); ok && name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// mentionedNames returns the known names that occur in message plus all
+// names captured by the given patterns, sorted and deduplicated.
+func mentionedNames(message string, known []string, patterns ...*regexp.Regexp) []string {
+	found := make(map[string]bool)
+	for _, name := range known {
+		if strings.Contains(message, name) {
+			found[name] = true
+		}
+	}
+	for _, pattern := range patterns {
+		for _, match := range pattern.FindAllStringSubmatch(message, -1) {
+			found[match[1]] = true
+		}
+	}
+
+	var names []string
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isDiscoveredKind(kind string) bool {
+	for _, gvk := range analyzer.SupportedGVKs {
+		if gvk.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
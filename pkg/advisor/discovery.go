@@ -0,0 +1,139 @@
+package advisor
+
+import (
+	"fmt"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/render"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AnalyzeDiscovery adds the outcome of listing each kind to a result and
+// reports kinds that could not be listed, since the analysis misses their
+// objects and may look healthier than the cluster is.
+func (a *Advisor) AnalyzeDiscovery(result *analyzer.AnalysisResult, outcomes []analyzer.GVKDiscovery) {
+	result.Discovery = outcomes
+
+	incomplete := analyzer.IncompleteDiscovery(outcomes)
+	if len(incomplete) == 0 {
+		return
+	}
+
+	for _, outcome := range incomplete {
+		issue := a.discoveryIssue(outcome)
+		setRuleID(issue)
+		result.Issues = append(result.Issues, issue)
+		result.Summary.SeverityCounts[issue.Severity]++
+	}
+	sortIssues(result.Issues)
+	result.Summary.ClusterHealth = ClusterHealth(result.Summary.StatusCounts, result.Summary.SeverityCounts)
+}
+
+// kindOutcome returns how listing a kind went, combining the outcomes of the
+// API groups it is served from: objects may be missing if any group could
+// not be listed, and the CRD is only missing if no group is installed.
+// Without outcomes set by SetDiscovery the kinds the advisor discovers count
+// as listed. Kinds that are not discovered have an empty outcome.
+func (a *Advisor) kindOutcome(kind string) analyzer.DiscoveryOutcome {
+	if a.discovery == nil {
+		if isDiscoveredKind(kind) {
+			return analyzer.DiscoveryOK
+		}
+		return ""
+	}
+
+	var result analyzer.DiscoveryOutcome
+	for _, outcome := range a.discovery {
+		if outcome.GVK.Kind != kind && string(outcome.Type) != kind {
+			continue
+		}
+		switch {
+		case outcome.Outcome.Incomplete():
+			return outcome.Outcome
+		case outcome.Outcome == analyzer.DiscoveryOK:
+			result = analyzer.DiscoveryOK
+		case result == "":
+			result = outcome.Outcome
+		}
+	}
+	return result
+}
+
+func (a *Advisor) discoveryIssue(outcome analyzer.GVKDiscovery) *analyzer.Issue {
+	resource := strings.ToLower(outcome.GVK.Kind)
+	if outcome.GVK.Group != "" {
+		resource += "." + outcome.GVK.Group
+	}
+
+	condition := metav1.Condition{
+		Type:    "Discovered",
+		Status:  metav1.ConditionFalse,
+		Reason:  string(outcome.Outcome),
+		Message: outcome.Error,
+	}
+	issue := &analyzer.Issue{
+		// The issue concerns every object of the kind
+		Component: &analyzer.Component{
+			Name:   "*",
+			Type:   outcome.Type,
+			GVK:    outcome.GVK,
+			Status: analyzer.StatusUnknown,
+		},
+		Condition: condition,
+		Severity:  analyzer.SeverityWarning,
+	}
+
+	switch outcome.Outcome {
+	case analyzer.DiscoveryForbidden:
+		issue.Description = fmt.Sprintf("Analysis is incomplete: not allowed to list %s objects", outcome.Type)
+		issue.Cause = a.enhanceCause(fmt.Sprintf("The current user lacks list permission on %s, so these objects and their issues are missing from the analysis", resource), condition)
+		issue.Resolution = fmt.Sprintf("1. Check your permissions: kubectl auth can-i list %s --all-namespaces\n   2. Grant get, list on %s with a ClusterRole, or limit the analysis to a namespace you can read with -n\n   3. Re-run the analysis", resource, resource)
+	case analyzer.DiscoveryTimeout:
+		issue.Description = fmt.Sprintf("Analysis is incomplete: listing %s objects timed out", outcome.Type)
+		issue.Cause = a.enhanceCause(fmt.Sprintf("The API server did not answer the list of %s in time", resource), condition)
+		issue.Resolution = "1. Re-run with a longer --request-timeout\n   2. Lower --page-size to request fewer objects per call\n   3. Check API server load and latency"
+	default:
+		issue.Description = fmt.Sprintf("Analysis is incomplete: could not list %s objects", outcome.Type)
+		issue.Cause = a.enhanceCause(fmt.Sprintf("Listing %s failed", resource), condition)
+		issue.Resolution = fmt.Sprintf("1. Try listing the objects: kubectl get %s -A\n   2. Check connectivity to the API server and re-run the analysis", resource)
+	}
+
+	return issue
+}
+
+// DiscoveryReport renders the outcome of discovery: how many kinds were
+// listed and which ones could not be listed completely.
+func DiscoveryReport(outcomes []analyzer.GVKDiscovery) string {
+	if len(outcomes) == 0 {
+		return ""
+	}
+
+	var report strings.Builder
+	counts := make(map[analyzer.DiscoveryOutcome]int)
+	var slowest analyzer.GVKDiscovery
+	for _, outcome := range outcomes {
+		counts[outcome.Outcome]++
+		if outcome.DurationMs > slowest.DurationMs {
+			slowest = outcome
+		}
+	}
+
+	report.WriteString(render.Heading("🔎 DISCOVERY") + "\n")
+	report.WriteString(fmt.Sprintf("Kinds Listed: %d, Not Installed: %d, Incomplete: %d\n", counts[analyzer.DiscoveryOK],
+		counts[analyzer.DiscoveryNotInstalled], len(outcomes)-counts[analyzer.DiscoveryOK]-counts[analyzer.DiscoveryNotInstalled]))
+	if slowest.DurationMs > 0 {
+		report.WriteString(fmt.Sprintf("Slowest: %s (%d objects in %dms)\n", slowest.Type, slowest.Count, slowest.DurationMs))
+	}
+
+	incomplete := analyzer.IncompleteDiscovery(outcomes)
+	if len(incomplete) > 0 {
+		report.WriteString("⚠️  Incomplete, these kinds could not be listed:\n")
+		for _, outcome := range incomplete {
+			report.WriteString(fmt.Sprintf("  %s %s %s (%s): %s\n", render.DiscoveryIcon(outcome.Outcome), outcome.Outcome,
+				outcome.Type, outcome.GVK.GroupVersion(), outcome.Error))
+		}
+	}
+
+	return report.String()
+}
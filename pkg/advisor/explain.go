@@ -0,0 +1,294 @@
+package advisor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/render"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// Explanation is the deep dive into a single component: the objects it
+// hangs off up to its Cluster, the objects it links down to, the issues
+// along that chain and how the knowledge base rates its conditions.
+type Explanation struct {
+	Component *analyzer.Component
+	// Ancestors are the parent of the component up to its Cluster
+	Ancestors []*analyzer.Component
+	// Descendants are the objects the component links down to, such as the
+	// BareMetalHost and bootstrap config of a Machine
+	Descendants []*analyzer.Component
+	// Rules are the rules for the conditions of the component, matched or not
+	Rules []RuleMatch
+	// Controller is the controller that reconciles the component
+	Controller string
+	// Steps are the issues of the chain, deepest dependency first, since
+	// fixing a dependency often resolves the objects above it
+	Steps []*analyzer.Issue
+}
+
+// RuleMatch is a rule for a condition type the component has.
+type RuleMatch struct {
+	RuleID      string
+	Description string
+	Matched     bool
+	// Miss tells why a rule for a condition of the component did not match
+	Miss string
+}
+
+// Explain scopes the result of AnalyzeComponents to one component and the
+// chain of objects it depends on.
+func (a *Advisor) Explain(comp *analyzer.Component, result *analyzer.AnalysisResult) *Explanation {
+	explanation := &Explanation{
+		Component:  comp,
+		Controller: Controller(comp),
+	}
+
+	for ancestor := comp.Parent; ancestor != nil; ancestor = ancestor.Parent {
+		explanation.Ancestors = append(explanation.Ancestors, ancestor)
+		if ancestor.Type == analyzer.ClusterType {
+			break
+		}
+	}
+
+	// Depth of every object in the chain, counted from the Cluster down
+	depth := make(map[*analyzer.Component]int)
+	for i, ancestor := range explanation.Ancestors {
+		depth[ancestor] = len(explanation.Ancestors) - 1 - i
+	}
+	depth[comp] = len(explanation.Ancestors)
+	var walk func(parent *analyzer.Component)
+	walk = func(parent *analyzer.Component) {
+		for _, child := range a.children(parent) {
+			if _, seen := depth[child]; seen {
+				continue
+			}
+			depth[child] = depth[parent] + 1
+			explanation.Descendants = append(explanation.Descendants, child)
+			walk(child)
+		}
+	}
+	walk(comp)
+
+	var own []*analyzer.Issue
+	for _, issue := range result.Issues {
+		if _, inChain := depth[issue.Component]; inChain {
+			explanation.Steps = append(explanation.Steps, issue)
+		}
+		if issue.Component == comp {
+			own = append(own, issue)
+		}
+	}
+	sort.SliceStable(explanation.Steps, func(i, j int) bool {
+		return depth[explanation.Steps[i].Component] > depth[explanation.Steps[j].Component]
+	})
+	explanation.Rules = a.ruleMatches(comp, own)
+
+	return explanation
+}
+
+// ruleMatches lists the knowledge base rules for the condition types of a
+// component. Rules that did not match are near misses: the condition has
+// another status or reason, or a rule for its reason took precedence.
+// Issues of checks outside the knowledge base are listed as matched rules.
+func (a *Advisor) ruleMatches(comp *analyzer.Component, issues []*analyzer.Issue) []RuleMatch {
+	matched := make(map[string]bool)
+	for _, issue := range issues {
+		matched[issue.RuleID] = true
+	}
+
+	keys := make([]string, 0, len(a.knowledgeBase))
+	for key := range a.knowledgeBase {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var matches []RuleMatch
+	for _, key := range keys {
+		// Keys are "Kind.ConditionType.Status" or "Kind.ConditionType.Status.Reason"
+		parts := strings.SplitN(key, ".", 4)
+		if len(parts) < 3 || parts[0] != string(comp.Type) {
+			continue
+		}
+		condition := findCondition(comp, parts[1])
+		if condition == nil {
+			continue
+		}
+
+		match := RuleMatch{RuleID: key, Description: a.knowledgeBase[key].Condition, Matched: matched[key]}
+		switch {
+		case match.Matched:
+		case string(condition.Status) != parts[2]:
+			match.Miss = fmt.Sprintf("%s is %s, the rule applies when it is %s", condition.Type, condition.Status, parts[2])
+		case len(parts) == 4 && condition.Reason != parts[3]:
+			reason := condition.Reason
+			if reason == "" {
+				reason = "<none>"
+			}
+			match.Miss = fmt.Sprintf("the reason is %s, the rule applies to %s", reason, parts[3])
+		default:
+			match.Miss = fmt.Sprintf("the rule for reason %s takes precedence", condition.Reason)
+		}
+		matches = append(matches, match)
+	}
+
+	for _, issue := range issues {
+		if _, inKnowledgeBase := a.knowledgeBase[issue.RuleID]; !inKnowledgeBase {
+			matches = append(matches, RuleMatch{RuleID: issue.RuleID, Description: issue.Description, Matched: true})
+		}
+	}
+	return matches
+}
+
+func findCondition(comp *analyzer.Component, conditionType string) *metav1.Condition {
+	for i := range comp.Conditions {
+		if comp.Conditions[i].Type == conditionType {
+			return &comp.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// Controller names the controller that reconciles a component, by the API
+// group of its kind.
+func Controller(comp *analyzer.Component) string {
+	group := comp.GVK.Group
+	switch {
+	case strings.HasPrefix(string(comp.Type), "Metal3") && group == "infrastructure.cluster.x-k8s.io":
+		return "capm3-controller-manager (namespace capm3-system)"
+	case group == "ipam.metal3.io":
+		return "ipam-controller-manager (namespace capm3-system)"
+	case group == "metal3.io":
+		return "baremetal-operator-controller-manager (namespace baremetal-operator-system) and Ironic"
+	case comp.Type == analyzer.KubeadmConfigType || comp.Type == analyzer.KubeadmConfigTemplateType:
+		return "capi-kubeadm-bootstrap-controller-manager (namespace capi-kubeadm-bootstrap-system)"
+	case comp.Type == analyzer.KubeadmControlPlaneType || comp.Type == analyzer.KubeadmControlPlaneTemplateType:
+		return "capi-kubeadm-control-plane-controller-manager (namespace capi-kubeadm-control-plane-system)"
+	case group == "cluster.x-k8s.io" || group == "addons.cluster.x-k8s.io":
+		return "capi-controller-manager (namespace capi-system)"
+	}
+	if provider := analyzer.ProviderFor(comp.Type); provider != nil {
+		return fmt.Sprintf("the controller manager of the %s provider", provider.Name())
+	}
+	if group == "" {
+		return "unknown"
+	}
+	return fmt.Sprintf("the controller of the %s API group", group)
+}
+
+// Chain returns the objects of the explanation from the Cluster down.
+func (e *Explanation) Chain() []*analyzer.Component {
+	chain := make([]*analyzer.Component, 0, len(e.Ancestors)+1+len(e.Descendants))
+	for i := len(e.Ancestors) - 1; i >= 0; i-- {
+		chain = append(chain, e.Ancestors[i])
+	}
+	chain = append(chain, e.Component)
+	return append(chain, e.Descendants...)
+}
+
+// Report renders an explanation as text.
+func (e *Explanation) Report() string {
+	var b strings.Builder
+	comp := e.Component
+	now := time.Now()
+
+	b.WriteString(render.Heading(fmt.Sprintf("🔎 %s/%s", comp.Type, comp.Name)) + "\n")
+	b.WriteString(strings.Repeat("=", 50) + "\n")
+	if comp.Namespace != "" {
+		fmt.Fprintf(&b, "Namespace:  %s\n", comp.Namespace)
+	}
+	fmt.Fprintf(&b, "Status:     %s %s\n", render.StatusIcon(comp.Status), render.Status(comp.Status, string(comp.Status)))
+	if phase := analyzer.Phase(comp); phase != "" {
+		fmt.Fprintf(&b, "Phase:      %s\n", phase)
+	}
+	if created := analyzer.CreationTimestamp(comp); !created.IsZero() {
+		fmt.Fprintf(&b, "Age:        %s\n", duration.HumanDuration(now.Sub(created)))
+	}
+	fmt.Fprintf(&b, "Controller: %s\n", e.Controller)
+
+	// Chain from the Cluster down to the objects the component depends on
+	b.WriteString("\n" + render.Heading("🔗 DEPENDENCY CHAIN") + "\n")
+	for _, chained := range e.Chain() {
+		marker := ""
+		if chained == comp {
+			marker = "  <- this object"
+		}
+		writeChainEntry(&b, chained, marker)
+	}
+
+	b.WriteString("\n" + render.Heading("📋 CONDITIONS") + "\n")
+	if len(comp.Conditions) == 0 {
+		b.WriteString("  No conditions reported\n")
+	}
+	for _, condition := range comp.Conditions {
+		age := "unknown"
+		if !condition.LastTransitionTime.IsZero() {
+			age = duration.HumanDuration(now.Sub(condition.LastTransitionTime.Time))
+		}
+		fmt.Fprintf(&b, "  %s %s=%s for %s", render.ConditionIcon(condition.Status), condition.Type, condition.Status, age)
+		if condition.Reason != "" {
+			fmt.Fprintf(&b, " (%s)", condition.Reason)
+		}
+		b.WriteString("\n")
+		if condition.Message != "" {
+			b.WriteString(render.Wrap("      ", condition.Message, "      ") + "\n")
+		}
+	}
+
+	// Events of the whole chain, a failing host often explains its Machine
+	b.WriteString("\n" + render.Heading("📰 EVENTS") + "\n")
+	recorded := false
+	for _, chained := range e.Chain() {
+		events := analyzer.ComponentEvents(chained)
+		if len(events) == 0 {
+			continue
+		}
+		recorded = true
+		fmt.Fprintf(&b, "  %s/%s:\n", chained.Type, chained.Name)
+		for _, event := range events {
+			seen := "unknown"
+			if !event.LastSeen.IsZero() {
+				seen = duration.HumanDuration(now.Sub(event.LastSeen)) + " ago"
+			}
+			line := fmt.Sprintf("%s %s %s (x%d): ", seen, event.Type, event.Reason, event.Count)
+			b.WriteString(render.Wrap("    "+line, event.Message, "      ") + "\n")
+		}
+	}
+	if !recorded {
+		b.WriteString("  No events recorded\n")
+	}
+
+	b.WriteString("\n" + render.Heading("📚 KNOWLEDGE BASE RULES") + "\n")
+	if len(e.Rules) == 0 {
+		b.WriteString("  No rules for the conditions of this component\n")
+	}
+	for _, rule := range e.Rules {
+		if rule.Matched {
+			fmt.Fprintf(&b, "  matched    %s: %s\n", rule.RuleID, rule.Description)
+		} else {
+			fmt.Fprintf(&b, "  near miss  %s: %s\n", rule.RuleID, rule.Miss)
+		}
+	}
+
+	b.WriteString("\n" + render.Heading("💡 STEP-BY-STEP RESOLUTION") + "\n")
+	if len(e.Steps) == 0 {
+		b.WriteString("✅ No issues found in the dependency chain.\n")
+	}
+	for i, issue := range e.Steps {
+		fmt.Fprintf(&b, "\n%d. %s %s/%s: %s\n", i+1, render.SeverityIcon(issue.Severity),
+			issue.Component.Type, issue.Component.Name, render.Severity(issue.Severity, issue.Description))
+		b.WriteString(render.Wrap("   Cause: ", issue.Cause, "      ") + "\n")
+		b.WriteString(render.Wrap("   Resolution: ", render.Resolution(issue.Resolution), "      ") + "\n")
+	}
+
+	return b.String()
+}
+
+func writeChainEntry(b *strings.Builder, comp *analyzer.Component, marker string) {
+	fmt.Fprintf(b, "  %s %s/%s%s\n", render.StatusIcon(comp.Status), comp.Type, render.Status(comp.Status, comp.Name), marker)
+}
@@ -0,0 +1,289 @@
+package advisor
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// analyzeHostFirmwareSettings validates the requested BIOS settings against
+// the FirmwareSchema of the host and reports settings that are requested in
+// spec but not applied in status.
+func (a *Advisor) analyzeHostFirmwareSettings(settings *analyzer.Component) []*analyzer.Issue {
+	var issues []*analyzer.Issue
+
+	spec, _ := settings.Metadata["spec"].(map[string]interface{})
+	status, _ := settings.Metadata["status"].(map[string]interface{})
+	desired, _, _ := unstructured.NestedMap(spec, "settings")
+	if len(desired) == 0 {
+		return nil
+	}
+	applied, _, _ := unstructured.NestedMap(status, "settings")
+
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Validate against the schema
+	if schema := a.firmwareSchema(settings); schema != nil {
+		var invalid []string
+		for _, name := range names {
+			if problem := validateSetting(schema, name, fmt.Sprint(desired[name])); problem != "" {
+				invalid = append(invalid, problem)
+			}
+		}
+		if len(invalid) > 0 {
+			condition := metav1.Condition{
+				Type:    "Valid",
+				Status:  metav1.ConditionFalse,
+				Reason:  "InvalidSettings",
+				Message: strings.Join(invalid, "; "),
+			}
+			issues = append(issues, &analyzer.Issue{
+				Component:   settings,
+				Condition:   condition,
+				Severity:    analyzer.SeverityCritical,
+				Description: fmt.Sprintf("%d BIOS setting(s) are invalid for the FirmwareSchema", len(invalid)),
+				Cause:       a.enhanceCause("Requested settings do not exist, are read-only or are outside the allowed values of the host's firmware", condition),
+				Resolution: fmt.Sprintf("1. Show the schema: kubectl get firmwareschema -n %s -o yaml\n   2. Fix or remove the listed entries in spec.settings of HostFirmwareSettings %s\n   3. Invalid settings make cleaning fail and block provisioning of the host",
+					settings.Namespace, settings.Name),
+			})
+		}
+	}
+
+	// Compare requested and applied settings
+	var pending []string
+	for _, name := range names {
+		if value, ok := applied[name]; !ok || fmt.Sprint(value) != fmt.Sprint(desired[name]) {
+			pending = append(pending, fmt.Sprintf("%s=%v (current: %v)", name, desired[name], valueOrNone(applied[name])))
+		}
+	}
+	if len(pending) > 0 {
+		severity := analyzer.SeverityInfo
+		if bmh := a.findComponent(settings.Name, settings.Namespace, analyzer.BareMetalHostType); bmh != nil && hostProvisioned(bmh) {
+			severity = analyzer.SeverityWarning
+		}
+		condition := metav1.Condition{
+			Type:    "SettingsApplied",
+			Status:  metav1.ConditionFalse,
+			Reason:  "ChangeDetected",
+			Message: strings.Join(pending, "; "),
+		}
+		issues = append(issues, &analyzer.Issue{
+			Component:   settings,
+			Condition:   condition,
+			Severity:    severity,
+			Description: fmt.Sprintf("%d BIOS setting(s) differ between spec and status", len(pending)),
+			Cause:       a.enhanceCause("BIOS settings are only applied while the host is cleaned or serviced, so requested changes are still pending", condition),
+			Resolution:  "1. Provisioned hosts apply settings during servicing; deprovision or enable servicing to apply them\n   2. Available hosts apply settings during the next cleaning\n   3. Check the Valid and ChangeDetected conditions of the HostFirmwareSettings",
+		})
+	}
+
+	return issues
+}
+
+// analyzeHostFirmwareComponents reports firmware updates that are requested
+// but not yet applied.
+func (a *Advisor) analyzeHostFirmwareComponents(firmware *analyzer.Component) []*analyzer.Issue {
+	spec, _ := firmware.Metadata["spec"].(map[string]interface{})
+	status, _ := firmware.Metadata["status"].(map[string]interface{})
+	requested, _, _ := unstructured.NestedSlice(spec, "updates")
+	applied, _, _ := unstructured.NestedSlice(status, "updates")
+
+	var pending []string
+	for _, update := range requested {
+		if !containsUpdate(applied, update) {
+			if updateMap, ok := update.(map[string]interface{}); ok {
+				pending = append(pending, fmt.Sprintf("%v from %v", updateMap["component"], updateMap["url"]))
+			}
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:    "UpdatesApplied",
+		Status:  metav1.ConditionFalse,
+		Reason:  "UpdatesPending",
+		Message: strings.Join(pending, "; "),
+	}
+	return []*analyzer.Issue{{
+		Component:   firmware,
+		Condition:   condition,
+		Severity:    analyzer.SeverityInfo,
+		Description: fmt.Sprintf("%d firmware update(s) are pending", len(pending)),
+		Cause:       a.enhanceCause("Firmware updates are only applied while the host is cleaned or serviced", condition),
+		Resolution:  "1. Verify the update URLs are reachable from the Ironic conductor\n   2. Updates are applied during the next cleaning or servicing of the host\n   3. Check status.components for the current versions after the update",
+	}}
+}
+
+// analyzeHostConfigurationDrift reports BareMetalHosts whose requested RAID
+// or firmware configuration differs from the one applied during provisioning,
+// and hosts whose firmware differs from the other hosts of their pool.
+func (a *Advisor) analyzeHostConfigurationDrift(bmh *analyzer.Component) []*analyzer.Issue {
+	var issues []*analyzer.Issue
+
+	spec, _ := bmh.Metadata["spec"].(map[string]interface{})
+	status, _ := bmh.Metadata["status"].(map[string]interface{})
+	for _, field := range []string{"raid", "firmware"} {
+		label := strings.ToUpper(field)
+		if field == "firmware" {
+			label = "BIOS"
+		}
+		desired, desiredFound, _ := unstructured.NestedFieldNoCopy(spec, field)
+		current, _, _ := unstructured.NestedFieldNoCopy(status, "provisioning", field)
+		if !desiredFound || reflect.DeepEqual(desired, current) {
+			continue
+		}
+
+		condition := metav1.Condition{
+			Type:    "ConfigurationApplied",
+			Status:  metav1.ConditionFalse,
+			Reason:  label + "ConfigurationDrift",
+			Message: fmt.Sprintf("spec.%s differs from status.provisioning.%s", field, field),
+		}
+		issues = append(issues, &analyzer.Issue{
+			Component:   bmh,
+			Condition:   condition,
+			Severity:    analyzer.SeverityWarning,
+			Description: fmt.Sprintf("Requested %s configuration is not applied", label),
+			Cause:       a.enhanceCause("The configuration is applied during cleaning; the host was provisioned with a different configuration", condition),
+			Resolution: fmt.Sprintf("1. Compare: kubectl get bmh %s -n %s -o jsonpath='{.spec.%s}{\"\\n\"}{.status.provisioning.%s}'\n   2. Deprovision the host to apply the configuration during cleaning\n   3. Check that the BMC driver supports the requested %s configuration",
+				bmh.Name, bmh.Namespace, field, field, label),
+		})
+	}
+
+	for _, drift := range a.drifts {
+		outliers := drift.Outliers()
+		value, isOutlier := outliers[bmh.Name]
+		if !isOutlier || drift.Pool != a.hostPool(bmh) {
+			continue
+		}
+
+		severity := analyzer.SeverityWarning
+		description := fmt.Sprintf("Firmware %s version differs from its pool", drift.Item)
+		if strings.HasPrefix(drift.Item, "setting:") {
+			severity = analyzer.SeverityInfo
+			description = fmt.Sprintf("BIOS setting %s differs from its pool", strings.TrimPrefix(drift.Item, "setting:"))
+		}
+		condition := metav1.Condition{
+			Type:    "FirmwareConsistent",
+			Status:  metav1.ConditionFalse,
+			Reason:  "FirmwareDrift",
+			Message: fmt.Sprintf("%s is %s on this host, %s on %d other host(s) of pool %s", drift.Item, value, drift.Majority, len(drift.Values[drift.Majority]), drift.Pool),
+		}
+		issues = append(issues, &analyzer.Issue{
+			Component:   bmh,
+			Condition:   condition,
+			Severity:    severity,
+			Description: description,
+			Cause:       a.enhanceCause("Hosts of the same model run different firmware, which causes inconsistent behaviour during provisioning", condition),
+			Resolution:  "1. Show firmware of all hosts: capi-advisor hosts --firmware\n   2. Align versions with HostFirmwareComponents spec.updates or BIOS settings with HostFirmwareSettings\n   3. Updates are applied during the next cleaning or servicing of the host",
+		})
+	}
+
+	return issues
+}
+
+func (a *Advisor) hostPool(bmh *analyzer.Component) string {
+	if host := a.inventoryHost(bmh); host != nil {
+		return host.PoolName()
+	}
+	return ""
+}
+
+// firmwareSchema returns spec.schema of the FirmwareSchema referenced by a
+// HostFirmwareSettings.
+func (a *Advisor) firmwareSchema(settings *analyzer.Component) map[string]interface{} {
+	status, _ := settings.Metadata["status"].(map[string]interface{})
+	name, _, _ := unstructured.NestedString(status, "schema", "name")
+	namespace, _, _ := unstructured.NestedString(status, "schema", "namespace")
+	if namespace == "" {
+		namespace = settings.Namespace
+	}
+	schema := a.findComponent(name, namespace, analyzer.FirmwareSchemaType)
+	if schema == nil {
+		return nil
+	}
+	spec, _ := schema.Metadata["spec"].(map[string]interface{})
+	entries, _, _ := unstructured.NestedMap(spec, "schema")
+	return entries
+}
+
+// validateSetting checks a single setting against its FirmwareSchema entry
+// and returns a description of the problem, if any.
+func validateSetting(schema map[string]interface{}, name, value string) string {
+	entry, ok := schema[name].(map[string]interface{})
+	if !ok {
+		return fmt.Sprintf("%s is not a known setting", name)
+	}
+	if readOnly, _ := entry["read_only"].(bool); readOnly {
+		return fmt.Sprintf("%s is read-only", name)
+	}
+
+	attributeType, _ := entry["attribute_type"].(string)
+	switch attributeType {
+	case "Enumeration":
+		allowed, _, _ := unstructured.NestedStringSlice(entry, "allowable_values")
+		for _, v := range allowed {
+			if v == value {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%s=%s is not one of %s", name, value, strings.Join(allowed, ", "))
+	case "Integer":
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Sprintf("%s=%s is not an integer", name, value)
+		}
+		if lower, found, _ := unstructured.NestedInt64(entry, "lower_bound"); found && number < lower {
+			return fmt.Sprintf("%s=%s is below %d", name, value, lower)
+		}
+		if upper, found, _ := unstructured.NestedInt64(entry, "upper_bound"); found && number > upper {
+			return fmt.Sprintf("%s=%s is above %d", name, value, upper)
+		}
+	case "String":
+		if minLength, found, _ := unstructured.NestedInt64(entry, "min_length"); found && int64(len(value)) < minLength {
+			return fmt.Sprintf("%s is shorter than %d characters", name, minLength)
+		}
+		if maxLength, found, _ := unstructured.NestedInt64(entry, "max_length"); found && int64(len(value)) > maxLength {
+			return fmt.Sprintf("%s is longer than %d characters", name, maxLength)
+		}
+	case "Boolean":
+		if value != "true" && value != "false" {
+			return fmt.Sprintf("%s=%s is not a boolean", name, value)
+		}
+	}
+	return ""
+}
+
+func containsUpdate(updates []interface{}, update interface{}) bool {
+	for _, u := range updates {
+		if reflect.DeepEqual(u, update) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostProvisioned(bmh *analyzer.Component) bool {
+	status, _ := bmh.Metadata["status"].(map[string]interface{})
+	state, _, _ := unstructured.NestedString(status, "provisioning", "state")
+	return state == "provisioned" || state == "externally provisioned"
+}
+
+func valueOrNone(value interface{}) interface{} {
+	if value == nil {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,169 @@
+package advisor
+
+import (
+	"fmt"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// poolWarningPercent is the IPPool utilization that raises a warning.
+const poolWarningPercent = 90
+
+// analyzeIPPool reports exhausted or nearly exhausted Metal3 IPPools.
+func (a *Advisor) analyzeIPPool(pool *analyzer.Component) []*analyzer.Issue {
+	util, err := analyzer.IPPoolUtilization(pool)
+	if err != nil {
+		condition := metav1.Condition{
+			Type:    "PoolValid",
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidPoolRange",
+			Message: err.Error(),
+		}
+		return []*analyzer.Issue{{
+			Component:   pool,
+			Condition:   condition,
+			Severity:    analyzer.SeverityWarning,
+			Description: "IPPool address ranges cannot be evaluated",
+			Cause:       a.enhanceCause("The IPPool spec.pools entries are incomplete or invalid", condition),
+			Resolution:  "1. Check IPPool: kubectl get ippool <name> -o yaml\n   2. Every entry in spec.pools needs start/end or a subnet\n   3. Verify start is lower than end and both are inside the subnet",
+		}}
+	}
+	if util.Capacity == 0 || util.Percent() < poolWarningPercent {
+		return nil
+	}
+
+	waiting := a.waitingClaims[a.getComponentKey(pool.Namespace, pool.Name, analyzer.IPPoolType)]
+
+	condition := metav1.Condition{
+		Type:    "AddressesAvailable",
+		Status:  metav1.ConditionFalse,
+		Reason:  "PoolNearlyExhausted",
+		Message: fmt.Sprintf("%d of %d addresses allocated (%.0f%%), %d claim(s) waiting", util.Allocated, util.Capacity, util.Percent(), waiting),
+	}
+	severity := analyzer.SeverityWarning
+	description := fmt.Sprintf("IPPool is %.0f%% allocated", util.Percent())
+	if util.Allocated >= util.Capacity {
+		condition.Reason = "PoolExhausted"
+		severity = analyzer.SeverityCritical
+		description = "IPPool has no free addresses"
+	}
+
+	return []*analyzer.Issue{{
+		Component:   pool,
+		Condition:   condition,
+		Severity:    severity,
+		Description: description,
+		Cause:       a.enhanceCause("New Metal3Data cannot be rendered without free addresses, so Metal3Machines never become Ready", condition),
+		Resolution:  "1. Check allocations: kubectl get ippool <name> -o jsonpath='{.status.indexes}'\n   2. Remove stale IPClaims of deleted machines: kubectl get ipclaims -n <namespace>\n   3. Extend spec.pools with an additional range or a larger subnet\n   4. Scale down MachineDeployments that wait for addresses until the pool is extended",
+	}}
+}
+
+// analyzeIPClaim reports IPClaims that did not get an address.
+func (a *Advisor) analyzeIPClaim(claim *analyzer.Component) []*analyzer.Issue {
+	if ipClaimBound(claim) {
+		return nil
+	}
+
+	poolNamespace, poolName := ipClaimPool(claim)
+	pool := a.findComponent(poolName, poolNamespace, analyzer.IPPoolType)
+	status, _ := claim.Metadata["status"].(map[string]interface{})
+	errorMessage, _, _ := unstructured.NestedString(status, "errorMessage")
+
+	condition := metav1.Condition{
+		Type:    "AddressBound",
+		Status:  metav1.ConditionFalse,
+		Reason:  "Unbound",
+		Message: errorMessage,
+	}
+	issue := &analyzer.Issue{
+		Component:   claim,
+		Condition:   condition,
+		Severity:    analyzer.SeverityWarning,
+		Description: "IPClaim is not bound to an IPAddress",
+		Resolution:  fmt.Sprintf("1. Check IPClaim: kubectl describe ipclaim %s -n %s\n   2. Check IPPool %s/%s utilization and ranges\n   3. Review ip-address-manager controller logs", claim.Name, claim.Namespace, poolNamespace, poolName),
+	}
+
+	switch {
+	case pool == nil && a.kindOutcome(string(analyzer.IPPoolType)) == analyzer.DiscoveryOK:
+		issue.Condition.Reason = "PoolNotFound"
+		if issue.Condition.Message == "" {
+			issue.Condition.Message = fmt.Sprintf("IPPool %s/%s was not found", poolNamespace, poolName)
+		}
+		issue.Severity = analyzer.SeverityCritical
+		issue.Cause = a.enhanceCause("The IPClaim references an IPPool that does not exist", issue.Condition)
+		issue.Resolution = fmt.Sprintf("1. List pools: kubectl get ippools -n %s\n   2. Fix the pool reference in the Metal3DataTemplate networkData or metaData\n   3. Create IPPool %s in namespace %s", poolNamespace, poolName, poolNamespace)
+	case errorMessage != "":
+		issue.Severity = analyzer.SeverityCritical
+		issue.Cause = a.enhanceCause("The ip-address-manager failed to allocate an address", issue.Condition)
+	default:
+		issue.Cause = a.enhanceCause("The claim is waiting for the ip-address-manager to allocate an address", issue.Condition)
+	}
+	// The pool is unknown when IPPools could not be listed
+	if pool != nil {
+		issue.Dependencies = []*analyzer.Component{pool}
+	}
+
+	return []*analyzer.Issue{issue}
+}
+
+// analyzeMetal3Data reports Metal3Data and Metal3DataClaims that failed to
+// render, which keeps the owning Metal3Machine from provisioning.
+func (a *Advisor) analyzeMetal3Data(comp *analyzer.Component) []*analyzer.Issue {
+	status, _ := comp.Metadata["status"].(map[string]interface{})
+	errorMessage, _, _ := unstructured.NestedString(status, "errorMessage")
+	if errorMessage == "" {
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:    "Rendered",
+		Status:  metav1.ConditionFalse,
+		Reason:  "RenderingFailed",
+		Message: errorMessage,
+	}
+
+	var deps []*analyzer.Component
+	for _, child := range a.children(comp) {
+		if child.Type == analyzer.IPClaimType || child.Type == analyzer.Metal3DataType {
+			deps = append(deps, child)
+		}
+	}
+	if spec, ok := comp.Metadata["spec"].(map[string]interface{}); ok {
+		if name, found, _ := unstructured.NestedString(spec, "template", "name"); found {
+			if template := a.findComponent(name, comp.Namespace, analyzer.Metal3DataTemplateType); template != nil {
+				deps = append(deps, template)
+			}
+		}
+	}
+
+	return []*analyzer.Issue{{
+		Component:    comp,
+		Condition:    condition,
+		Severity:     analyzer.SeverityCritical,
+		Description:  fmt.Sprintf("%s rendering failed", comp.Type),
+		Cause:        a.enhanceCause("Metadata or network data could not be rendered from the Metal3DataTemplate", condition),
+		Resolution:   fmt.Sprintf("1. Check %s: kubectl describe %s %s -n %s\n   2. Verify the Metal3DataTemplate references existing IPPools\n   3. Check the IPClaims of this data for allocation errors\n   4. Validate metaData and networkData templates (link and network names, fromHostInterface)\n   5. Review CAPM3 controller logs", comp.Type, strings.ToLower(string(comp.Type)), comp.Name, comp.Namespace),
+		Dependencies: deps,
+	}}
+}
+
+// ipClaimPool returns the namespace and name of the IPPool a claim
+// allocates from, which defaults to the namespace of the claim.
+func ipClaimPool(claim *analyzer.Component) (string, string) {
+	spec, _ := claim.Metadata["spec"].(map[string]interface{})
+	name, _, _ := unstructured.NestedString(spec, "pool", "name")
+	namespace, _, _ := unstructured.NestedString(spec, "pool", "namespace")
+	if namespace == "" {
+		namespace = claim.Namespace
+	}
+	return namespace, name
+}
+
+func ipClaimBound(claim *analyzer.Component) bool {
+	status, _ := claim.Metadata["status"].(map[string]interface{})
+	name, _, _ := unstructured.NestedString(status, "address", "name")
+	return name != ""
+}
@@ -0,0 +1,84 @@
+package advisor
+
+import (
+	"fmt"
+
+	"capi-advisor/pkg/analyzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// analyzeMachinePool compares desired and ready replicas of a MachinePool and
+// reports failures recorded in its status. Replica issues are only raised when
+// the MachinePool does not already report ReplicasReady=False itself.
+func (a *Advisor) analyzeMachinePool(comp *analyzer.Component) []*analyzer.Issue {
+	var issues []*analyzer.Issue
+
+	spec, _ := comp.Metadata["spec"].(map[string]interface{})
+	status, _ := comp.Metadata["status"].(map[string]interface{})
+	if spec == nil || status == nil {
+		return nil
+	}
+
+	if failureReason, found, _ := unstructured.NestedString(status, "failureReason"); found && failureReason != "" {
+		failureMessage, _, _ := unstructured.NestedString(status, "failureMessage")
+		condition := metav1.Condition{
+			Type:    "Failed",
+			Status:  metav1.ConditionFalse,
+			Reason:  failureReason,
+			Message: failureMessage,
+		}
+		issues = append(issues, &analyzer.Issue{
+			Component:   comp,
+			Condition:   condition,
+			Severity:    analyzer.SeverityCritical,
+			Description: "MachinePool reports a terminal failure",
+			Cause:       a.enhanceCause("The MachinePool controller recorded a failure that requires manual intervention", condition),
+			Resolution:  a.enhanceResolution("1. Check MachinePool status: kubectl describe machinepool <name>\n   2. Fix the cause reported in status.failureReason and status.failureMessage\n   3. Recreate the MachinePool if the failure is not recoverable", condition, comp),
+		})
+	}
+
+	for _, condition := range comp.Conditions {
+		if condition.Type == "ReplicasReady" && condition.Status == metav1.ConditionFalse {
+			return issues
+		}
+	}
+
+	desired, found, _ := unstructured.NestedInt64(spec, "replicas")
+	if !found || desired == 0 {
+		return issues
+	}
+	ready, _, _ := unstructured.NestedInt64(status, "readyReplicas")
+	available, _, _ := unstructured.NestedInt64(status, "availableReplicas")
+	if ready >= desired {
+		return issues
+	}
+
+	severity := analyzer.SeverityWarning
+	if ready == 0 {
+		severity = analyzer.SeverityCritical
+	}
+
+	condition := metav1.Condition{
+		Type:    "ReplicasReady",
+		Status:  metav1.ConditionFalse,
+		Reason:  "ReplicasNotReady",
+		Message: fmt.Sprintf("%d of %d replicas ready (%d available)", ready, desired, available),
+	}
+	if phase, found, _ := unstructured.NestedString(status, "phase"); found && phase != "" {
+		condition.Message = fmt.Sprintf("%s, phase %s", condition.Message, phase)
+	}
+
+	knowledge := a.knowledgeBase["MachinePool.ReplicasReady.False"]
+	issues = append(issues, &analyzer.Issue{
+		Component:    comp,
+		Condition:    condition,
+		Severity:     severity,
+		Description:  fmt.Sprintf("MachinePool has %d of %d replicas ready", ready, desired),
+		Cause:        a.enhanceCause(knowledge.Cause, condition),
+		Resolution:   a.enhanceResolution(knowledge.Resolution, condition, comp),
+		Dependencies: a.findDependencies(comp, knowledge.Dependencies),
+	})
+
+	return issues
+}
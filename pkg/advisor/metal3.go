@@ -0,0 +1,46 @@
+package advisor
+
+import (
+	"fmt"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/inventory"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// analyzeMetal3Machine explains why a Metal3Machine without a BareMetalHost
+// cannot find one by matching its hostSelector and the root device hints of
+// all available hosts. Nothing is reported when the hosts could not be
+// listed, since the pool of available hosts is unknown.
+func (a *Advisor) analyzeMetal3Machine(machine *analyzer.Component) []*analyzer.Issue {
+	if !a.unbound[machine] || a.kindOutcome(string(analyzer.BareMetalHostType)) != analyzer.DiscoveryOK {
+		return nil
+	}
+
+	fit := inventory.CheckFit(machine, a.hosts)
+	if len(fit.Matching) > 0 && len(fit.Errors) == 0 {
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:    "AssociationReady",
+		Status:  metav1.ConditionFalse,
+		Reason:  "NoMatchingHost",
+		Message: fit.Summary(),
+	}
+	cause := "No available BareMetalHost satisfies the hostSelector of the Metal3Machine"
+	if len(fit.Errors) > 0 {
+		cause = fmt.Sprintf("The hostSelector of the Metal3Machine is invalid: %s", strings.Join(fit.Errors, "; "))
+	}
+
+	return []*analyzer.Issue{{
+		Component:   machine,
+		Condition:   condition,
+		Severity:    analyzer.SeverityCritical,
+		Description: "Metal3Machine has no matching BareMetalHost",
+		Cause:       a.enhanceCause(cause, condition),
+		Resolution: fmt.Sprintf("1. Compare the hostSelector with host labels: kubectl get bmh -n %s --show-labels\n   2. Show the inventory and fit of all hosts: capi-advisor hosts -n %s\n   3. Register or free up hosts, or relax the hostSelector in the Metal3MachineTemplate\n   4. Check root device hints of hosts against their inspected disks",
+			machine.Namespace, machine.Namespace),
+	}}
+}
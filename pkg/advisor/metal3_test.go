@@ -0,0 +1,46 @@
+package advisor
+
+import (
+	"testing"
+
+	"capi-advisor/pkg/analyzer"
+)
+
+func TestAnalyzeMetal3MachineHostDiscovery(t *testing.T) {
+	tests := []struct {
+		name      string
+		outcomes  []analyzer.GVKDiscovery
+		wantIssue bool
+	}{
+		{name: "hosts listed", outcomes: []analyzer.GVKDiscovery{{Type: analyzer.BareMetalHostType, Outcome: analyzer.DiscoveryOK}}, wantIssue: true},
+		{name: "no outcomes", wantIssue: true},
+		{name: "hosts forbidden", outcomes: []analyzer.GVKDiscovery{{Type: analyzer.BareMetalHostType, Outcome: analyzer.DiscoveryForbidden}}},
+		{name: "hosts timed out", outcomes: []analyzer.GVKDiscovery{{Type: analyzer.BareMetalHostType, Outcome: analyzer.DiscoveryTimeout}}},
+		{name: "hosts not installed", outcomes: []analyzer.GVKDiscovery{{Type: analyzer.BareMetalHostType, Outcome: analyzer.DiscoveryNotInstalled}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			machine := &analyzer.Component{
+				Name:      "worker-0",
+				Namespace: "metal3",
+				Type:      analyzer.Metal3MachineType,
+				Status:    analyzer.StatusPending,
+				Metadata:  map[string]interface{}{"spec": map[string]interface{}{}},
+			}
+			adv := NewAdvisor()
+			adv.SetDiscovery(tt.outcomes)
+			result := adv.AnalyzeComponents([]*analyzer.Component{machine})
+
+			found := false
+			for _, issue := range result.Issues {
+				if issue.Condition.Reason == "NoMatchingHost" {
+					found = true
+				}
+			}
+			if found != tt.wantIssue {
+				t.Errorf("NoMatchingHost reported = %t, want %t", found, tt.wantIssue)
+			}
+		})
+	}
+}
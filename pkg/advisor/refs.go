@@ -0,0 +1,58 @@
+package advisor
+
+import (
+	"fmt"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// analyzeReferencedObject reports referenced objects of unknown kinds that
+// could not be fetched, so a broken hop in the chain is not silently lost.
+func (a *Advisor) analyzeReferencedObject(comp *analyzer.Component) []*analyzer.Issue {
+	lookupErr, failed := analyzer.ReferenceLookupError(comp)
+	if !failed {
+		return nil
+	}
+
+	referencedBy := analyzer.ReferencedBy(comp)
+	condition := metav1.Condition{
+		Type:    "ReferenceResolved",
+		Status:  metav1.ConditionFalse,
+		Reason:  lookupErr.Reason,
+		Message: lookupErr.Message,
+	}
+	issue := &analyzer.Issue{
+		Component: comp,
+		Condition: condition,
+	}
+
+	switch lookupErr.Reason {
+	case analyzer.LookupNotFound:
+		issue.Severity = analyzer.SeverityCritical
+		issue.Description = fmt.Sprintf("Referenced %s does not exist", comp.Type)
+		issue.Cause = a.enhanceCause(fmt.Sprintf("%s references %s/%s, which was not found", referencedBy, comp.Type, comp.Name), condition)
+		issue.Resolution = fmt.Sprintf("1. Check the object exists: kubectl get %s %s -n %s\n   2. Create it or fix the reference in %s\n   3. The controller of the referencing object waits until the reference resolves",
+			strings.ToLower(string(comp.Type)), comp.Name, comp.Namespace, referencedBy)
+	case analyzer.LookupNotInstalled:
+		issue.Severity = analyzer.SeverityCritical
+		issue.Description = fmt.Sprintf("CRD for referenced %s is not installed", comp.Type)
+		issue.Cause = a.enhanceCause(fmt.Sprintf("%s references kind %s in group %s, which the API server does not serve", referencedBy, comp.Type, comp.GVK.Group), condition)
+		issue.Resolution = fmt.Sprintf("1. List installed providers: clusterctl describe provider\n   2. Install the provider serving %s, e.g. clusterctl init --infrastructure <provider>\n   3. Check the apiVersion of the reference matches a served version: kubectl api-resources --api-group=%s",
+			comp.Type, comp.GVK.Group)
+	case analyzer.LookupForbidden:
+		issue.Severity = analyzer.SeverityInfo
+		issue.Description = fmt.Sprintf("Referenced %s cannot be read", comp.Type)
+		issue.Cause = a.enhanceCause(fmt.Sprintf("Access to %s/%s referenced by %s is denied", comp.Type, comp.Name, referencedBy), condition)
+		issue.Resolution = fmt.Sprintf("1. Grant get on %s in group %s to analyze this object\n   2. Check your permissions: kubectl auth can-i get %s -n %s",
+			strings.ToLower(string(comp.Type)), comp.GVK.Group, strings.ToLower(string(comp.Type)), comp.Namespace)
+	default:
+		issue.Severity = analyzer.SeverityWarning
+		issue.Description = fmt.Sprintf("Referenced %s could not be fetched", comp.Type)
+		issue.Cause = a.enhanceCause(fmt.Sprintf("Fetching %s/%s referenced by %s failed", comp.Type, comp.Name, referencedBy), condition)
+		issue.Resolution = "1. Check the error above\n   2. Verify connectivity to the API server and retry"
+	}
+
+	return []*analyzer.Issue{issue}
+}
@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BMCCredentialsKey is the Metadata key holding the BMCCredentials of a
+// BareMetalHost.
+const BMCCredentialsKey = "bmcCredentials"
+
+// BMCCredentials describes the Secret referenced by spec.bmc.credentialsName
+// of a BareMetalHost. Only the presence of keys is recorded, never their
+// values.
+type BMCCredentials struct {
+	SecretName string `json:"secretName"`
+	Found      bool   `json:"found"`
+	// Keys lists the data keys of the Secret with a non-empty value
+	Keys []string `json:"keys,omitempty"`
+	// EmptyKeys lists the data keys of the Secret with an empty value
+	EmptyKeys []string `json:"emptyKeys,omitempty"`
+	// Error is set when the Secret could not be read, e.g. due to RBAC
+	Error string `json:"error,omitempty"`
+}
+
+// HasKey reports whether the Secret contains a non-empty value for key.
+func (c BMCCredentials) HasKey(key string) bool {
+	for _, k := range c.Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// HostBMCCredentials returns the resolved BMC credentials of a BareMetalHost.
+// The second return value is false if the host references no Secret.
+func HostBMCCredentials(bmh *Component) (BMCCredentials, bool) {
+	credentials, ok := bmh.Metadata[BMCCredentialsKey].(BMCCredentials)
+	return credentials, ok
+}
+
+// resolveBMCCredentials looks up the credentials Secret of every
+// BareMetalHost and stores which keys it contains. Each Secret is read once,
+// with the same bounded concurrency as listing.
+func (d *ComponentDiscovery) resolveBMCCredentials(ctx context.Context, components []*Component) {
+	// Index of the Secret of each host in secrets
+	hostSecrets := make(map[*Component]int)
+	secretIndex := make(map[string]int)
+	var secrets []string
+	for _, comp := range components {
+		if comp.Type != BareMetalHostType {
+			continue
+		}
+		spec, _ := comp.Metadata["spec"].(map[string]interface{})
+		secretName, _, _ := unstructured.NestedString(spec, "bmc", "credentialsName")
+		if secretName == "" {
+			continue
+		}
+
+		key := comp.Namespace + "/" + secretName
+		i, found := secretIndex[key]
+		if !found {
+			i = len(secrets)
+			secretIndex[key] = i
+			secrets = append(secrets, key)
+		}
+		hostSecrets[comp] = i
+	}
+
+	credentials := make([]BMCCredentials, len(secrets))
+	d.runConcurrently(len(secrets), func(i int) {
+		namespace, name, _ := strings.Cut(secrets[i], "/")
+		credentials[i] = d.getBMCCredentials(ctx, namespace, name)
+	})
+	for comp, i := range hostSecrets {
+		comp.Metadata[BMCCredentialsKey] = credentials[i]
+	}
+}
+
+func (d *ComponentDiscovery) getBMCCredentials(ctx context.Context, namespace, name string) BMCCredentials {
+	credentials := BMCCredentials{SecretName: name}
+
+	secret := &unstructured.Unstructured{}
+	secret.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "Secret"})
+	reqCtx, cancel := d.requestContext(ctx)
+	defer cancel()
+	if err := d.client.Get(reqCtx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			credentials.Error = err.Error()
+		}
+		return credentials
+	}
+	credentials.Found = true
+
+	// stringData is only present on objects that were never persisted, but
+	// is honoured for completeness
+	for _, field := range []string{"data", "stringData"} {
+		data, _, _ := unstructured.NestedMap(secret.Object, field)
+		for key, value := range data {
+			if s, _ := value.(string); s != "" {
+				credentials.Keys = append(credentials.Keys, key)
+			} else {
+				credentials.EmptyKeys = append(credentials.EmptyKeys, key)
+			}
+		}
+	}
+	sort.Strings(credentials.Keys)
+	sort.Strings(credentials.EmptyKeys)
+
+	return credentials
+}
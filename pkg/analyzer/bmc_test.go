@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestResolveBMCCredentials(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "bmc", "namespace": "metal3"},
+		"data":       map[string]interface{}{"username": "YWRtaW4=", "password": ""},
+	}}
+	var gets atomic.Int32
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(secret).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				gets.Add(1)
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).Build()
+
+	host := func(name, credentialsName string) *Component {
+		spec := map[string]interface{}{}
+		if credentialsName != "" {
+			spec["bmc"] = map[string]interface{}{"credentialsName": credentialsName}
+		}
+		return &Component{Name: name, Namespace: "metal3", Type: BareMetalHostType, Metadata: map[string]interface{}{"spec": spec}}
+	}
+	hosts := []*Component{host("host-0", "bmc"), host("host-1", "bmc"), host("host-2", "missing"), host("host-3", "")}
+
+	discovery := NewComponentDiscovery(c)
+	discovery.Concurrency = 2
+	discovery.resolveBMCCredentials(context.Background(), hosts)
+
+	tests := []struct {
+		host      *Component
+		want      BMCCredentials
+		wantFound bool
+	}{
+		{host: hosts[0], want: BMCCredentials{SecretName: "bmc", Found: true, Keys: []string{"username"}, EmptyKeys: []string{"password"}}, wantFound: true},
+		{host: hosts[1], want: BMCCredentials{SecretName: "bmc", Found: true, Keys: []string{"username"}, EmptyKeys: []string{"password"}}, wantFound: true},
+		{host: hosts[2], want: BMCCredentials{SecretName: "missing"}, wantFound: true},
+		{host: hosts[3]},
+	}
+	for _, tt := range tests {
+		got, found := HostBMCCredentials(tt.host)
+		if found != tt.wantFound || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("HostBMCCredentials(%s) = %+v, %t, want %+v, %t", tt.host.Name, got, found, tt.want, tt.wantFound)
+		}
+	}
+	// The Secret shared by two hosts is read once
+	if got := gets.Load(); got != 2 {
+		t.Errorf("got %d Secret reads, want 2", got)
+	}
+}
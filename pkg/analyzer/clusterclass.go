@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TemplateRef is a template referenced by a ClusterClass.
+type TemplateRef struct {
+	// Role describes where the template is used, e.g. "controlPlane" or
+	// "workers.machineDeployments[md-0].bootstrap".
+	Role       string `json:"role"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+}
+
+// ClusterClassRef returns the name and namespace of the ClusterClass a Cluster
+// with a managed topology is created from. The name is empty for Clusters
+// without spec.topology.
+func ClusterClassRef(cluster *Component) (string, string) {
+	if cluster.Type != ClusterType {
+		return "", ""
+	}
+	spec, ok := cluster.Metadata["spec"].(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+
+	name, _, _ := unstructured.NestedString(spec, "topology", "class")
+	namespace, _, _ := unstructured.NestedString(spec, "topology", "classNamespace")
+	if name == "" {
+		// v1beta2 moved the reference to spec.topology.classRef
+		name, _, _ = unstructured.NestedString(spec, "topology", "classRef", "name")
+		namespace, _, _ = unstructured.NestedString(spec, "topology", "classRef", "namespace")
+	}
+	if namespace == "" {
+		namespace = cluster.Namespace
+	}
+	return name, namespace
+}
+
+// ClusterClassTemplateRefs lists all templates referenced by a ClusterClass
+// in a stable order: infrastructure, control plane, then worker classes.
+func ClusterClassTemplateRefs(class *Component) []TemplateRef {
+	spec, ok := class.Metadata["spec"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var refs []TemplateRef
+	add := func(role string, obj map[string]interface{}, fields ...string) {
+		ref, found := nestedTemplateRef(obj, fields...)
+		if !found {
+			return
+		}
+		ref.Role = role
+		if ref.Namespace == "" {
+			ref.Namespace = class.Namespace
+		}
+		refs = append(refs, ref)
+	}
+
+	add("infrastructure", spec, "infrastructure")
+	add("controlPlane", spec, "controlPlane")
+	add("controlPlane.machineInfrastructure", spec, "controlPlane", "machineInfrastructure")
+
+	for _, workerKind := range []string{"machineDeployments", "machinePools"} {
+		workers, _, _ := unstructured.NestedSlice(spec, "workers", workerKind)
+		for _, worker := range workers {
+			workerMap, ok := worker.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			workerClass, _ := workerMap["class"].(string)
+			prefix := fmt.Sprintf("workers.%s[%s]", workerKind, workerClass)
+			add(prefix+".bootstrap", workerMap, "template", "bootstrap")
+			add(prefix+".infrastructure", workerMap, "template", "infrastructure")
+			// v1beta2 flattened the worker templates
+			add(prefix+".bootstrap", workerMap, "bootstrap")
+			add(prefix+".infrastructure", workerMap, "infrastructure")
+		}
+	}
+
+	return refs
+}
+
+// nestedTemplateRef reads a template reference below fields, accepting both
+// the v1beta1 "ref" and the v1beta2 "templateRef" field names.
+func nestedTemplateRef(obj map[string]interface{}, fields ...string) (TemplateRef, bool) {
+	for _, refField := range []string{"ref", "templateRef"} {
+		path := append(append([]string{}, fields...), refField)
+		refMap, found, _ := unstructured.NestedMap(obj, path...)
+		if !found {
+			continue
+		}
+		ref := TemplateRef{}
+		ref.APIVersion, _ = refMap["apiVersion"].(string)
+		ref.Kind, _ = refMap["kind"].(string)
+		ref.Name, _ = refMap["name"].(string)
+		ref.Namespace, _ = refMap["namespace"].(string)
+		if ref.Kind != "" && ref.Name != "" {
+			return ref, true
+		}
+	}
+	return TemplateRef{}, false
+}
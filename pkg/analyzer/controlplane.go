@@ -0,0 +1,183 @@
+package analyzer
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Bootstrap and control plane provider kinds
+const (
+	RKE2ControlPlaneType    ComponentType = "RKE2ControlPlane"
+	RKE2ConfigType          ComponentType = "RKE2Config"
+	KThreesControlPlaneType ComponentType = "KThreesControlPlane"
+	KThreesConfigType       ComponentType = "KThreesConfig"
+	TalosControlPlaneType   ComponentType = "TalosControlPlane"
+	TalosConfigType         ComponentType = "TalosConfig"
+)
+
+const (
+	controlPlaneGroup = "controlplane.cluster.x-k8s.io"
+	bootstrapGroup    = "bootstrap.cluster.x-k8s.io"
+)
+
+func init() {
+	RegisterProvider(rke2Provider())
+	RegisterProvider(k3sProvider())
+	RegisterProvider(talosProvider())
+}
+
+// controlPlaneStatus interprets the status fields every control plane
+// provider sets by the Cluster API contract.
+func controlPlaneStatus(comp *Component) (ComponentStatus, bool) {
+	status, ok := comp.Metadata["status"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+
+	ready, _, _ := unstructured.NestedBool(status, "ready")
+	if !ready {
+		return StatusPending, true
+	}
+
+	spec, _ := comp.Metadata["spec"].(map[string]interface{})
+	replicas, found, _ := unstructured.NestedInt64(spec, "replicas")
+	readyReplicas, _, _ := unstructured.NestedInt64(status, "readyReplicas")
+	if found && readyReplicas < replicas {
+		return StatusDegraded, true
+	}
+	return StatusHealthy, true
+}
+
+// bootstrapConfigStatus interprets the status fields every bootstrap
+// provider sets by the Cluster API contract.
+func bootstrapConfigStatus(comp *Component) (ComponentStatus, bool) {
+	status, ok := comp.Metadata["status"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	if secret, _, _ := unstructured.NestedString(status, "dataSecretName"); secret != "" {
+		return StatusHealthy, true
+	}
+	return StatusPending, true
+}
+
+// bootstrapProvider builds a provider for a control plane kind that owns its
+// Machines and the matching bootstrap config kind.
+func bootstrapProvider(name, version string, controlPlane, config ComponentType, rules map[string]Rule) Provider {
+	return &builtinProvider{
+		name: name,
+		gvks: map[ComponentType]schema.GroupVersionKind{
+			controlPlane: {Group: controlPlaneGroup, Version: version, Kind: string(controlPlane)},
+			config:       {Group: bootstrapGroup, Version: version, Kind: string(config)},
+		},
+		owned: map[ComponentType][]ComponentType{
+			controlPlane: {MachineType},
+		},
+		status: func(comp *Component) (ComponentStatus, bool) {
+			if comp.Type == controlPlane {
+				return controlPlaneStatus(comp)
+			}
+			return bootstrapConfigStatus(comp)
+		},
+		rules: rules,
+	}
+}
+
+func rke2Provider() Provider {
+	return bootstrapProvider("rke2", "v1beta1", RKE2ControlPlaneType, RKE2ConfigType, map[string]Rule{
+		"RKE2ControlPlane.Available.False": {
+			Condition:    "RKE2ControlPlane Available is False",
+			Severity:     SeverityCritical,
+			Cause:        "The RKE2 control plane is not serving the Kubernetes API",
+			Resolution:   "1. Check RKE2ControlPlane: kubectl describe rke2controlplane <name>\n   2. List control plane machines: kubectl get machines -l cluster.x-k8s.io/control-plane\n   3. On the first server node check: journalctl -u rke2-server\n   4. Verify the registration address in spec.registrationMethod is reachable\n   5. Review the RKE2 control plane provider logs",
+			Dependencies: []string{"Machine"},
+		},
+		"RKE2ControlPlane.MachinesReady.False": {
+			Condition:    "RKE2ControlPlane MachinesReady is False",
+			Severity:     SeverityWarning,
+			Cause:        "Not all control plane machines are ready",
+			Resolution:   "1. Check the control plane machines: kubectl get machines -l cluster.x-k8s.io/control-plane\n   2. Inspect the failing machine and its infrastructure\n   3. On the node check: journalctl -u rke2-server",
+			Dependencies: []string{"Machine"},
+		},
+		"RKE2ControlPlane.EtcdClusterHealthy.False": {
+			Condition:    "RKE2ControlPlane EtcdClusterHealthy is False",
+			Severity:     SeverityCritical,
+			Cause:        "The embedded etcd cluster of RKE2 is unhealthy",
+			Resolution:   "1. Check etcd members on a server node: crictl exec <etcd-container> etcdctl member list\n   2. Remove stale members left by deleted machines\n   3. Verify ports 2379-2380 are open between server nodes",
+			Dependencies: []string{"Machine"},
+		},
+		"RKE2Config.DataSecretAvailable.False": {
+			Condition:    "RKE2Config DataSecretAvailable is False",
+			Severity:     SeverityWarning,
+			Cause:        "The RKE2 bootstrap data has not been generated",
+			Resolution:   "1. Check RKE2Config: kubectl describe rke2config <name>\n   2. Agents wait for the control plane to be initialized before their data is generated\n   3. Verify the token Secret of the cluster exists\n   4. Review the RKE2 bootstrap provider logs",
+			Dependencies: []string{},
+		},
+	})
+}
+
+func k3sProvider() Provider {
+	return bootstrapProvider("k3s", "v1beta2", KThreesControlPlaneType, KThreesConfigType, map[string]Rule{
+		"KThreesControlPlane.Available.False": {
+			Condition:    "KThreesControlPlane Available is False",
+			Severity:     SeverityCritical,
+			Cause:        "The k3s control plane is not serving the Kubernetes API",
+			Resolution:   "1. Check KThreesControlPlane: kubectl describe kthreescontrolplane <name>\n   2. List control plane machines: kubectl get machines -l cluster.x-k8s.io/control-plane\n   3. On the first server node check: journalctl -u k3s\n   4. Review the k3s control plane provider logs",
+			Dependencies: []string{"Machine"},
+		},
+		"KThreesControlPlane.MachinesReady.False": {
+			Condition:    "KThreesControlPlane MachinesReady is False",
+			Severity:     SeverityWarning,
+			Cause:        "Not all control plane machines are ready",
+			Resolution:   "1. Check the control plane machines: kubectl get machines -l cluster.x-k8s.io/control-plane\n   2. Inspect the failing machine and its infrastructure\n   3. On the node check: journalctl -u k3s",
+			Dependencies: []string{"Machine"},
+		},
+		"KThreesControlPlane.EtcdClusterHealthy.False": {
+			Condition:    "KThreesControlPlane EtcdClusterHealthy is False",
+			Severity:     SeverityCritical,
+			Cause:        "The embedded etcd cluster of k3s is unhealthy",
+			Resolution:   "1. Check the etcd messages in the k3s server logs: journalctl -u k3s | grep etcd\n   2. Remove stale members left by deleted machines\n   3. Verify ports 2379-2380 are open between server nodes",
+			Dependencies: []string{"Machine"},
+		},
+		"KThreesConfig.DataSecretAvailable.False": {
+			Condition:    "KThreesConfig DataSecretAvailable is False",
+			Severity:     SeverityWarning,
+			Cause:        "The k3s bootstrap data has not been generated",
+			Resolution:   "1. Check KThreesConfig: kubectl describe kthreesconfig <name>\n   2. Agents wait for the control plane to be initialized before their data is generated\n   3. Verify the token Secret of the cluster exists\n   4. Review the k3s bootstrap provider logs",
+			Dependencies: []string{},
+		},
+	})
+}
+
+func talosProvider() Provider {
+	return bootstrapProvider("talos", "v1alpha3", TalosControlPlaneType, TalosConfigType, map[string]Rule{
+		"TalosControlPlane.Available.False": {
+			Condition:    "TalosControlPlane Available is False",
+			Severity:     SeverityCritical,
+			Cause:        "The Talos control plane is not serving the Kubernetes API",
+			Resolution:   "1. Check TalosControlPlane: kubectl describe taloscontrolplane <name>\n   2. Get the talosconfig: kubectl get secret <cluster>-talosconfig -o jsonpath='{.data.talosconfig}' | base64 -d\n   3. Check the services of the first node: talosctl -n <ip> services\n   4. Review the Talos control plane provider logs",
+			Dependencies: []string{"Machine"},
+		},
+		"TalosControlPlane.MachinesBootstrapped.False": {
+			Condition:    "TalosControlPlane MachinesBootstrapped is False",
+			Severity:     SeverityCritical,
+			Cause:        "etcd was not bootstrapped on the first control plane node",
+			Resolution:   "1. Check the first control plane node: talosctl -n <ip> service etcd\n   2. Verify the node is reachable on the Talos API port 50000\n   3. Review the Talos control plane provider logs for bootstrap errors",
+			Dependencies: []string{"Machine"},
+		},
+		"TalosControlPlane.EtcdClusterHealthy.False": {
+			Condition:    "TalosControlPlane EtcdClusterHealthy is False",
+			Severity:     SeverityCritical,
+			Cause:        "The etcd cluster of the Talos control plane is unhealthy",
+			Resolution:   "1. List etcd members: talosctl -n <ip> etcd members\n   2. Remove stale members: talosctl -n <ip> etcd remove-member <id>\n   3. Check etcd logs: talosctl -n <ip> logs etcd",
+			Dependencies: []string{"Machine"},
+		},
+		"TalosConfig.DataSecretAvailable.False": {
+			Condition:    "TalosConfig DataSecretAvailable is False",
+			Severity:     SeverityWarning,
+			Cause:        "The Talos machine configuration has not been generated",
+			Resolution:   "1. Check TalosConfig: kubectl describe talosconfig <name>\n   2. Validate spec.configPatches against the Talos version\n   3. Review the Talos bootstrap provider logs",
+			Dependencies: []string{},
+		},
+	})
+}
@@ -3,9 +3,10 @@ package analyzer
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
-	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -58,27 +59,194 @@ var SupportedGVKs = map[ComponentType]schema.GroupVersionKind{
 		Version: "v1beta1",
 		Kind:    "KubeadmConfig",
 	},
+	MachinePoolType: {
+		Group:   "exp.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "MachinePool",
+	},
+	DockerMachinePoolType: {
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "DockerMachinePool",
+	},
+	AWSMachinePoolType: {
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1beta2",
+		Kind:    "AWSMachinePool",
+	},
+	AzureMachinePoolType: {
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "AzureMachinePool",
+	},
+	ClusterClassType: {
+		Group:   "cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "ClusterClass",
+	},
+	KubeadmControlPlaneTemplateType: {
+		Group:   "controlplane.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "KubeadmControlPlaneTemplate",
+	},
+	KubeadmConfigTemplateType: {
+		Group:   "bootstrap.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "KubeadmConfigTemplate",
+	},
+	Metal3ClusterTemplateType: {
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "Metal3ClusterTemplate",
+	},
+	Metal3MachineTemplateType: {
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "Metal3MachineTemplate",
+	},
+	Metal3DataTemplateType: {
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "Metal3DataTemplate",
+	},
+	Metal3DataType: {
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "Metal3Data",
+	},
+	Metal3DataClaimType: {
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "Metal3DataClaim",
+	},
+	IPPoolType: {
+		Group:   "ipam.metal3.io",
+		Version: "v1alpha1",
+		Kind:    "IPPool",
+	},
+	IPClaimType: {
+		Group:   "ipam.metal3.io",
+		Version: "v1alpha1",
+		Kind:    "IPClaim",
+	},
+	IPAddressType: {
+		Group:   "ipam.metal3.io",
+		Version: "v1alpha1",
+		Kind:    "IPAddress",
+	},
+	HardwareDataType: {
+		Group:   "metal3.io",
+		Version: "v1alpha1",
+		Kind:    "HardwareData",
+	},
+	HostFirmwareSettingsType: {
+		Group:   "metal3.io",
+		Version: "v1alpha1",
+		Kind:    "HostFirmwareSettings",
+	},
+	HostFirmwareComponentsType: {
+		Group:   "metal3.io",
+		Version: "v1alpha1",
+		Kind:    "HostFirmwareComponents",
+	},
+	FirmwareSchemaType: {
+		Group:   "metal3.io",
+		Version: "v1alpha1",
+		Kind:    "FirmwareSchema",
+	},
 }
 
+// AlternativeGVKs lists additional API groups a component type may be served
+// from. MachinePool graduated from the experimental API group, so depending on
+// the Cluster API release it is found under either group.
+var AlternativeGVKs = map[ComponentType][]schema.GroupVersionKind{
+	MachinePoolType: {
+		{
+			Group:   "cluster.x-k8s.io",
+			Version: "v1beta2",
+			Kind:    "MachinePool",
+		},
+	},
+}
+
+// Discovery defaults, tuned for management clusters with thousands of
+// Machines.
+const (
+	DefaultConcurrency    = 8
+	DefaultPageSize       = 500
+	DefaultRequestTimeout = 30 * time.Second
+)
+
 type ComponentDiscovery struct {
 	client client.Client
+
+	// Concurrency bounds the number of kinds listed at the same time
+	Concurrency int
+	// PageSize is the number of objects requested per list call, 0 lists
+	// without pagination
+	PageSize int64
+	// RequestTimeout bounds every single API request, 0 only applies the
+	// deadline of the context passed to DiscoverComponents
+	RequestTimeout time.Duration
 }
 
 func NewComponentDiscovery(c client.Client) *ComponentDiscovery {
-	return &ComponentDiscovery{client: c}
+	return &ComponentDiscovery{
+		client:         c,
+		Concurrency:    DefaultConcurrency,
+		PageSize:       DefaultPageSize,
+		RequestTimeout: DefaultRequestTimeout,
+	}
+}
+
+// discoveryJob lists one kind from one API group.
+type discoveryJob struct {
+	compType    ComponentType
+	gvk         schema.GroupVersionKind
+	alternative bool
+
+	components []*Component
+	outcome    GVKDiscovery
 }
 
-func (d *ComponentDiscovery) DiscoverComponents(ctx context.Context, namespace string, clusterName string) ([]*Component, error) {
+// DiscoverComponents lists all supported kinds and returns the components
+// found together with the outcome of listing each kind, ordered by kind.
+// Kinds that cannot be listed do not fail discovery, their outcome tells
+// why they are missing.
+func (d *ComponentDiscovery) DiscoverComponents(ctx context.Context, namespace string, clusterName string) ([]*Component, []GVKDiscovery, error) {
+	jobs := discoveryJobs()
+	d.runJobs(ctx, namespace, jobs)
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("discovery interrupted: %v", err)
+	}
+
+	outcomes := make([]GVKDiscovery, 0, len(jobs))
 	var allComponents []*Component
+	for _, job := range jobs {
+		outcomes = append(outcomes, job.outcome)
+		if !job.alternative {
+			allComponents = append(allComponents, job.components...)
+		}
+	}
 
-	for componentType, gvk := range SupportedGVKs {
-		components, err := d.discoverComponentType(ctx, namespace, componentType, gvk)
-		if err != nil {
-			// Log the error but continue with other component types
-			fmt.Printf("Warning: failed to discover %s components: %v\n", componentType, err)
+	// Add objects served from alternative API groups, skipping objects
+	// already found
+	for _, job := range jobs {
+		if !job.alternative {
 			continue
 		}
-		allComponents = append(allComponents, components...)
+		seen := make(map[string]bool)
+		for _, comp := range allComponents {
+			if comp.Type == job.compType {
+				seen[comp.Namespace+"/"+comp.Name] = true
+			}
+		}
+		for _, comp := range job.components {
+			if !seen[comp.Namespace+"/"+comp.Name] {
+				seen[comp.Namespace+"/"+comp.Name] = true
+				allComponents = append(allComponents, comp)
+			}
+		}
 	}
 
 	// Filter by cluster name if specified
@@ -86,38 +254,142 @@ func (d *ComponentDiscovery) DiscoverComponents(ctx context.Context, namespace s
 		allComponents = d.filterByCluster(allComponents, clusterName)
 	}
 
-	return allComponents, nil
+	// Check BMC credential Secrets without exposing their contents
+	d.resolveBMCCredentials(ctx, allComponents)
+
+	// Fetch referenced objects of kinds that are not listed
+	allComponents = d.followReferences(ctx, allComponents)
+
+	return allComponents, outcomes, nil
+}
+
+// discoveryJobs returns the kinds to list, ordered by kind with the
+// alternative API groups last so the primary group wins for duplicates.
+func discoveryJobs() []*discoveryJob {
+	var jobs []*discoveryJob
+	for compType, gvk := range SupportedGVKs {
+		jobs = append(jobs, &discoveryJob{compType: compType, gvk: gvk})
+	}
+	for compType, gvks := range AlternativeGVKs {
+		for _, gvk := range gvks {
+			jobs = append(jobs, &discoveryJob{compType: compType, gvk: gvk, alternative: true})
+		}
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].alternative != jobs[j].alternative {
+			return !jobs[i].alternative
+		}
+		if jobs[i].compType != jobs[j].compType {
+			return jobs[i].compType < jobs[j].compType
+		}
+		return jobs[i].gvk.String() < jobs[j].gvk.String()
+	})
+	return jobs
 }
 
-func (d *ComponentDiscovery) discoverComponentType(ctx context.Context, namespace string, compType ComponentType, gvk schema.GroupVersionKind) ([]*Component, error) {
-	list := &unstructured.UnstructuredList{}
-	list.SetGroupVersionKind(schema.GroupVersionKind{
-		Group:   gvk.Group,
-		Version: gvk.Version,
-		Kind:    gvk.Kind + "List",
+// runJobs lists the kinds of all jobs with a bounded pool of workers.
+func (d *ComponentDiscovery) runJobs(ctx context.Context, namespace string, jobs []*discoveryJob) {
+	d.runConcurrently(len(jobs), func(i int) {
+		job := jobs[i]
+		start := time.Now()
+		components, pages, err := d.discoverComponentType(ctx, namespace, job.compType, job.gvk)
+		job.components = components
+		job.outcome = GVKDiscovery{
+			Type:       job.compType,
+			GVK:        job.gvk,
+			Outcome:    listOutcome(err),
+			Count:      len(components),
+			Pages:      pages,
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if job.outcome.Outcome.Incomplete() {
+			job.outcome.Error = err.Error()
+		}
 	})
+}
 
-	var opts []client.ListOption
-	if namespace != "" {
-		opts = append(opts, client.InNamespace(namespace))
+// runConcurrently calls work for 0 to n-1 with at most Concurrency calls
+// running at a time.
+func (d *ComponentDiscovery) runConcurrently(n int, work func(i int)) {
+	workers := d.Concurrency
+	if workers < 1 {
+		workers = 1
 	}
 
-	err := d.client.List(ctx, list, opts...)
-	if err != nil {
-		// Check if this is a "not found" error for CRDs that might not be installed
-		if meta.IsNoMatchError(err) {
-			return nil, nil // Return empty list, not an error
-		}
-		return nil, fmt.Errorf("failed to list %s: %v", compType, err)
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				work(i)
+			}
+		}()
 	}
 
-	var components []*Component
-	for _, item := range list.Items {
-		component := d.convertUnstructuredToComponent(&item, compType, gvk)
-		components = append(components, component)
+	for i := 0; i < n; i++ {
+		queue <- i
 	}
+	close(queue)
+	wg.Wait()
+}
 
-	return components, nil
+// requestContext bounds a single API request by the request timeout.
+func (d *ComponentDiscovery) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d.RequestTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d.RequestTimeout)
+}
+
+// discoverComponentType lists all objects of a kind page by page and
+// returns them with the number of pages requested. No objects are returned
+// when any page fails.
+func (d *ComponentDiscovery) discoverComponentType(ctx context.Context, namespace string, compType ComponentType, gvk schema.GroupVersionKind) ([]*Component, int, error) {
+	var components []*Component
+	pages := 0
+	continueToken := ""
+
+	for {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   gvk.Group,
+			Version: gvk.Version,
+			Kind:    gvk.Kind + "List",
+		})
+
+		var opts []client.ListOption
+		if namespace != "" {
+			opts = append(opts, client.InNamespace(namespace))
+		}
+		if d.PageSize > 0 {
+			opts = append(opts, client.Limit(d.PageSize))
+		}
+		if continueToken != "" {
+			opts = append(opts, client.Continue(continueToken))
+		}
+
+		reqCtx, cancel := d.requestContext(ctx)
+		err := d.client.List(reqCtx, list, opts...)
+		cancel()
+		pages++
+		if err != nil {
+			// Drop the pages listed so far, a partial list would be analyzed
+			// as if it held every object of the kind
+			return nil, pages, err
+		}
+
+		for i := range list.Items {
+			components = append(components, d.convertUnstructuredToComponent(&list.Items[i], compType, gvk))
+		}
+
+		continueToken = list.GetContinue()
+		if continueToken == "" {
+			return components, pages, nil
+		}
+	}
 }
 
 func (d *ComponentDiscovery) convertUnstructuredToComponent(obj *unstructured.Unstructured, compType ComponentType, gvk schema.GroupVersionKind) *Component {
@@ -129,8 +401,20 @@ func (d *ComponentDiscovery) convertUnstructuredToComponent(obj *unstructured.Un
 		Metadata:  make(map[string]interface{}),
 	}
 
-	// Extract labels
+	// Extract labels and annotations
 	component.Metadata["labels"] = obj.GetLabels()
+	component.Metadata["annotations"] = obj.GetAnnotations()
+
+	// Extract owner references and the UID for ownership relationships, and
+	// the creation time for the age of the object
+	objectMeta := map[string]interface{}{
+		"uid":               string(obj.GetUID()),
+		"creationTimestamp": obj.GetCreationTimestamp().Time,
+	}
+	if ownerRefs, found, err := unstructured.NestedSlice(obj.Object, "metadata", "ownerReferences"); found && err == nil {
+		objectMeta["ownerReferences"] = ownerRefs
+	}
+	component.Metadata["metadata"] = objectMeta
 
 	// Extract conditions from status
 	if status, found, err := unstructured.NestedMap(obj.Object, "status"); found && err == nil {
@@ -149,6 +433,9 @@ func (d *ComponentDiscovery) convertUnstructuredToComponent(obj *unstructured.Un
 
 	// Determine component status based on conditions
 	component.Status = d.determineComponentStatus(component.Conditions)
+	if len(component.Conditions) == 0 {
+		component.Status = d.determineStatusFromFields(component)
+	}
 
 	return component
 }
@@ -223,41 +510,111 @@ func (d *ComponentDiscovery) determineComponentStatus(conditions []metav1.Condit
 	return StatusHealthy
 }
 
-func (d *ComponentDiscovery) filterByCluster(components []*Component, clusterName string) []*Component {
-	var filtered []*Component
-	clusterMap := make(map[string]bool)
+// determineStatusFromFields derives a status for kinds that report progress
+// through status fields instead of conditions, such as Metal3Data, the
+// Metal3 IPAM kinds and provider kinds with their own status fields.
+func (d *ComponentDiscovery) determineStatusFromFields(comp *Component) ComponentStatus {
+	status, _ := comp.Metadata["status"].(map[string]interface{})
 
-	// First pass: find all Cluster resources with matching name
-	for _, comp := range components {
-		if comp.Type == ClusterType && comp.Name == clusterName {
-			filtered = append(filtered, comp)
-			clusterMap[comp.Namespace+"/"+comp.Name] = true
+	for _, field := range []string{"errorMessage", "failureMessage"} {
+		if msg, found, _ := unstructured.NestedString(status, field); found && msg != "" {
+			return StatusFailed
 		}
 	}
 
-	// Second pass: filter components that belong to the specified cluster
-	for _, comp := range components {
-		if comp.Type == ClusterType {
-			continue // Already added
+	switch comp.Type {
+	case IPClaimType:
+		if name, found, _ := unstructured.NestedString(status, "address", "name"); found && name != "" {
+			return StatusHealthy
 		}
-
-		// Check if component has cluster owner reference in labels
-		if spec, ok := comp.Metadata["spec"].(map[string]interface{}); ok {
-			// Check for clusterName in spec
-			if cn, ok := spec["clusterName"].(string); ok && cn == clusterName {
-				filtered = append(filtered, comp)
-				continue
+		return StatusPending
+	case BareMetalHostType:
+		switch operationalStatus, _, _ := unstructured.NestedString(status, "operationalStatus"); operationalStatus {
+		case "OK":
+			return StatusHealthy
+		case "error":
+			return StatusFailed
+		case "":
+			return StatusUnknown
+		default:
+			return StatusDegraded
+		}
+	case Metal3DataClaimType:
+		if name, found, _ := unstructured.NestedString(status, "renderedData", "name"); found && name != "" {
+			return StatusHealthy
+		}
+		return StatusPending
+	case IPPoolType:
+		if util, err := IPPoolUtilization(comp); err == nil && util.Capacity > 0 {
+			if util.Allocated >= util.Capacity {
+				return StatusDegraded
 			}
+			return StatusHealthy
 		}
+	}
 
-		// Check labels for cluster association
-		if labels, ok := comp.Metadata["labels"].(map[string]string); ok {
-			if cn, ok := labels["cluster.x-k8s.io/cluster-name"]; ok && cn == clusterName {
-				filtered = append(filtered, comp)
-				continue
+	// Let the provider of the kind interpret its status fields
+	if provider := ProviderFor(comp.Type); provider != nil {
+		if providerStatus, ok := provider.Status(comp); ok {
+			return providerStatus
+		}
+	}
+
+	if ready, found, _ := unstructured.NestedBool(status, "ready"); found {
+		if ready {
+			return StatusHealthy
+		}
+		return StatusPending
+	}
+
+	return StatusUnknown
+}
+
+// filterByCluster keeps the components of the Clusters with a name in any
+// namespace, found through their spec.clusterName or cluster-name label, the
+// ClusterClass of a managed topology with the templates it references, and
+// the BareMetalHosts in the namespaces of the Clusters that are free or
+// consumed by one of the kept components.
+func (d *ComponentDiscovery) filterByCluster(components []*Component, clusterName string) []*Component {
+	index := NewClusterIndex(components)
+
+	keep := make(map[string]bool)
+	classes := make(map[string]bool)
+	namespaces := make(map[string]bool)
+	for _, comp := range index.Named(clusterName) {
+		keep[comp.Namespace+"/"+comp.Name+"/"+string(comp.Type)] = true
+		if comp.Type != ClusterType {
+			continue
+		}
+		namespaces[comp.Namespace] = true
+		if className, classNamespace := ClusterClassRef(comp); className != "" {
+			classes[classNamespace+"/"+className] = true
+		}
+	}
+
+	for _, comp := range components {
+		switch {
+		// Keep the ClusterClass of managed topologies and the templates it references
+		case comp.Type == ClusterClassType && classes[comp.Namespace+"/"+comp.Name]:
+			keep[comp.Namespace+"/"+comp.Name+"/"+string(comp.Type)] = true
+			for _, ref := range ClusterClassTemplateRefs(comp) {
+				keep[ref.Namespace+"/"+ref.Name+"/"+ref.Kind] = true
+			}
+		// Keep the BareMetalHosts the Metal3Machines of the Cluster are or can
+		// be bound to, which carry no cluster-name label while they are free
+		case comp.Type == BareMetalHostType && namespaces[comp.Namespace]:
+			kind, namespace, name := HostConsumer(comp)
+			if name == "" || keep[namespace+"/"+name+"/"+kind] {
+				keep[comp.Namespace+"/"+comp.Name+"/"+string(comp.Type)] = true
 			}
 		}
 	}
 
+	var filtered []*Component
+	for _, comp := range components {
+		if keep[comp.Namespace+"/"+comp.Name+"/"+string(comp.Type)] {
+			filtered = append(filtered, comp)
+		}
+	}
 	return filtered
 }
\ No newline at end of file
@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterByCluster(t *testing.T) {
+	component := func(compType ComponentType, namespace, name string, spec map[string]interface{}, labels map[string]string) *Component {
+		return &Component{
+			Name:      name,
+			Namespace: namespace,
+			Type:      compType,
+			Metadata:  map[string]interface{}{"spec": spec, "labels": labels},
+		}
+	}
+	templateRef := map[string]interface{}{"ref": map[string]interface{}{"kind": "Metal3ClusterTemplate", "name": "tpl"}}
+
+	components := []*Component{
+		component(ClusterType, "ns1", "a", map[string]interface{}{"topology": map[string]interface{}{"class": "cc"}}, nil),
+		component(ClusterType, "ns2", "a", nil, nil),
+		component(ClusterType, "ns1", "b", nil, nil),
+		component(ClusterClassType, "ns1", "cc", map[string]interface{}{"infrastructure": templateRef}, nil),
+		component(ClusterClassType, "ns1", "other", nil, nil),
+		component("Metal3ClusterTemplate", "ns1", "tpl", nil, nil),
+		component("Metal3ClusterTemplate", "ns1", "other-tpl", nil, nil),
+		component(MachineType, "ns1", "a-0", map[string]interface{}{"clusterName": "a"}, nil),
+		component(MachineType, "ns1", "b-0", map[string]interface{}{"clusterName": "b"}, nil),
+		component(Metal3MachineType, "ns1", "a-0", nil, map[string]string{ClusterNameLabel: "a"}),
+		component(Metal3MachineType, "ns2", "a-0", nil, map[string]string{ClusterNameLabel: "a"}),
+		component(Metal3MachineType, "ns1", "b-0", nil, map[string]string{ClusterNameLabel: "b"}),
+		// Indexed under both Clusters
+		component(IPPoolType, "ns1", "pool", map[string]interface{}{"clusterName": "b"}, map[string]string{ClusterNameLabel: "a"}),
+		component(BareMetalHostType, "ns1", "free", nil, nil),
+		component(BareMetalHostType, "ns3", "free", nil, nil),
+		component(BareMetalHostType, "ns1", "host-a", map[string]interface{}{"consumerRef": map[string]interface{}{"kind": "Metal3Machine", "name": "a-0"}}, nil),
+		component(BareMetalHostType, "ns1", "host-b", map[string]interface{}{"consumerRef": map[string]interface{}{"kind": "Metal3Machine", "name": "b-0"}}, nil),
+	}
+
+	var got []string
+	for _, comp := range (&ComponentDiscovery{}).filterByCluster(components, "a") {
+		got = append(got, comp.Namespace+"/"+comp.Name+"/"+string(comp.Type))
+	}
+	want := []string{
+		"ns1/a/Cluster",
+		"ns2/a/Cluster",
+		"ns1/cc/ClusterClass",
+		"ns1/tpl/Metal3ClusterTemplate",
+		"ns1/a-0/Machine",
+		"ns1/a-0/Metal3Machine",
+		"ns2/a-0/Metal3Machine",
+		"ns1/pool/IPPool",
+		"ns1/free/BareMetalHost",
+		"ns1/host-a/BareMetalHost",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filterByCluster() = %v, want %v", got, want)
+	}
+}
+
+func TestClusterIndexMembers(t *testing.T) {
+	cluster := &Component{Name: "a", Namespace: "ns1", Type: ClusterType, Metadata: map[string]interface{}{
+		"labels": map[string]string{ClusterNameLabel: "b"},
+	}}
+	machine := &Component{Name: "a-0", Namespace: "ns1", Type: MachineType, Metadata: map[string]interface{}{
+		"spec": map[string]interface{}{"clusterName": "a"},
+	}}
+	other := &Component{Name: "a-0", Namespace: "ns2", Type: MachineType, Metadata: map[string]interface{}{
+		"spec": map[string]interface{}{"clusterName": "a"},
+	}}
+	index := NewClusterIndex([]*Component{cluster, machine, other})
+
+	tests := []struct {
+		namespace, name string
+		want            []*Component
+	}{
+		{namespace: "ns1", name: "a", want: []*Component{cluster, machine}},
+		{namespace: "ns2", name: "a", want: []*Component{other}},
+		// A Cluster is not a member of the Cluster in its label
+		{namespace: "ns1", name: "b"},
+	}
+	for _, tt := range tests {
+		if got := index.Members(tt.namespace, tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Members(%s, %s) = %v, want %v", tt.namespace, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EventsKey is the Metadata key holding the events recorded for a component.
+const EventsKey = "events"
+
+// maxEvents is the number of most recent events kept per component.
+const maxEvents = 10
+
+// Event is a Kubernetes event recorded for a component.
+type Event struct {
+	Type     string    `json:"type"`
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message"`
+	Count    int64     `json:"count"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// ComponentEvents returns the events resolved for a component, newest first.
+func ComponentEvents(comp *Component) []Event {
+	events, _ := comp.Metadata[EventsKey].([]Event)
+	return events
+}
+
+// ResolveEvents looks up the events recorded for the given components, one
+// list call per namespace. Events only add context to issues, so namespaces
+// whose events cannot be listed, e.g. due to RBAC, are skipped.
+func (d *ComponentDiscovery) ResolveEvents(ctx context.Context, components []*Component) {
+	byNamespace := make(map[string][]*Component)
+	for _, comp := range components {
+		if comp.Namespace != "" {
+			byNamespace[comp.Namespace] = append(byNamespace[comp.Namespace], comp)
+		}
+	}
+
+	for namespace, comps := range byNamespace {
+		events, err := d.listEvents(ctx, namespace)
+		if err != nil {
+			continue
+		}
+		for _, comp := range comps {
+			var compEvents []Event
+			for _, event := range events {
+				kind, _, _ := unstructured.NestedString(event.Object, "involvedObject", "kind")
+				name, _, _ := unstructured.NestedString(event.Object, "involvedObject", "name")
+				if kind == string(comp.Type) && name == comp.Name {
+					compEvents = append(compEvents, newEvent(event))
+				}
+			}
+			sort.SliceStable(compEvents, func(i, j int) bool {
+				return compEvents[i].LastSeen.After(compEvents[j].LastSeen)
+			})
+			if len(compEvents) > maxEvents {
+				compEvents = compEvents[:maxEvents]
+			}
+			if len(compEvents) > 0 {
+				comp.Metadata[EventsKey] = compEvents
+			}
+		}
+	}
+}
+
+func (d *ComponentDiscovery) listEvents(ctx context.Context, namespace string) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "EventList"})
+	reqCtx, cancel := d.requestContext(ctx)
+	defer cancel()
+	if err := d.client.List(reqCtx, list, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func newEvent(obj unstructured.Unstructured) Event {
+	event := Event{Count: 1}
+	event.Type, _, _ = unstructured.NestedString(obj.Object, "type")
+	event.Reason, _, _ = unstructured.NestedString(obj.Object, "reason")
+	event.Message, _, _ = unstructured.NestedString(obj.Object, "message")
+	if count, found, _ := unstructured.NestedInt64(obj.Object, "count"); found && count > 0 {
+		event.Count = count
+	} else if count, found, _ := unstructured.NestedInt64(obj.Object, "series", "count"); found && count > 0 {
+		event.Count = count
+	}
+
+	// Events of the events.k8s.io API only set eventTime and series
+	for _, field := range [][]string{{"lastTimestamp"}, {"series", "lastObservedTime"}, {"eventTime"}} {
+		if value, found, _ := unstructured.NestedString(obj.Object, field...); found && value != "" {
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				event.LastSeen = t
+				break
+			}
+		}
+	}
+	if event.LastSeen.IsZero() {
+		event.LastSeen = obj.GetCreationTimestamp().Time
+	}
+	return event
+}
@@ -0,0 +1,288 @@
+package analyzer
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Infrastructure provider kinds
+const (
+	DockerClusterType            ComponentType = "DockerCluster"
+	DockerMachineType            ComponentType = "DockerMachine"
+	DockerMachineTemplateType    ComponentType = "DockerMachineTemplate"
+	OpenStackClusterType         ComponentType = "OpenStackCluster"
+	OpenStackMachineType         ComponentType = "OpenStackMachine"
+	OpenStackMachineTemplateType ComponentType = "OpenStackMachineTemplate"
+	OpenStackServerType          ComponentType = "OpenStackServer"
+	VSphereClusterType           ComponentType = "VSphereCluster"
+	VSphereMachineType           ComponentType = "VSphereMachine"
+	VSphereMachineTemplateType   ComponentType = "VSphereMachineTemplate"
+	VSphereVMType                ComponentType = "VSphereVM"
+	AWSClusterType               ComponentType = "AWSCluster"
+	AWSMachineType               ComponentType = "AWSMachine"
+	AWSMachineTemplateType       ComponentType = "AWSMachineTemplate"
+)
+
+const infrastructureGroup = "infrastructure.cluster.x-k8s.io"
+
+func init() {
+	RegisterProvider(dockerProvider())
+	RegisterProvider(openStackProvider())
+	RegisterProvider(vSphereProvider())
+	RegisterProvider(awsProvider())
+}
+
+// builtinProvider implements Provider for the providers shipped with the
+// advisor.
+type builtinProvider struct {
+	name   string
+	gvks   map[ComponentType]schema.GroupVersionKind
+	owned  map[ComponentType][]ComponentType
+	status func(comp *Component) (ComponentStatus, bool)
+	rules  map[string]Rule
+}
+
+func (p *builtinProvider) Name() string {
+	return p.name
+}
+
+func (p *builtinProvider) GVKs() map[ComponentType]schema.GroupVersionKind {
+	return p.gvks
+}
+
+func (p *builtinProvider) Status(comp *Component) (ComponentStatus, bool) {
+	if p.status == nil {
+		return "", false
+	}
+	return p.status(comp)
+}
+
+func (p *builtinProvider) Rules() map[string]Rule {
+	return p.rules
+}
+
+// OwnedKinds maps an owner kind to the kinds of objects it creates, for
+// objects that are linked by ownerReferences only.
+func (p *builtinProvider) OwnedKinds() map[ComponentType][]ComponentType {
+	return p.owned
+}
+
+func infrastructureGVK(version string, kind ComponentType) schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: infrastructureGroup, Version: version, Kind: string(kind)}
+}
+
+// instanceStateStatus maps the cloud instance state reported in
+// status.instanceState to a component status.
+func instanceStateStatus(comp *Component, states map[string]ComponentStatus) (ComponentStatus, bool) {
+	status, _ := comp.Metadata["status"].(map[string]interface{})
+	state, _, _ := unstructured.NestedString(status, "instanceState")
+	result, found := states[strings.ToLower(state)]
+	return result, found
+}
+
+func dockerProvider() Provider {
+	return &builtinProvider{
+		name: "docker",
+		gvks: map[ComponentType]schema.GroupVersionKind{
+			DockerClusterType:         infrastructureGVK("v1beta1", DockerClusterType),
+			DockerMachineType:         infrastructureGVK("v1beta1", DockerMachineType),
+			DockerMachineTemplateType: infrastructureGVK("v1beta1", DockerMachineTemplateType),
+		},
+		rules: map[string]Rule{
+			"DockerCluster.LoadBalancerAvailable.False": {
+				Condition:    "DockerCluster LoadBalancerAvailable is False",
+				Severity:     SeverityCritical,
+				Cause:        "The HAProxy load balancer container for the API server is not running",
+				Resolution:   "1. Check the load balancer container: docker ps -a --filter name=<cluster>-lb\n   2. Inspect its logs: docker logs <cluster>-lb\n   3. Verify the kindest/haproxy image can be pulled\n   4. Review CAPD controller logs",
+				Dependencies: []string{},
+			},
+			"DockerMachine.ContainerProvisioned.False": {
+				Condition:    "DockerMachine ContainerProvisioned is False",
+				Severity:     SeverityCritical,
+				Cause:        "The node container could not be created",
+				Resolution:   "1. Check DockerMachine: kubectl describe dockermachine <name>\n   2. Verify the node image exists: docker images kindest/node\n   3. Check Docker host resources (disk, memory, open files)\n   4. Review CAPD controller logs",
+				Dependencies: []string{},
+			},
+			"DockerMachine.BootstrapExecSucceeded.False": {
+				Condition:    "DockerMachine BootstrapExecSucceeded is False",
+				Severity:     SeverityCritical,
+				Cause:        "Running the bootstrap data in the node container failed",
+				Resolution:   "1. Check the condition message for the failing command\n   2. Inspect the container: docker exec <machine> journalctl -u kubelet\n   3. Review cloud-init output: docker exec <machine> cat /var/log/cloud-init-output.log\n   4. Verify the bootstrap config of the Machine is ready",
+				Dependencies: []string{"Machine"},
+			},
+		},
+	}
+}
+
+func openStackProvider() Provider {
+	return &builtinProvider{
+		name: "openstack",
+		gvks: map[ComponentType]schema.GroupVersionKind{
+			OpenStackClusterType:         infrastructureGVK("v1beta1", OpenStackClusterType),
+			OpenStackMachineType:         infrastructureGVK("v1beta1", OpenStackMachineType),
+			OpenStackMachineTemplateType: infrastructureGVK("v1beta1", OpenStackMachineTemplateType),
+			OpenStackServerType:          infrastructureGVK("v1alpha1", OpenStackServerType),
+		},
+		owned: map[ComponentType][]ComponentType{
+			OpenStackMachineType: {OpenStackServerType},
+		},
+		status: func(comp *Component) (ComponentStatus, bool) {
+			return instanceStateStatus(comp, map[string]ComponentStatus{
+				"active":  StatusHealthy,
+				"build":   StatusPending,
+				"error":   StatusFailed,
+				"shutoff": StatusDegraded,
+				"deleted": StatusFailed,
+			})
+		},
+		rules: map[string]Rule{
+			"OpenStackCluster.NetworkReady.False": {
+				Condition:    "OpenStackCluster NetworkReady is False",
+				Severity:     SeverityCritical,
+				Cause:        "The cluster network, subnet or router could not be reconciled",
+				Resolution:   "1. Check OpenStackCluster: kubectl describe openstackcluster <name>\n   2. Verify the network and subnet exist: openstack network list\n   3. Check the project quota for networks, subnets and routers\n   4. Verify the cloud credentials Secret referenced by spec.identityRef",
+				Dependencies: []string{},
+			},
+			"OpenStackCluster.SecurityGroupsReady.False": {
+				Condition:    "OpenStackCluster SecurityGroupsReady is False",
+				Severity:     SeverityCritical,
+				Cause:        "Managed security groups could not be created or updated",
+				Resolution:   "1. Check the condition message for the failing rule\n   2. Verify the security group quota: openstack quota show\n   3. Review CAPO controller logs",
+				Dependencies: []string{},
+			},
+			"OpenStackCluster.APIEndpointReady.False": {
+				Condition:    "OpenStackCluster APIEndpointReady is False",
+				Severity:     SeverityCritical,
+				Cause:        "The API server load balancer or floating IP is not ready",
+				Resolution:   "1. Check the Octavia load balancer: openstack loadbalancer list\n   2. Verify a floating IP is available in the external network\n   3. Check spec.apiServerLoadBalancer and spec.externalNetwork\n   4. Review CAPO controller logs",
+				Dependencies: []string{},
+			},
+			"OpenStackMachine.InstanceReady.False": {
+				Condition:    "OpenStackMachine InstanceReady is False",
+				Severity:     SeverityCritical,
+				Cause:        "The server could not be created or is not active",
+				Resolution:   "1. Check OpenStackMachine: kubectl describe openstackmachine <name>\n   2. Show the server: openstack server show <name>\n   3. Verify the image, flavor and availability zone exist\n   4. Check the project quota for instances, cores and RAM\n   5. Review CAPO controller logs",
+				Dependencies: []string{"OpenStackServer"},
+			},
+		},
+	}
+}
+
+func vSphereProvider() Provider {
+	return &builtinProvider{
+		name: "vsphere",
+		gvks: map[ComponentType]schema.GroupVersionKind{
+			VSphereClusterType:         infrastructureGVK("v1beta1", VSphereClusterType),
+			VSphereMachineType:         infrastructureGVK("v1beta1", VSphereMachineType),
+			VSphereMachineTemplateType: infrastructureGVK("v1beta1", VSphereMachineTemplateType),
+			VSphereVMType:              infrastructureGVK("v1beta1", VSphereVMType),
+		},
+		owned: map[ComponentType][]ComponentType{
+			VSphereMachineType: {VSphereVMType},
+		},
+		rules: map[string]Rule{
+			"VSphereCluster.VCenterAvailable.False": {
+				Condition:    "VSphereCluster VCenterAvailable is False",
+				Severity:     SeverityCritical,
+				Cause:        "vCenter cannot be reached or rejected the credentials",
+				Resolution:   "1. Check VSphereCluster: kubectl describe vspherecluster <name>\n   2. Verify spec.server and spec.thumbprint match the vCenter certificate\n   3. Check the credentials Secret or VSphereClusterIdentity referenced by spec.identityRef\n   4. Test connectivity from the CAPV pod to vCenter on port 443",
+				Dependencies: []string{},
+			},
+			"VSphereCluster.ClusterModulesAvailable.False": {
+				Condition:    "VSphereCluster ClusterModulesAvailable is False",
+				Severity:     SeverityWarning,
+				Cause:        "vSphere cluster modules for anti-affinity could not be created",
+				Resolution:   "1. Verify vCenter is version 7.0 U1 or later\n   2. Check the user has permission to manage cluster modules\n   3. Review CAPV controller logs",
+				Dependencies: []string{},
+			},
+			"VSphereMachine.VMProvisioned.False": {
+				Condition:    "VSphereMachine VMProvisioned is False",
+				Severity:     SeverityCritical,
+				Cause:        "The virtual machine could not be cloned or powered on",
+				Resolution:   "1. Check the VSphereVM: kubectl describe vspherevm <name>\n   2. Verify the template, datastore, network and resource pool in the VSphereMachineTemplate\n   3. Check recent tasks in vCenter for clone errors\n   4. Review CAPV controller logs",
+				Dependencies: []string{"VSphereVM"},
+			},
+			"VSphereVM.VMProvisioned.False": {
+				Condition:    "VSphereVM VMProvisioned is False",
+				Severity:     SeverityCritical,
+				Cause:        "Cloning or powering on the virtual machine failed",
+				Resolution:   "1. Check the condition message for the vCenter task error\n   2. Verify the template exists and has enough free space on the datastore\n   3. Check that the network is available on the target host\n   4. Review recent tasks in vCenter",
+				Dependencies: []string{},
+			},
+		},
+	}
+}
+
+func awsProvider() Provider {
+	return &builtinProvider{
+		name: "aws",
+		gvks: map[ComponentType]schema.GroupVersionKind{
+			AWSClusterType:         infrastructureGVK("v1beta2", AWSClusterType),
+			AWSMachineType:         infrastructureGVK("v1beta2", AWSMachineType),
+			AWSMachineTemplateType: infrastructureGVK("v1beta2", AWSMachineTemplateType),
+		},
+		status: func(comp *Component) (ComponentStatus, bool) {
+			return instanceStateStatus(comp, map[string]ComponentStatus{
+				"running":       StatusHealthy,
+				"pending":       StatusPending,
+				"stopping":      StatusDegraded,
+				"stopped":       StatusDegraded,
+				"shutting-down": StatusFailed,
+				"terminated":    StatusFailed,
+			})
+		},
+		rules: map[string]Rule{
+			"AWSCluster.VpcReady.False": {
+				Condition:    "AWSCluster VpcReady is False",
+				Severity:     SeverityCritical,
+				Cause:        "The VPC could not be created or found",
+				Resolution:   "1. Check AWSCluster: kubectl describe awscluster <name>\n   2. Verify spec.network.vpc.id exists in the region when bringing your own VPC\n   3. Check the VPC service quota of the account\n   4. Review CAPA controller logs",
+				Dependencies: []string{},
+			},
+			"AWSCluster.SubnetsReady.False": {
+				Condition:    "AWSCluster SubnetsReady is False",
+				Severity:     SeverityCritical,
+				Cause:        "Subnets could not be created or do not match the VPC",
+				Resolution:   "1. Check the subnets in spec.network.subnets\n   2. Verify the CIDR blocks do not overlap and fit the VPC CIDR\n   3. Verify the availability zones exist in the region",
+				Dependencies: []string{},
+			},
+			"AWSCluster.LoadBalancerReady.False": {
+				Condition:    "AWSCluster LoadBalancerReady is False",
+				Severity:     SeverityCritical,
+				Cause:        "The API server load balancer is not ready",
+				Resolution:   "1. Check the load balancer in the EC2 console or: aws elbv2 describe-load-balancers\n   2. Verify the public subnets and internet gateway exist\n   3. Check the load balancer service quota\n   4. Review CAPA controller logs",
+				Dependencies: []string{},
+			},
+			"AWSCluster.BastionHostReady.False": {
+				Condition:    "AWSCluster BastionHostReady is False",
+				Severity:     SeverityWarning,
+				Cause:        "The bastion host could not be created",
+				Resolution:   "1. Check spec.bastion of the AWSCluster\n   2. Verify the bastion AMI and instance type are available in the region\n   3. Review CAPA controller logs",
+				Dependencies: []string{},
+			},
+			"AWSMachine.InstanceReady.False": {
+				Condition:    "AWSMachine InstanceReady is False",
+				Severity:     SeverityCritical,
+				Cause:        "The EC2 instance could not be created or is not running",
+				Resolution:   "1. Check AWSMachine: kubectl describe awsmachine <name>\n   2. Show the instance: aws ec2 describe-instances --instance-ids <id>\n   3. Verify the AMI, instance type and IAM instance profile exist\n   4. Check EC2 service quotas for the instance type\n   5. Review CAPA controller logs",
+				Dependencies: []string{},
+			},
+			"AWSMachine.SecurityGroupsReady.False": {
+				Condition:    "AWSMachine SecurityGroupsReady is False",
+				Severity:     SeverityWarning,
+				Cause:        "Security groups could not be attached to the instance",
+				Resolution:   "1. Check spec.additionalSecurityGroups of the AWSMachine\n   2. Verify the security groups exist in the VPC of the cluster\n   3. Review CAPA controller logs",
+				Dependencies: []string{},
+			},
+			"AWSMachine.ELBAttached.False": {
+				Condition:    "AWSMachine ELBAttached is False",
+				Severity:     SeverityWarning,
+				Cause:        "The control plane instance could not be registered with the API server load balancer",
+				Resolution:   "1. Check the LoadBalancerReady condition of the AWSCluster\n   2. Verify the instance is in a subnet served by the load balancer\n   3. Review CAPA controller logs",
+				Dependencies: []string{"AWSCluster"},
+			},
+		},
+	}
+}
@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// PoolUtilization describes how many addresses of a Metal3 IPPool are in use.
+type PoolUtilization struct {
+	Allocated int64 `json:"allocated"`
+	Capacity  int64 `json:"capacity"`
+}
+
+// Percent returns the share of allocated addresses in percent.
+func (u PoolUtilization) Percent() float64 {
+	if u.Capacity == 0 {
+		return 0
+	}
+	return float64(u.Allocated) * 100 / float64(u.Capacity)
+}
+
+// IPPoolUtilization computes the number of allocatable and allocated
+// addresses of an IPPool from spec.pools and status.indexes. Ranges without
+// start or end default to the usable addresses of the subnet, like the
+// ip-address-manager does.
+func IPPoolUtilization(pool *Component) (PoolUtilization, error) {
+	util := PoolUtilization{}
+
+	spec, ok := pool.Metadata["spec"].(map[string]interface{})
+	if !ok {
+		return util, fmt.Errorf("IPPool %s has no spec", pool.Name)
+	}
+	defaultPrefix, _, _ := unstructured.NestedInt64(spec, "prefix")
+
+	ranges, _, _ := unstructured.NestedSlice(spec, "pools")
+	capacity := new(big.Int)
+	for _, r := range ranges {
+		rangeMap, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		size, err := addressRangeSize(rangeMap, int(defaultPrefix))
+		if err != nil {
+			return util, fmt.Errorf("IPPool %s: %v", pool.Name, err)
+		}
+		capacity.Add(capacity, size)
+	}
+	if capacity.IsInt64() {
+		util.Capacity = capacity.Int64()
+	} else {
+		util.Capacity = 1<<63 - 1
+	}
+
+	if status, ok := pool.Metadata["status"].(map[string]interface{}); ok {
+		for _, field := range []string{"indexes", "allocations"} {
+			if allocations, found, _ := unstructured.NestedMap(status, field); found {
+				util.Allocated = int64(len(allocations))
+				break
+			}
+		}
+	}
+
+	return util, nil
+}
+
+// addressRangeSize returns the number of addresses between start and end of
+// a pool entry, both inclusive.
+func addressRangeSize(rangeMap map[string]interface{}, defaultPrefix int) (*big.Int, error) {
+	startStr, _ := rangeMap["start"].(string)
+	endStr, _ := rangeMap["end"].(string)
+	subnetStr, _ := rangeMap["subnet"].(string)
+
+	var start, end netip.Addr
+	var err error
+	if startStr != "" {
+		if start, err = netip.ParseAddr(startStr); err != nil {
+			return nil, fmt.Errorf("invalid start address %q", startStr)
+		}
+	}
+	if endStr != "" {
+		if end, err = netip.ParseAddr(endStr); err != nil {
+			return nil, fmt.Errorf("invalid end address %q", endStr)
+		}
+	}
+
+	if !start.IsValid() || !end.IsValid() {
+		var prefix netip.Prefix
+		switch {
+		case subnetStr != "":
+			if prefix, err = netip.ParsePrefix(subnetStr); err != nil {
+				return nil, fmt.Errorf("invalid subnet %q", subnetStr)
+			}
+		case start.IsValid():
+			bits := defaultPrefix
+			if p, ok := rangeMap["prefix"].(int64); ok {
+				bits = int(p)
+			}
+			if bits == 0 {
+				return nil, fmt.Errorf("range starting at %s has no end, subnet or prefix", start)
+			}
+			if prefix, err = start.Prefix(bits); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("range has neither start/end nor subnet")
+		}
+
+		prefix = prefix.Masked()
+		if !start.IsValid() {
+			start = prefix.Addr().Next()
+		}
+		if !end.IsValid() {
+			end = lastAddr(prefix).Prev()
+		}
+	}
+
+	size := new(big.Int).Sub(new(big.Int).SetBytes(end.AsSlice()), new(big.Int).SetBytes(start.AsSlice()))
+	size.Add(size, big.NewInt(1))
+	if size.Sign() <= 0 {
+		return nil, fmt.Errorf("range end %s is before start %s", end, start)
+	}
+	return size, nil
+}
+
+// lastAddr returns the highest address of a prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	hostBits := len(bytes)*8 - prefix.Bits()
+	for i := len(bytes) - 1; i >= 0 && hostBits > 0; i-- {
+		if hostBits >= 8 {
+			bytes[i] = 0xff
+			hostBits -= 8
+		} else {
+			bytes[i] |= byte(1<<hostBits - 1)
+			hostBits = 0
+		}
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr.WithZone(prefix.Addr().Zone())
+}
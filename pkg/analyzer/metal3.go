@@ -0,0 +1,41 @@
+package analyzer
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// BareMetalHostAnnotation is set by CAPM3 on a Metal3Machine once it is
+// associated with a BareMetalHost. The value has the form namespace/name.
+const BareMetalHostAnnotation = "metal3.io/BareMetalHost"
+
+// AnnotatedHost returns the namespace and name of the BareMetalHost a
+// Metal3Machine is associated with according to its annotation.
+func AnnotatedHost(metal3Machine *Component) (string, string) {
+	annotations, _ := metal3Machine.Metadata["annotations"].(map[string]string)
+	value := annotations[BareMetalHostAnnotation]
+	if value == "" {
+		return "", ""
+	}
+	if namespace, name, found := strings.Cut(value, "/"); found {
+		return namespace, name
+	}
+	return metal3Machine.Namespace, value
+}
+
+// HostConsumer returns the kind, namespace and name of the object that
+// consumes a BareMetalHost, taken from spec.consumerRef.
+func HostConsumer(bmh *Component) (string, string, string) {
+	spec, ok := bmh.Metadata["spec"].(map[string]interface{})
+	if !ok {
+		return "", "", ""
+	}
+	kind, _, _ := unstructured.NestedString(spec, "consumerRef", "kind")
+	name, _, _ := unstructured.NestedString(spec, "consumerRef", "name")
+	namespace, _, _ := unstructured.NestedString(spec, "consumerRef", "namespace")
+	if name != "" && namespace == "" {
+		namespace = bmh.Namespace
+	}
+	return kind, namespace, name
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ClusterNameLabel is set by Cluster API on the objects of a Cluster.
+const ClusterNameLabel = "cluster.x-k8s.io/cluster-name"
+
+// Labels returns the labels of a component.
+func Labels(comp *Component) map[string]string {
+	labels, _ := comp.Metadata["labels"].(map[string]string)
+	return labels
+}
+
+// UID returns the UID of the object of a component, empty for objects that
+// were never fetched.
+func UID(comp *Component) string {
+	metadata, _ := comp.Metadata["metadata"].(map[string]interface{})
+	uid, _ := metadata["uid"].(string)
+	return uid
+}
+
+// CreationTimestamp returns when the object of a component was created, zero
+// for objects that were never fetched.
+func CreationTimestamp(comp *Component) time.Time {
+	metadata, _ := comp.Metadata["metadata"].(map[string]interface{})
+	created, _ := metadata["creationTimestamp"].(time.Time)
+	return created
+}
+
+// Phase returns the phase a component reports, status.phase for Cluster API
+// kinds and the provisioning state for BareMetalHosts.
+func Phase(comp *Component) string {
+	status, _ := comp.Metadata["status"].(map[string]interface{})
+	if comp.Type == BareMetalHostType {
+		state, _, _ := unstructured.NestedString(status, "provisioning", "state")
+		return state
+	}
+	phase, _, _ := unstructured.NestedString(status, "phase")
+	return phase
+}
+
+// NodeName returns the Node a Machine runs as, taken from status.nodeRef.
+func NodeName(comp *Component) string {
+	status, _ := comp.Metadata["status"].(map[string]interface{})
+	name, _, _ := unstructured.NestedString(status, "nodeRef", "name")
+	return name
+}
+
+// ClusterName returns the name of the Cluster a component belongs to
+// according to its spec.clusterName or its cluster-name label.
+func ClusterName(comp *Component) string {
+	if comp.Type == ClusterType {
+		return comp.Name
+	}
+	if spec, ok := comp.Metadata["spec"].(map[string]interface{}); ok {
+		if name, found, _ := unstructured.NestedString(spec, "clusterName"); found && name != "" {
+			return name
+		}
+	}
+	return Labels(comp)[ClusterNameLabel]
+}
+
+// ClusterIndex holds components by the Cluster they belong to, so the
+// objects of a Cluster are found with a lookup rather than a scan of all
+// components. A component whose spec.clusterName and cluster-name label
+// differ is indexed under both Clusters.
+type ClusterIndex struct {
+	// byCluster holds components by the namespace/name of their Cluster
+	byCluster map[string][]*Component
+	// byName holds components by the name of their Cluster in any namespace
+	byName map[string][]*Component
+}
+
+// NewClusterIndex indexes components by their Cluster, keeping the order
+// of components within each Cluster.
+func NewClusterIndex(components []*Component) *ClusterIndex {
+	index := &ClusterIndex{
+		byCluster: make(map[string][]*Component),
+		byName:    make(map[string][]*Component),
+	}
+	for _, comp := range components {
+		name := ClusterName(comp)
+		if name != "" {
+			index.add(comp, name)
+		}
+		// A Cluster belongs to itself only
+		if label := Labels(comp)[ClusterNameLabel]; label != "" && label != name && comp.Type != ClusterType {
+			index.add(comp, label)
+		}
+	}
+	return index
+}
+
+func (i *ClusterIndex) add(comp *Component, cluster string) {
+	key := comp.Namespace + "/" + cluster
+	i.byCluster[key] = append(i.byCluster[key], comp)
+	i.byName[cluster] = append(i.byName[cluster], comp)
+}
+
+// Members returns the components of the Cluster with a namespace and name,
+// the Cluster included.
+func (i *ClusterIndex) Members(namespace, name string) []*Component {
+	return i.byCluster[namespace+"/"+name]
+}
+
+// Named returns the components of the Clusters with a name in any
+// namespace, the Clusters included.
+func (i *ClusterIndex) Named(name string) []*Component {
+	return i.byName[name]
+}
@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// DiscoveryOutcome is the result of listing one kind.
+type DiscoveryOutcome string
+
+const (
+	DiscoveryOK           DiscoveryOutcome = "OK"
+	DiscoveryNotInstalled DiscoveryOutcome = "CRDNotInstalled"
+	DiscoveryForbidden    DiscoveryOutcome = "Forbidden"
+	DiscoveryTimeout      DiscoveryOutcome = "Timeout"
+	DiscoveryFailed       DiscoveryOutcome = "Error"
+)
+
+// Incomplete reports whether objects of the kind may be missing from the
+// analysis. Kinds whose CRD is not installed have no objects to miss.
+func (o DiscoveryOutcome) Incomplete() bool {
+	return o != DiscoveryOK && o != DiscoveryNotInstalled
+}
+
+// listOutcome classifies the error of a list call.
+func listOutcome(err error) DiscoveryOutcome {
+	switch {
+	case err == nil:
+		return DiscoveryOK
+	case meta.IsNoMatchError(err), apierrors.IsNotFound(err):
+		return DiscoveryNotInstalled
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return DiscoveryForbidden
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), errors.Is(err, context.DeadlineExceeded):
+		return DiscoveryTimeout
+	default:
+		return DiscoveryFailed
+	}
+}
+
+// IncompleteDiscovery returns the kinds that could not be listed completely.
+func IncompleteDiscovery(outcomes []GVKDiscovery) []GVKDiscovery {
+	var incomplete []GVKDiscovery
+	for _, outcome := range outcomes {
+		if outcome.Outcome.Incomplete() {
+			incomplete = append(incomplete, outcome)
+		}
+	}
+	return incomplete
+}
@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Provider contributes the kinds of a Cluster API provider to discovery and
+// analysis. Kinds are linked in the tree through the generic ref fields of
+// Cluster API objects, so a provider only describes its own objects.
+type Provider interface {
+	// Name identifies the provider, e.g. "docker" or "aws"
+	Name() string
+	// GVKs returns the kinds to discover
+	GVKs() map[ComponentType]schema.GroupVersionKind
+	// Status derives the status of a component of the provider that has no
+	// conditions. It returns false to use the default interpretation.
+	Status(comp *Component) (ComponentStatus, bool)
+	// Rules returns knowledge base entries keyed by
+	// "Kind.ConditionType.Status" or "Kind.ConditionType.Status.Reason"
+	Rules() map[string]Rule
+}
+
+// Rule describes the cause and resolution of a condition.
+type Rule struct {
+	Condition    string
+	Severity     ConditionSeverity
+	Cause        string
+	Resolution   string
+	Dependencies []string
+}
+
+var (
+	providers     []Provider
+	providerKinds = make(map[ComponentType]Provider)
+)
+
+// RegisterProvider adds a provider and discovers its kinds from then on.
+func RegisterProvider(p Provider) {
+	providers = append(providers, p)
+	for compType, gvk := range p.GVKs() {
+		SupportedGVKs[compType] = gvk
+		providerKinds[compType] = p
+	}
+}
+
+// Providers returns all registered providers ordered by name.
+func Providers() []Provider {
+	sorted := append([]Provider(nil), providers...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name() < sorted[j].Name()
+	})
+	return sorted
+}
+
+// ProviderFor returns the provider of a component type, or nil for kinds
+// built into the advisor.
+func ProviderFor(compType ComponentType) Provider {
+	return providerKinds[compType]
+}
+
+// ObjectRef is a reference to another object such as infrastructureRef or
+// bootstrap.configRef.
+type ObjectRef struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	// APIGroup is used instead of APIVersion by v1beta2 contract refs
+	APIGroup  string `json:"apiGroup,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Group returns the API group of the referenced object.
+func (r ObjectRef) Group() string {
+	if r.APIGroup != "" {
+		return r.APIGroup
+	}
+	if group, _, found := strings.Cut(r.APIVersion, "/"); found {
+		return group
+	}
+	return ""
+}
+
+// NestedRef reads a reference at the given path of a component's spec. The
+// namespace defaults to the namespace of the component.
+func NestedRef(comp *Component, fields ...string) (ObjectRef, bool) {
+	spec, ok := comp.Metadata["spec"].(map[string]interface{})
+	if !ok {
+		return ObjectRef{}, false
+	}
+	refMap, found, _ := unstructured.NestedMap(spec, fields...)
+	if !found {
+		return ObjectRef{}, false
+	}
+
+	ref := parseObjectRef(refMap, comp.Namespace)
+	return ref, ref.Kind != "" && ref.Name != ""
+}
+
+// parseObjectRef reads a reference, defaulting its namespace.
+func parseObjectRef(refMap map[string]interface{}, namespace string) ObjectRef {
+	var ref ObjectRef
+	ref.APIVersion, _ = refMap["apiVersion"].(string)
+	ref.APIGroup, _ = refMap["apiGroup"].(string)
+	ref.Kind, _ = refMap["kind"].(string)
+	ref.Name, _ = refMap["name"].(string)
+	ref.Namespace, _ = refMap["namespace"].(string)
+	if ref.Namespace == "" {
+		ref.Namespace = namespace
+	}
+	return ref
+}
+
+// Matches reports whether a component is the object the reference points
+// to. The API group is compared when the reference names one, the version
+// is not since the discovered version may differ from the referenced one.
+func (r ObjectRef) Matches(comp *Component) bool {
+	if string(comp.Type) != r.Kind || comp.Name != r.Name || comp.Namespace != r.Namespace {
+		return false
+	}
+	return r.Group() == "" || comp.GVK.Group == "" || comp.GVK.Group == r.Group()
+}
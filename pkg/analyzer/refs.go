@@ -0,0 +1,230 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Metadata keys of components fetched by following references.
+const (
+	// ReferencedByKey describes the reference a component was fetched
+	// through, e.g. "Cluster/my-cluster spec.infrastructureRef"
+	ReferencedByKey = "referencedBy"
+	// LookupErrorKey holds the error of a referenced object that could not
+	// be fetched
+	LookupErrorKey = "lookupError"
+)
+
+// Reasons a referenced object could not be fetched.
+const (
+	LookupNotFound     = "NotFound"
+	LookupNotInstalled = "CRDNotInstalled"
+	LookupForbidden    = "Forbidden"
+	LookupFailed       = "Error"
+)
+
+// LookupError describes why a referenced object could not be fetched.
+type LookupError struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// ReferencedBy returns the reference a component was fetched through, or an
+// empty string for components found by listing a supported kind.
+func ReferencedBy(comp *Component) string {
+	referencedBy, _ := comp.Metadata[ReferencedByKey].(string)
+	return referencedBy
+}
+
+// ReferenceLookupError returns why a referenced object could not be fetched.
+func ReferenceLookupError(comp *Component) (LookupError, bool) {
+	lookupErr, ok := comp.Metadata[LookupErrorKey].(LookupError)
+	return lookupErr, ok
+}
+
+// specRef is a reference found in the spec of a component.
+type specRef struct {
+	path string
+	ref  ObjectRef
+}
+
+// followReferences fetches every object referenced by the components whose
+// kind is not discovered by listing, so providers the advisor does not know
+// still appear in the tree and analysis. Fetched objects are followed in
+// turn.
+func (d *ComponentDiscovery) followReferences(ctx context.Context, components []*Component) []*Component {
+	seen := make(map[string]bool)
+	for _, comp := range components {
+		seen[comp.Namespace+"/"+comp.Name+"/"+string(comp.Type)] = true
+	}
+
+	queue := append([]*Component(nil), components...)
+	for len(queue) > 0 {
+		comp := queue[0]
+		queue = queue[1:]
+
+		for _, found := range collectRefs(comp) {
+			key := found.ref.Namespace + "/" + found.ref.Name + "/" + found.ref.Kind
+			if seen[key] || isListedKind(found.ref) {
+				continue
+			}
+			seen[key] = true
+
+			referenced := d.fetchReference(ctx, found.ref)
+			referenced.Metadata[ReferencedByKey] = fmt.Sprintf("%s/%s %s", comp.Type, comp.Name, found.path)
+			components = append(components, referenced)
+			queue = append(queue, referenced)
+		}
+	}
+
+	return components
+}
+
+// fetchReference gets a referenced object as a generic component. Objects
+// that cannot be fetched are returned as placeholders carrying the error.
+func (d *ComponentDiscovery) fetchReference(ctx context.Context, ref ObjectRef) *Component {
+	gvk, err := d.referenceGVK(ref)
+	if err == nil {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		reqCtx, cancel := d.requestContext(ctx)
+		err = d.client.Get(reqCtx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, obj)
+		cancel()
+		if err == nil {
+			return d.convertUnstructuredToComponent(obj, ComponentType(ref.Kind), gvk)
+		}
+	}
+
+	lookupErr := LookupError{Reason: LookupFailed, Message: err.Error()}
+	status := StatusUnknown
+	switch {
+	case apierrors.IsNotFound(err):
+		lookupErr.Reason = LookupNotFound
+		status = StatusFailed
+	case meta.IsNoMatchError(err):
+		lookupErr.Reason = LookupNotInstalled
+		status = StatusFailed
+	case apierrors.IsForbidden(err):
+		lookupErr.Reason = LookupForbidden
+	}
+
+	return &Component{
+		Name:      ref.Name,
+		Namespace: ref.Namespace,
+		Type:      ComponentType(ref.Kind),
+		GVK:       gvk,
+		Status:    status,
+		Metadata: map[string]interface{}{
+			LookupErrorKey: lookupErr,
+		},
+	}
+}
+
+// referenceGVK returns the kind to fetch for a reference. References that
+// only name an API group, as in the v1beta2 contract, are resolved to the
+// preferred version.
+func (d *ComponentDiscovery) referenceGVK(ref ObjectRef) (schema.GroupVersionKind, error) {
+	if ref.APIVersion != "" {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			return schema.GroupVersionKind{Kind: ref.Kind}, err
+		}
+		return gv.WithKind(ref.Kind), nil
+	}
+
+	mapping, err := d.client.RESTMapper().RESTMapping(schema.GroupKind{Group: ref.Group(), Kind: ref.Kind})
+	if err != nil {
+		return schema.GroupVersionKind{Group: ref.Group(), Kind: ref.Kind}, err
+	}
+	return mapping.GroupVersionKind, nil
+}
+
+// References returns the objects referenced anywhere in the spec of a
+// component, ordered by their path.
+func References(comp *Component) []ObjectRef {
+	var refs []ObjectRef
+	for _, found := range collectRefs(comp) {
+		refs = append(refs, found.ref)
+	}
+	return refs
+}
+
+// collectRefs finds all object references in the spec of a component: maps
+// under a key named "ref" or ending in "Ref" that name a kind and a name.
+// consumerRef is skipped since it points back to the consumer of a host.
+func collectRefs(comp *Component) []specRef {
+	spec, ok := comp.Metadata["spec"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var refs []specRef
+	var walk func(value interface{}, path string)
+	walk = func(value interface{}, path string) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				child := path + "." + key
+				if refMap, ok := v[key].(map[string]interface{}); ok && isRefKey(key) {
+					if ref, ok := objectRef(refMap, comp.Namespace); ok {
+						refs = append(refs, specRef{path: child, ref: ref})
+						continue
+					}
+				}
+				walk(v[key], child)
+			}
+		case []interface{}:
+			for i, item := range v {
+				walk(item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+	walk(spec, "spec")
+
+	return refs
+}
+
+func isRefKey(key string) bool {
+	return key == "ref" || (strings.HasSuffix(key, "Ref") && key != "consumerRef")
+}
+
+func objectRef(refMap map[string]interface{}, namespace string) (ObjectRef, bool) {
+	ref := parseObjectRef(refMap, namespace)
+	// Core kinds such as Secrets and ConfigMaps are not part of the chain
+	if ref.APIVersion == "v1" {
+		return ref, false
+	}
+	return ref, ref.Kind != "" && ref.Name != "" && (ref.APIVersion != "" || ref.APIGroup != "")
+}
+
+// isListedKind reports whether objects of the referenced kind are already
+// discovered by listing.
+func isListedKind(ref ObjectRef) bool {
+	for _, gvk := range SupportedGVKs {
+		if gvk.Kind == ref.Kind && gvk.Group == ref.Group() {
+			return true
+		}
+	}
+	for _, gvks := range AlternativeGVKs {
+		for _, gvk := range gvks {
+			if gvk.Kind == ref.Kind && gvk.Group == ref.Group() {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func ref(apiVersion, kind, name string) map[string]interface{} {
+	return map[string]interface{}{"apiVersion": apiVersion, "kind": kind, "name": name}
+}
+
+func TestReferences(t *testing.T) {
+	tests := []struct {
+		name string
+		spec map[string]interface{}
+		want []ObjectRef
+	}{
+		{
+			name: "no spec",
+		},
+		{
+			name: "refs ordered by path",
+			spec: map[string]interface{}{
+				"infrastructureRef": ref("infrastructure.example.com/v1", "FooCluster", "foo"),
+				"controlPlaneRef":   ref("controlplane.example.com/v1", "FooControlPlane", "foo-cp"),
+			},
+			want: []ObjectRef{
+				{APIVersion: "controlplane.example.com/v1", Kind: "FooControlPlane", Name: "foo-cp", Namespace: "default"},
+				{APIVersion: "infrastructure.example.com/v1", Kind: "FooCluster", Name: "foo", Namespace: "default"},
+			},
+		},
+		{
+			name: "nested ref with namespace and API group",
+			spec: map[string]interface{}{
+				"template": map[string]interface{}{
+					"ref": map[string]interface{}{"apiGroup": "infrastructure.example.com", "kind": "FooTemplate", "name": "tpl", "namespace": "shared"},
+				},
+			},
+			want: []ObjectRef{{APIGroup: "infrastructure.example.com", Kind: "FooTemplate", Name: "tpl", Namespace: "shared"}},
+		},
+		{
+			name: "refs in lists",
+			spec: map[string]interface{}{
+				"workers": []interface{}{
+					map[string]interface{}{"bootstrapRef": ref("bootstrap.example.com/v1", "FooConfig", "a")},
+					map[string]interface{}{"bootstrapRef": ref("bootstrap.example.com/v1", "FooConfig", "b")},
+				},
+			},
+			want: []ObjectRef{
+				{APIVersion: "bootstrap.example.com/v1", Kind: "FooConfig", Name: "a", Namespace: "default"},
+				{APIVersion: "bootstrap.example.com/v1", Kind: "FooConfig", Name: "b", Namespace: "default"},
+			},
+		},
+		{
+			name: "skipped refs",
+			spec: map[string]interface{}{
+				"consumerRef":   ref("infrastructure.example.com/v1", "FooMachine", "m"),
+				"secretRef":     ref("v1", "Secret", "creds"),
+				"incompleteRef": map[string]interface{}{"kind": "FooCluster", "name": "foo"},
+				"reference":     ref("infrastructure.example.com/v1", "FooCluster", "foo"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comp := &Component{Name: "c", Namespace: "default", Type: ClusterType, Metadata: map[string]interface{}{}}
+			if tt.spec != nil {
+				comp.Metadata["spec"] = tt.spec
+			}
+			if got := References(comp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("References() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFollowReferences(t *testing.T) {
+	fooCluster := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "infrastructure.example.com/v1",
+		"kind":       "FooCluster",
+		"metadata":   map[string]interface{}{"name": "foo", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"networkRef": ref("infrastructure.example.com/v1", "FooNetwork", "missing"),
+			// A reference back to the Cluster is not fetched again
+			"clusterRef": ref(SupportedGVKs[ClusterType].GroupVersion().String(), "Cluster", "c"),
+		},
+	}}
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(fooCluster).Build()
+
+	cluster := &Component{
+		Name:      "c",
+		Namespace: "default",
+		Type:      ClusterType,
+		Metadata: map[string]interface{}{"spec": map[string]interface{}{
+			"infrastructureRef": ref("infrastructure.example.com/v1", "FooCluster", "foo"),
+			"controlPlaneRef":   map[string]interface{}{"apiGroup": "controlplane.example.com", "kind": "FooControlPlane", "name": "foo-cp"},
+		}},
+	}
+
+	components := NewComponentDiscovery(c).followReferences(context.Background(), []*Component{cluster})
+
+	type result struct {
+		referencedBy string
+		status       ComponentStatus
+		lookupError  string
+	}
+	got := make(map[string]result)
+	for _, comp := range components[1:] {
+		lookupErr, _ := ReferenceLookupError(comp)
+		got[string(comp.Type)+"/"+comp.Name] = result{ReferencedBy(comp), comp.Status, lookupErr.Reason}
+	}
+	want := map[string]result{
+		// The fake client has no mapping for the API group
+		"FooControlPlane/foo-cp": {"Cluster/c spec.controlPlaneRef", StatusFailed, LookupNotInstalled},
+		"FooCluster/foo":         {"Cluster/c spec.infrastructureRef", StatusUnknown, ""},
+		"FooNetwork/missing":     {"FooCluster/foo spec.networkRef", StatusFailed, LookupNotFound},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("followed references = %+v, want %+v", got, want)
+	}
+}
@@ -17,6 +17,25 @@ const (
 	BareMetalHostType    ComponentType = "BareMetalHost"
 	KubeadmControlPlaneType ComponentType = "KubeadmControlPlane"
 	KubeadmConfigType    ComponentType = "KubeadmConfig"
+	MachinePoolType      ComponentType = "MachinePool"
+	DockerMachinePoolType ComponentType = "DockerMachinePool"
+	AWSMachinePoolType   ComponentType = "AWSMachinePool"
+	AzureMachinePoolType ComponentType = "AzureMachinePool"
+	ClusterClassType     ComponentType = "ClusterClass"
+	KubeadmControlPlaneTemplateType ComponentType = "KubeadmControlPlaneTemplate"
+	KubeadmConfigTemplateType ComponentType = "KubeadmConfigTemplate"
+	Metal3ClusterTemplateType ComponentType = "Metal3ClusterTemplate"
+	Metal3MachineTemplateType ComponentType = "Metal3MachineTemplate"
+	Metal3DataTemplateType ComponentType = "Metal3DataTemplate"
+	Metal3DataType       ComponentType = "Metal3Data"
+	Metal3DataClaimType  ComponentType = "Metal3DataClaim"
+	IPPoolType           ComponentType = "IPPool"
+	IPClaimType          ComponentType = "IPClaim"
+	IPAddressType        ComponentType = "IPAddress"
+	HardwareDataType     ComponentType = "HardwareData"
+	HostFirmwareSettingsType ComponentType = "HostFirmwareSettings"
+	HostFirmwareComponentsType ComponentType = "HostFirmwareComponents"
+	FirmwareSchemaType   ComponentType = "FirmwareSchema"
 )
 
 type Component struct {
@@ -26,8 +45,10 @@ type Component struct {
 	GVK        schema.GroupVersionKind `json:"gvk"`
 	Conditions []metav1.Condition `json:"conditions"`
 	Status     ComponentStatus    `json:"status"`
-	Children   []*Component      `json:"children,omitempty"`
-	Parent     *Component        `json:"parent,omitempty"`
+	// Tree links are not serialized since they form cycles, the report
+	// package emits the tree as an adjacency list instead
+	Children   []*Component      `json:"-"`
+	Parent     *Component        `json:"-"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -50,6 +71,8 @@ const (
 )
 
 type Issue struct {
+	// RuleID names the knowledge base entry or check that found the issue
+	RuleID       string            `json:"ruleId"`
 	Component    *Component        `json:"component"`
 	Condition    metav1.Condition  `json:"condition"`
 	Severity     ConditionSeverity `json:"severity"`
@@ -59,10 +82,26 @@ type Issue struct {
 	Dependencies []*Component      `json:"dependencies,omitempty"`
 }
 
+// GuidanceHeading starts the line of a resolution that introduces guidance
+// specific to the reason of a condition. Text output marks it with an icon.
+const GuidanceHeading = "Specific guidance based on"
+
 type AnalysisResult struct {
 	Components []*Component `json:"components"`
 	Issues     []*Issue     `json:"issues"`
 	Summary    Summary      `json:"summary"`
+	Discovery  []GVKDiscovery `json:"discovery,omitempty"`
+}
+
+// GVKDiscovery records how listing one kind went.
+type GVKDiscovery struct {
+	Type       ComponentType    `json:"type"`
+	GVK        schema.GroupVersionKind `json:"gvk"`
+	Outcome    DiscoveryOutcome `json:"outcome"`
+	Count      int              `json:"count"`
+	Pages      int              `json:"pages"`
+	DurationMs int64            `json:"duration_ms"`
+	Error      string           `json:"error,omitempty"`
 }
 
 type Summary struct {
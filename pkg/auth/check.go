@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"sync"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Check is the result of checking a requirement in a namespace. An empty
+// namespace stands for all namespaces, or the cluster scope for resources
+// that are not namespaced.
+type Check struct {
+	Requirement
+	Namespace string          `json:"namespace"`
+	Allowed   map[string]bool `json:"allowed"`
+	// Errors holds the error of reviews that failed, keyed by verb
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Missing returns the verbs that are not allowed.
+func (c Check) Missing() []string {
+	var missing []string
+	for _, verb := range c.Verbs {
+		if !c.Allowed[verb] {
+			missing = append(missing, verb)
+		}
+	}
+	return missing
+}
+
+// review is a single SelfSubjectAccessReview of a check.
+type review struct {
+	check *Check
+	verb  string
+}
+
+// CheckPermissions issues a SelfSubjectAccessReview for every verb of every
+// installed requirement in every namespace, with at most concurrency
+// reviews in flight. Resources that are not namespaced are checked once at
+// cluster scope. The checks are returned in the order of the requirements.
+func CheckPermissions(ctx context.Context, clientset kubernetes.Interface, requirements []Requirement, namespaces []string, concurrency int) []Check {
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var checks []*Check
+	for _, requirement := range requirements {
+		if requirement.NotInstalled {
+			continue
+		}
+		if !requirement.Namespaced {
+			checks = append(checks, &Check{Requirement: requirement})
+			continue
+		}
+		for _, namespace := range namespaces {
+			checks = append(checks, &Check{Requirement: requirement, Namespace: namespace})
+		}
+	}
+
+	var reviews []review
+	for _, check := range checks {
+		check.Allowed = make(map[string]bool)
+		for _, verb := range check.Verbs {
+			reviews = append(reviews, review{check: check, verb: verb})
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan review)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range queue {
+				allowed, err := accessAllowed(ctx, clientset, r.check, r.verb)
+				mu.Lock()
+				r.check.Allowed[r.verb] = allowed
+				if err != nil {
+					if r.check.Errors == nil {
+						r.check.Errors = make(map[string]string)
+					}
+					r.check.Errors[r.verb] = err.Error()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, r := range reviews {
+		queue <- r
+	}
+	close(queue)
+	wg.Wait()
+
+	result := make([]Check, 0, len(checks))
+	for _, check := range checks {
+		result = append(result, *check)
+	}
+	return result
+}
+
+func accessAllowed(ctx context.Context, clientset kubernetes.Interface, check *Check, verb string) (bool, error) {
+	ssar := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   check.Namespace,
+				Verb:        verb,
+				Group:       check.Group,
+				Resource:    check.Resource,
+				Subresource: check.Subresource,
+			},
+		},
+	}
+
+	response, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return response.Status.Allowed, nil
+}
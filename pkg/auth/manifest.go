@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// role is a ClusterRole or Role without the server populated fields of the
+// typed objects, so the manifest stays ready to apply.
+type role struct {
+	APIVersion string              `json:"apiVersion"`
+	Kind       string              `json:"kind"`
+	Metadata   roleMetadata        `json:"metadata"`
+	Rules      []rbacv1.PolicyRule `json:"rules"`
+}
+
+type roleMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Manifest renders a ClusterRole with the permissions missing at cluster
+// scope and a Role per namespace with the permissions missing there. It
+// returns nil when nothing is missing.
+func Manifest(checks []Check, name string) ([]byte, error) {
+	byNamespace := make(map[string][]Check)
+	for _, check := range checks {
+		if len(check.Missing()) > 0 {
+			byNamespace[check.Namespace] = append(byNamespace[check.Namespace], check)
+		}
+	}
+	if len(byNamespace) == 0 {
+		return nil, nil
+	}
+
+	namespaces := make([]string, 0, len(byNamespace))
+	for namespace := range byNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	var manifest bytes.Buffer
+	for _, namespace := range namespaces {
+		r := role{
+			APIVersion: "rbac.authorization.k8s.io/v1",
+			Kind:       "ClusterRole",
+			Metadata:   roleMetadata{Name: name},
+			Rules:      policyRules(byNamespace[namespace]),
+		}
+		if namespace != "" {
+			r.Kind = "Role"
+			r.Metadata.Namespace = namespace
+		}
+
+		data, err := yaml.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		manifest.WriteString("---\n")
+		manifest.Write(data)
+	}
+
+	return manifest.Bytes(), nil
+}
+
+// policyRules groups the missing verbs into one rule per API group and set
+// of verbs.
+func policyRules(checks []Check) []rbacv1.PolicyRule {
+	type ruleKey struct {
+		group string
+		verbs string
+	}
+
+	resources := make(map[ruleKey][]string)
+	for _, check := range checks {
+		key := ruleKey{group: check.Group, verbs: strings.Join(check.Missing(), ",")}
+		resources[key] = append(resources[key], check.FullResource())
+	}
+
+	keys := make([]ruleKey, 0, len(resources))
+	for key := range resources {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].group != keys[j].group {
+			return keys[i].group < keys[j].group
+		}
+		return keys[i].verbs < keys[j].verbs
+	})
+
+	var rules []rbacv1.PolicyRule
+	for _, key := range keys {
+		sort.Strings(resources[key])
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{key.group},
+			Resources: resources[key],
+			Verbs:     strings.Split(key.verbs, ","),
+		})
+	}
+	return rules
+}
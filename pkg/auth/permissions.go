@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"sort"
+
+	"capi-advisor/pkg/analyzer"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Verbs checked for every listed kind
+var discoveryVerbs = []string{"get", "list", "watch"}
+
+// Requirement is a permission the advisor needs for a feature.
+type Requirement struct {
+	Feature     string   `json:"feature"`
+	Kind        string   `json:"kind,omitempty"`
+	Group       string   `json:"group"`
+	Resource    string   `json:"resource"`
+	Subresource string   `json:"subresource,omitempty"`
+	Verbs       []string `json:"verbs"`
+	Namespaced  bool     `json:"namespaced"`
+	// NotInstalled is set for kinds whose CRD is not served, their
+	// permissions cannot be checked and are not needed
+	NotInstalled bool `json:"notInstalled,omitempty"`
+}
+
+// FullResource returns the resource with its subresource, e.g. "pods/log".
+func (r Requirement) FullResource() string {
+	if r.Subresource != "" {
+		return r.Resource + "/" + r.Subresource
+	}
+	return r.Resource
+}
+
+// Requirements returns the permissions needed by the advisor: get, list and
+// watch on every kind it discovers, get on the BMC credential Secrets and
+// read access to the events, controller logs and nodes that resolutions
+// point to. Kinds are mapped to resources with the RESTMapper, so kinds
+// whose CRD is not installed are marked instead of guessed.
+func Requirements(mapper meta.RESTMapper) []Requirement {
+	var requirements []Requirement
+	seen := make(map[schema.GroupKind]bool)
+
+	add := func(compType analyzer.ComponentType, gvk schema.GroupVersionKind) {
+		if seen[gvk.GroupKind()] {
+			return
+		}
+		seen[gvk.GroupKind()] = true
+
+		feature := "discovery"
+		if provider := analyzer.ProviderFor(compType); provider != nil {
+			feature = "provider " + provider.Name()
+		}
+		requirement := Requirement{
+			Feature: feature,
+			Kind:    gvk.Kind,
+			Group:   gvk.Group,
+			Verbs:   discoveryVerbs,
+		}
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			// The kind may be served in another version only
+			mapping, err = mapper.RESTMapping(gvk.GroupKind())
+		}
+		if err != nil {
+			requirement.NotInstalled = true
+			requirement.Namespaced = true
+		} else {
+			requirement.Resource = mapping.Resource.Resource
+			requirement.Namespaced = mapping.Scope.Name() == meta.RESTScopeNameNamespace
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	types := make([]analyzer.ComponentType, 0, len(analyzer.SupportedGVKs))
+	for compType := range analyzer.SupportedGVKs {
+		types = append(types, compType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	for _, compType := range types {
+		add(compType, analyzer.SupportedGVKs[compType])
+		for _, gvk := range analyzer.AlternativeGVKs[compType] {
+			add(compType, gvk)
+		}
+	}
+
+	return append(requirements,
+		Requirement{Feature: "bmc-credentials", Kind: "Secret", Resource: "secrets", Verbs: []string{"get"}, Namespaced: true},
+		Requirement{Feature: "troubleshooting", Kind: "Event", Resource: "events", Verbs: []string{"list", "watch"}, Namespaced: true},
+		Requirement{Feature: "troubleshooting", Kind: "Pod", Resource: "pods", Subresource: "log", Verbs: []string{"get"}, Namespaced: true},
+		Requirement{Feature: "troubleshooting", Kind: "Node", Resource: "nodes", Verbs: []string{"get", "list"}},
+	)
+}
@@ -17,11 +17,15 @@ type K8sClient struct {
 	Client    client.Client
 	Clientset *kubernetes.Clientset
 	Config    *rest.Config
+	// Context is the kubeconfig context in use, "in-cluster" when running
+	// as a pod
+	Context string
 }
 
 func NewK8sClient() (*K8sClient, error) {
 	var config *rest.Config
 	var err error
+	kubeContext := "in-cluster"
 
 	// Try in-cluster config first
 	config, err = rest.InClusterConfig()
@@ -39,6 +43,11 @@ func NewK8sClient() (*K8sClient, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to create kubernetes config: %v", err)
 		}
+
+		kubeContext = ""
+		if rawConfig, err := clientcmd.LoadFromFile(kubeconfig); err == nil {
+			kubeContext = rawConfig.CurrentContext
+		}
 	}
 
 	// Create controller-runtime client
@@ -57,6 +66,7 @@ func NewK8sClient() (*K8sClient, error) {
 		Client:    runtimeClient,
 		Clientset: clientset,
 		Config:    config,
+		Context:   kubeContext,
 	}, nil
 }
 
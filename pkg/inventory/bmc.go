@@ -0,0 +1,81 @@
+package inventory
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// BMCDriver describes how the baremetal-operator talks to a BMC type.
+type BMCDriver struct {
+	// Protocol is the management protocol, e.g. "ipmi" or "redfish"
+	Protocol string
+	// VirtualMedia drivers boot from an ISO image instead of PXE
+	VirtualMedia bool
+	// NeedsMAC is true for drivers that PXE boot and therefore require
+	// spec.bootMACAddress
+	NeedsMAC bool
+	// TLS is true when the driver connects to the BMC over HTTPS
+	TLS bool
+	// DefaultPort is used when the address has no port
+	DefaultPort string
+}
+
+// bmcDrivers follows the address schemes accepted by the baremetal-operator.
+var bmcDrivers = map[string]BMCDriver{
+	"ipmi":                       {Protocol: "ipmi", NeedsMAC: true, DefaultPort: "623"},
+	"libvirt":                    {Protocol: "ipmi", NeedsMAC: true, DefaultPort: "623"},
+	"redfish":                    {Protocol: "redfish", NeedsMAC: true, TLS: true, DefaultPort: "443"},
+	"redfish+https":              {Protocol: "redfish", NeedsMAC: true, TLS: true, DefaultPort: "443"},
+	"redfish+http":               {Protocol: "redfish", NeedsMAC: true, DefaultPort: "80"},
+	"redfish-virtualmedia":       {Protocol: "redfish", VirtualMedia: true, TLS: true, DefaultPort: "443"},
+	"redfish-virtualmedia+https": {Protocol: "redfish", VirtualMedia: true, TLS: true, DefaultPort: "443"},
+	"redfish-virtualmedia+http":  {Protocol: "redfish", VirtualMedia: true, DefaultPort: "80"},
+	"idrac":                      {Protocol: "wsman", NeedsMAC: true, TLS: true, DefaultPort: "443"},
+	"idrac-redfish":              {Protocol: "redfish", NeedsMAC: true, TLS: true, DefaultPort: "443"},
+	"idrac-virtualmedia":         {Protocol: "redfish", VirtualMedia: true, TLS: true, DefaultPort: "443"},
+	"ilo4":                       {Protocol: "ribcl", NeedsMAC: true, TLS: true, DefaultPort: "443"},
+	"ilo4-virtualmedia":          {Protocol: "ribcl", VirtualMedia: true, TLS: true, DefaultPort: "443"},
+	"ilo5":                       {Protocol: "ribcl", NeedsMAC: true, TLS: true, DefaultPort: "443"},
+	"ilo5-redfish":               {Protocol: "redfish", NeedsMAC: true, TLS: true, DefaultPort: "443"},
+	"ilo5-virtualmedia":          {Protocol: "ribcl", VirtualMedia: true, TLS: true, DefaultPort: "443"},
+	"irmc":                       {Protocol: "irmc", NeedsMAC: true, TLS: true, DefaultPort: "443"},
+	"irmc-virtualmedia":          {Protocol: "irmc", VirtualMedia: true, TLS: true, DefaultPort: "443"},
+}
+
+// LookupBMCDriver returns the driver for a BMC address scheme as returned
+// by BMCType.
+func LookupBMCDriver(scheme string) (BMCDriver, bool) {
+	driver, found := bmcDrivers[scheme]
+	return driver, found
+}
+
+// BMCDriverSchemes returns all supported BMC address schemes in order.
+func BMCDriverSchemes() []string {
+	schemes := make([]string, 0, len(bmcDrivers))
+	for scheme := range bmcDrivers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// BMCEndpoint splits a BMC address into host, port and path. IPMI addresses
+// may omit the scheme and are given as host or host:port.
+func BMCEndpoint(address string) (host, port, path string, err error) {
+	if !strings.Contains(address, "://") {
+		address = "ipmi://" + address
+	}
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return "", "", "", err
+	}
+	return parsed.Hostname(), parsed.Port(), parsed.Path, nil
+}
+
+// IsIPAddress reports whether a BMC host is a literal IP address rather than
+// a DNS name.
+func IsIPAddress(host string) bool {
+	return net.ParseIP(host) != nil
+}
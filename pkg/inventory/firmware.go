@@ -0,0 +1,167 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+
+	"capi-advisor/pkg/analyzer"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Drift describes a firmware component or BIOS setting that has different
+// values across the hosts of one pool.
+type Drift struct {
+	// Pool groups hosts of the same namespace and hardware model
+	Pool string `json:"pool"`
+	// Item is a firmware component such as "bios" or "bmc", or a BIOS
+	// setting prefixed with "setting:"
+	Item string `json:"item"`
+	// Values maps each value to the hosts reporting it
+	Values map[string][]string `json:"values"`
+	// Majority is the value reported by most hosts
+	Majority string `json:"majority"`
+}
+
+// Outliers returns the hosts whose value differs from the majority.
+func (d Drift) Outliers() map[string]string {
+	outliers := make(map[string]string)
+	for value, hosts := range d.Values {
+		if value == d.Majority {
+			continue
+		}
+		for _, host := range hosts {
+			outliers[host] = value
+		}
+	}
+	return outliers
+}
+
+// PoolName returns the pool a host belongs to for drift reporting, or an
+// empty string for hosts without a known hardware model.
+func (h *Host) PoolName() string {
+	if h.Model() == "" {
+		return ""
+	}
+	return h.Namespace + "/" + h.Model()
+}
+
+// FirmwareVersions returns the current version of each firmware component of
+// a host, read from its HostFirmwareComponents or, if absent, the BIOS
+// version found during inspection.
+func FirmwareVersions(host *Host, components []*analyzer.Component) map[string]string {
+	versions := make(map[string]string)
+	for _, comp := range components {
+		if comp.Type != analyzer.HostFirmwareComponentsType || comp.Name != host.Name || comp.Namespace != host.Namespace {
+			continue
+		}
+		status, _ := comp.Metadata["status"].(map[string]interface{})
+		items, _, _ := unstructured.NestedSlice(status, "components")
+		for _, item := range items {
+			itemMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := itemMap["component"].(string)
+			version, _ := itemMap["currentVersion"].(string)
+			if name != "" && version != "" {
+				versions[name] = version
+			}
+		}
+	}
+	if _, found := versions["bios"]; !found && host.BIOSVersion != "" {
+		versions["bios"] = host.BIOSVersion
+	}
+	return versions
+}
+
+// FirmwareSettings returns the applied BIOS settings of a host from the
+// status of its HostFirmwareSettings.
+func FirmwareSettings(host *Host, components []*analyzer.Component) map[string]string {
+	settings := make(map[string]string)
+	for _, comp := range components {
+		if comp.Type != analyzer.HostFirmwareSettingsType || comp.Name != host.Name || comp.Namespace != host.Namespace {
+			continue
+		}
+		status, _ := comp.Metadata["status"].(map[string]interface{})
+		values, _, _ := unstructured.NestedMap(status, "settings")
+		for name, value := range values {
+			settings[name] = fmt.Sprint(value)
+		}
+	}
+	return settings
+}
+
+// FirmwareDrift compares firmware versions and BIOS settings of all hosts in
+// the same pool and returns the items with more than one value. Settings
+// are only compared when every host of the pool reports them.
+func FirmwareDrift(hosts []*Host, components []*analyzer.Component) []Drift {
+	pools := make(map[string][]*Host)
+	for _, host := range hosts {
+		if pool := host.PoolName(); pool != "" {
+			pools[pool] = append(pools[pool], host)
+		}
+	}
+
+	var drifts []Drift
+	for pool, members := range pools {
+		if len(members) < 2 {
+			continue
+		}
+
+		versions := make(map[string]map[string][]string)
+		settings := make(map[string]map[string][]string)
+		for _, host := range members {
+			for item, version := range FirmwareVersions(host, components) {
+				addValue(versions, item, version, host.Name)
+			}
+			for name, value := range FirmwareSettings(host, components) {
+				addValue(settings, "setting:"+name, value, host.Name)
+			}
+		}
+
+		for item, values := range versions {
+			if len(values) > 1 {
+				drifts = append(drifts, newDrift(pool, item, values))
+			}
+		}
+		for item, values := range settings {
+			if len(values) > 1 && countHosts(values) == len(members) {
+				drifts = append(drifts, newDrift(pool, item, values))
+			}
+		}
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Pool != drifts[j].Pool {
+			return drifts[i].Pool < drifts[j].Pool
+		}
+		return drifts[i].Item < drifts[j].Item
+	})
+	return drifts
+}
+
+func addValue(items map[string]map[string][]string, item, value, host string) {
+	if items[item] == nil {
+		items[item] = make(map[string][]string)
+	}
+	items[item][value] = append(items[item][value], host)
+}
+
+func countHosts(values map[string][]string) int {
+	count := 0
+	for _, hosts := range values {
+		count += len(hosts)
+	}
+	return count
+}
+
+func newDrift(pool, item string, values map[string][]string) Drift {
+	drift := Drift{Pool: pool, Item: item, Values: values}
+	for value, hosts := range values {
+		sort.Strings(hosts)
+		if len(hosts) > len(values[drift.Majority]) || (len(hosts) == len(values[drift.Majority]) && value > drift.Majority) {
+			drift.Majority = value
+		}
+	}
+	return drift
+}
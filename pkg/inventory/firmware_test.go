@@ -0,0 +1,110 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	"capi-advisor/pkg/analyzer"
+)
+
+func TestNewDrift(t *testing.T) {
+	tests := []struct {
+		name         string
+		values       map[string][]string
+		wantMajority string
+	}{
+		{
+			name:         "majority",
+			values:       map[string][]string{"2.1": {"host-0", "host-2"}, "2.3": {"host-1"}},
+			wantMajority: "2.1",
+		},
+		{
+			name:         "tie",
+			values:       map[string][]string{"2.1": {"host-0"}, "2.3": {"host-1"}},
+			wantMajority: "2.3",
+		},
+		{
+			name:         "tie below the majority",
+			values:       map[string][]string{"1.0": {"host-0", "host-1", "host-2"}, "2.1": {"host-3"}, "2.3": {"host-4"}},
+			wantMajority: "1.0",
+		},
+		{
+			name:         "three way tie",
+			values:       map[string][]string{"Enabled": {"host-0"}, "Disabled": {"host-1"}, "Auto": {"host-2"}},
+			wantMajority: "Enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map iteration order is random, repeat to catch order dependence
+			for i := 0; i < 20; i++ {
+				if got := newDrift("metal3/Dell R650", "bios", tt.values); got.Majority != tt.wantMajority {
+					t.Fatalf("Majority = %q, want %q", got.Majority, tt.wantMajority)
+				}
+			}
+		})
+	}
+}
+
+func TestFirmwareDrift(t *testing.T) {
+	host := func(name, productName string) *Host {
+		return &Host{Name: name, Namespace: "metal3", Manufacturer: "Dell", ProductName: productName}
+	}
+	firmware := func(name, bios string) *analyzer.Component {
+		return &analyzer.Component{Name: name, Namespace: "metal3", Type: analyzer.HostFirmwareComponentsType, Metadata: map[string]interface{}{
+			"status": map[string]interface{}{"components": []interface{}{
+				map[string]interface{}{"component": "bios", "currentVersion": bios},
+			}},
+		}}
+	}
+	settings := func(name string, values map[string]interface{}) *analyzer.Component {
+		return &analyzer.Component{Name: name, Namespace: "metal3", Type: analyzer.HostFirmwareSettingsType, Metadata: map[string]interface{}{
+			"status": map[string]interface{}{"settings": values},
+		}}
+	}
+
+	hosts := []*Host{
+		host("host-0", "R650"),
+		host("host-1", "R650"),
+		host("host-2", "R650"),
+		// Alone in its pool
+		host("host-3", "R750"),
+		// No model, in no pool
+		{Name: "host-4", Namespace: "metal3"},
+	}
+	components := []*analyzer.Component{
+		firmware("host-0", "1.0"),
+		firmware("host-1", "1.0"),
+		firmware("host-2", "1.1"),
+		firmware("host-3", "0.9"),
+		firmware("host-4", "0.8"),
+		settings("host-0", map[string]interface{}{"BootMode": "Uefi", "SriovGlobalEnable": "Enabled", "LogicalProc": "Enabled"}),
+		settings("host-1", map[string]interface{}{"BootMode": "Bios", "SriovGlobalEnable": "Disabled", "LogicalProc": "Enabled"}),
+		// SriovGlobalEnable is not reported by every host and not compared
+		settings("host-2", map[string]interface{}{"BootMode": "Uefi", "LogicalProc": "Enabled"}),
+		settings("host-3", map[string]interface{}{"BootMode": "Bios"}),
+	}
+
+	want := []Drift{
+		{
+			Pool:     "metal3/Dell R650",
+			Item:     "bios",
+			Values:   map[string][]string{"1.0": {"host-0", "host-1"}, "1.1": {"host-2"}},
+			Majority: "1.0",
+		},
+		{
+			Pool:     "metal3/Dell R650",
+			Item:     "setting:BootMode",
+			Values:   map[string][]string{"Uefi": {"host-0", "host-2"}, "Bios": {"host-1"}},
+			Majority: "Uefi",
+		},
+	}
+	got := FirmwareDrift(hosts, components)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("FirmwareDrift() = %+v, want %+v", got, want)
+	}
+	if outliers := got[1].Outliers(); !reflect.DeepEqual(outliers, map[string]string{"host-1": "Bios"}) {
+		t.Errorf("Outliers() = %v, want host-1 with Bios", outliers)
+	}
+}
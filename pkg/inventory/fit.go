@@ -0,0 +1,296 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// Fit explains which available hosts an unbound Metal3Machine could claim.
+type Fit struct {
+	Machine   *analyzer.Component `json:"-"`
+	Available int                 `json:"available"`
+	Matching  []*Host             `json:"-"`
+	Criteria  []Criterion         `json:"criteria"`
+	// Errors lists hostSelector requirements that could not be parsed
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Criterion is a single requirement and the number of available hosts
+// satisfying it.
+type Criterion struct {
+	Description string `json:"description"`
+	Matching    int    `json:"matching"`
+}
+
+// UnboundMachines returns all Metal3Machines that are not associated with a
+// BareMetalHost.
+func UnboundMachines(components []*analyzer.Component) []*analyzer.Component {
+	consumed := make(map[string]bool)
+	for _, comp := range components {
+		if comp.Type == analyzer.BareMetalHostType {
+			if kind, namespace, name := analyzer.HostConsumer(comp); kind == string(analyzer.Metal3MachineType) {
+				consumed[namespace+"/"+name] = true
+			}
+		}
+	}
+
+	var unbound []*analyzer.Component
+	for _, comp := range components {
+		if comp.Type != analyzer.Metal3MachineType || consumed[comp.Namespace+"/"+comp.Name] {
+			continue
+		}
+		if _, name := analyzer.AnnotatedHost(comp); name != "" {
+			continue
+		}
+		unbound = append(unbound, comp)
+	}
+
+	sort.Slice(unbound, func(i, j int) bool {
+		if unbound[i].Namespace != unbound[j].Namespace {
+			return unbound[i].Namespace < unbound[j].Namespace
+		}
+		return unbound[i].Name < unbound[j].Name
+	})
+	return unbound
+}
+
+// CheckFit matches the hostSelector of a Metal3Machine and the root device
+// hints of each host against all available hosts in the machine's namespace.
+func CheckFit(machine *analyzer.Component, hosts []*Host) Fit {
+	fit := Fit{Machine: machine}
+
+	var candidates []*Host
+	for _, host := range hosts {
+		if host.Namespace == machine.Namespace && host.Available() {
+			candidates = append(candidates, host)
+		}
+	}
+	fit.Available = len(candidates)
+
+	requirements, errs := hostSelectorRequirements(machine)
+	fit.Errors = errs
+
+	matchesAll := make([]bool, len(candidates))
+	for i := range matchesAll {
+		matchesAll[i] = true
+	}
+
+	for _, req := range requirements {
+		criterion := Criterion{Description: "label " + req.String()}
+		for i, host := range candidates {
+			if req.Matches(labels.Set(host.Labels)) {
+				criterion.Matching++
+			} else {
+				matchesAll[i] = false
+			}
+		}
+		fit.Criteria = append(fit.Criteria, criterion)
+	}
+
+	hintsUsed := false
+	hints := Criterion{Description: "a disk matching its root device hints"}
+	hintDescriptions := make(map[string]bool)
+	for i, host := range candidates {
+		if len(host.RootDeviceHints) > 0 {
+			hintsUsed = true
+			hintDescriptions[describeHints(host.RootDeviceHints)] = true
+		}
+		if host.MatchesRootDeviceHints() {
+			hints.Matching++
+		} else {
+			matchesAll[i] = false
+		}
+	}
+	if hintsUsed {
+		// Name the hints when all hosts use the same ones
+		if len(hintDescriptions) == 1 {
+			for description := range hintDescriptions {
+				hints.Description = "a disk matching " + description
+			}
+		}
+		fit.Criteria = append(fit.Criteria, hints)
+	}
+
+	for i, host := range candidates {
+		if matchesAll[i] {
+			fit.Matching = append(fit.Matching, host)
+		}
+	}
+
+	return fit
+}
+
+// Summary renders the fit in a single line, e.g.
+// "0 of 12 available hosts match (label rack=b: 3 of 12, ...)".
+func (f Fit) Summary() string {
+	summary := fmt.Sprintf("%d of %d available hosts match", len(f.Matching), f.Available)
+	if len(f.Criteria) == 0 {
+		return summary
+	}
+
+	var parts []string
+	for _, c := range f.Criteria {
+		parts = append(parts, fmt.Sprintf("%s: %d of %d", c.Description, c.Matching, f.Available))
+	}
+	return fmt.Sprintf("%s (%s)", summary, strings.Join(parts, ", "))
+}
+
+// MatchesRootDeviceHints reports whether any disk satisfies all root device
+// hints of the host. Hosts without hints or without an inventory match.
+func (h *Host) MatchesRootDeviceHints() bool {
+	if len(h.RootDeviceHints) == 0 || !h.HasInventory {
+		return true
+	}
+	for _, disk := range h.Disks {
+		if disk.matchesHints(h.RootDeviceHints) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesHints follows the baremetal-operator translation of root device
+// hints: model and vendor match substrings, other fields match exactly.
+func (d Disk) matchesHints(hints map[string]interface{}) bool {
+	for key, value := range hints {
+		switch key {
+		case "deviceName":
+			name, _ := value.(string)
+			if name != d.Name && !containsString(d.AlternateNames, name) {
+				return false
+			}
+		case "hctl":
+			if value != d.HCTL {
+				return false
+			}
+		case "model":
+			if s, _ := value.(string); !strings.Contains(d.Model, s) {
+				return false
+			}
+		case "vendor":
+			if s, _ := value.(string); !strings.Contains(d.Vendor, s) {
+				return false
+			}
+		case "serialNumber":
+			if value != d.SerialNumber {
+				return false
+			}
+		case "wwn":
+			if value != d.WWN {
+				return false
+			}
+		case "wwnWithExtension":
+			if value != d.WWNWithExt {
+				return false
+			}
+		case "wwnVendorExtension":
+			if value != d.WWNVendorExt {
+				return false
+			}
+		case "rotational":
+			if rotational, ok := value.(bool); ok && rotational != d.Rotational {
+				return false
+			}
+		case "minSizeGigabytes":
+			minSize := int64Field(hints, key)
+			if d.SizeBytes < minSize*(1<<30) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// describeHints renders root device hints as "key=value" pairs in a stable
+// order, e.g. "rotational=false, size>=500Gi".
+func describeHints(hints map[string]interface{}) string {
+	keys := make([]string, 0, len(hints))
+	for key := range hints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		if key == "minSizeGigabytes" {
+			parts = append(parts, fmt.Sprintf("size>=%vGi", hints[key]))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%v", key, hints[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// hostSelectorRequirements converts spec.hostSelector of a Metal3Machine into
+// label requirements.
+func hostSelectorRequirements(machine *analyzer.Component) ([]labels.Requirement, []string) {
+	spec, ok := machine.Metadata["spec"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	var requirements []labels.Requirement
+	var errs []string
+
+	matchLabels, _, _ := unstructured.NestedStringMap(spec, "hostSelector", "matchLabels")
+	keys := make([]string, 0, len(matchLabels))
+	for key := range matchLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		req, err := labels.NewRequirement(key, selection.Equals, []string{matchLabels[key]})
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		requirements = append(requirements, *req)
+	}
+
+	expressions, _, _ := unstructured.NestedSlice(spec, "hostSelector", "matchExpressions")
+	for _, expr := range expressions {
+		exprMap, ok := expr.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key, _ := exprMap["key"].(string)
+		operator, _ := exprMap["operator"].(string)
+		values, _, _ := unstructured.NestedStringSlice(exprMap, "values")
+		req, err := labels.NewRequirement(key, selectorOperator(operator), values)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		requirements = append(requirements, *req)
+	}
+
+	return requirements, errs
+}
+
+// selectorOperator maps label selector operators in either spelling
+// ("In", "in", "DoesNotExist", "!") to a selection.Operator.
+func selectorOperator(operator string) selection.Operator {
+	switch strings.ToLower(operator) {
+	case "doesnotexist":
+		return selection.DoesNotExist
+	case "notin":
+		return selection.NotIn
+	default:
+		return selection.Operator(strings.ToLower(operator))
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
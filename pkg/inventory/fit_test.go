@@ -0,0 +1,152 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	"capi-advisor/pkg/analyzer"
+)
+
+func testHosts() []*Host {
+	ssd := Disk{Name: "/dev/sda", SizeBytes: 960 << 30, Model: "SAMSUNG MZ7L3960"}
+	hdd := Disk{Name: "/dev/sda", SizeBytes: 4000 << 30, Rotational: true}
+	return []*Host{
+		{Name: "host-0", Namespace: "metal3", ProvisioningState: "available", Labels: map[string]string{"rack": "a"}, HasInventory: true, Disks: []Disk{ssd}},
+		{Name: "host-1", Namespace: "metal3", ProvisioningState: "available", Labels: map[string]string{"rack": "b"}, HasInventory: true, Disks: []Disk{hdd},
+			RootDeviceHints: map[string]interface{}{"rotational": false}},
+		{Name: "host-2", Namespace: "metal3", ProvisioningState: "ready", Labels: map[string]string{"rack": "b", "gpu": "true"}, HasInventory: true, Disks: []Disk{ssd},
+			RootDeviceHints: map[string]interface{}{"rotational": false}},
+		// Not available: consumed, provisioning failed, in another namespace
+		{Name: "host-3", Namespace: "metal3", ProvisioningState: "provisioned", Consumer: "metal3/worker-9", Labels: map[string]string{"rack": "b"}},
+		{Name: "host-4", Namespace: "metal3", ProvisioningState: "available", ErrorMessage: "inspection failed", Labels: map[string]string{"rack": "b"}},
+		{Name: "host-5", Namespace: "other", ProvisioningState: "available", Labels: map[string]string{"rack": "b"}},
+	}
+}
+
+func testMachine(hostSelector map[string]interface{}) *analyzer.Component {
+	spec := map[string]interface{}{}
+	if hostSelector != nil {
+		spec["hostSelector"] = hostSelector
+	}
+	return &analyzer.Component{
+		Name:      "worker-0",
+		Namespace: "metal3",
+		Type:      analyzer.Metal3MachineType,
+		Metadata:  map[string]interface{}{"spec": spec},
+	}
+}
+
+func TestCheckFit(t *testing.T) {
+	tests := []struct {
+		name         string
+		hostSelector map[string]interface{}
+		wantMatching []string
+		wantCriteria []Criterion
+		wantErrors   int
+	}{
+		{
+			name:         "no host selector",
+			wantMatching: []string{"host-0", "host-2"},
+			wantCriteria: []Criterion{{Description: "a disk matching rotational=false", Matching: 2}},
+		},
+		{
+			name:         "match labels",
+			hostSelector: map[string]interface{}{"matchLabels": map[string]interface{}{"rack": "b"}},
+			wantMatching: []string{"host-2"},
+			wantCriteria: []Criterion{
+				{Description: "label rack=b", Matching: 2},
+				{Description: "a disk matching rotational=false", Matching: 2},
+			},
+		},
+		{
+			name: "match expressions",
+			hostSelector: map[string]interface{}{"matchExpressions": []interface{}{
+				map[string]interface{}{"key": "rack", "operator": "In", "values": []interface{}{"a", "b"}},
+				map[string]interface{}{"key": "gpu", "operator": "DoesNotExist"},
+			}},
+			wantMatching: []string{"host-0"},
+			wantCriteria: []Criterion{
+				{Description: "label rack in (a,b)", Matching: 3},
+				{Description: "label !gpu", Matching: 2},
+				{Description: "a disk matching rotational=false", Matching: 2},
+			},
+		},
+		{
+			name:         "no host matches",
+			hostSelector: map[string]interface{}{"matchLabels": map[string]interface{}{"rack": "c"}},
+			wantCriteria: []Criterion{
+				{Description: "label rack=c", Matching: 0},
+				{Description: "a disk matching rotational=false", Matching: 2},
+			},
+		},
+		{
+			name: "invalid requirement",
+			hostSelector: map[string]interface{}{"matchExpressions": []interface{}{
+				map[string]interface{}{"key": "rack", "operator": "In"},
+			}},
+			wantMatching: []string{"host-0", "host-2"},
+			wantCriteria: []Criterion{{Description: "a disk matching rotational=false", Matching: 2}},
+			wantErrors:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fit := CheckFit(testMachine(tt.hostSelector), testHosts())
+
+			if fit.Available != 3 {
+				t.Errorf("Available = %d, want 3", fit.Available)
+			}
+			var matching []string
+			for _, host := range fit.Matching {
+				matching = append(matching, host.Name)
+			}
+			if !reflect.DeepEqual(matching, tt.wantMatching) {
+				t.Errorf("Matching = %v, want %v", matching, tt.wantMatching)
+			}
+			if !reflect.DeepEqual(fit.Criteria, tt.wantCriteria) {
+				t.Errorf("Criteria = %+v, want %+v", fit.Criteria, tt.wantCriteria)
+			}
+			if len(fit.Errors) != tt.wantErrors {
+				t.Errorf("Errors = %v, want %d", fit.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestFitSummary(t *testing.T) {
+	fit := CheckFit(testMachine(map[string]interface{}{"matchLabels": map[string]interface{}{"rack": "b"}}), testHosts())
+	want := "1 of 3 available hosts match (label rack=b: 2 of 3, a disk matching rotational=false: 2 of 3)"
+	if got := fit.Summary(); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func TestMatchesRootDeviceHints(t *testing.T) {
+	disk := Disk{Name: "/dev/sdb", AlternateNames: []string{"/dev/disk/by-path/pci-0"}, SizeBytes: 480 << 30, Model: "SAMSUNG MZ7L3480", Vendor: "ATA", SerialNumber: "S1"}
+	tests := []struct {
+		name         string
+		hints        map[string]interface{}
+		hasInventory bool
+		want         bool
+	}{
+		{name: "no hints", hasInventory: true, want: true},
+		{name: "not inspected", hints: map[string]interface{}{"serialNumber": "S2"}, want: true},
+		{name: "device name", hints: map[string]interface{}{"deviceName": "/dev/sdb"}, hasInventory: true, want: true},
+		{name: "alternate device name", hints: map[string]interface{}{"deviceName": "/dev/disk/by-path/pci-0"}, hasInventory: true, want: true},
+		{name: "model substring", hints: map[string]interface{}{"model": "MZ7L3"}, hasInventory: true, want: true},
+		{name: "serial number mismatch", hints: map[string]interface{}{"serialNumber": "S2"}, hasInventory: true, want: false},
+		{name: "minimum size met", hints: map[string]interface{}{"minSizeGigabytes": int64(400)}, hasInventory: true, want: true},
+		{name: "minimum size not met", hints: map[string]interface{}{"minSizeGigabytes": int64(500)}, hasInventory: true, want: false},
+		{name: "rotational mismatch", hints: map[string]interface{}{"rotational": true}, hasInventory: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host := &Host{HasInventory: tt.hasInventory, Disks: []Disk{disk}, RootDeviceHints: tt.hints}
+			if got := host.MatchesRootDeviceHints(); got != tt.want {
+				t.Errorf("MatchesRootDeviceHints() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
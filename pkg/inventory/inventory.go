@@ -0,0 +1,245 @@
+package inventory
+
+import (
+	"sort"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Host is the hardware inventory and provisioning state of a BareMetalHost.
+type Host struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	ProvisioningState string            `json:"provisioningState"`
+	OperationalStatus string            `json:"operationalStatus,omitempty"`
+	ErrorMessage      string            `json:"errorMessage,omitempty"`
+	Online            bool              `json:"online"`
+	PoweredOn         bool              `json:"poweredOn"`
+	Consumer          string            `json:"consumer,omitempty"`
+	BMCAddress        string            `json:"bmcAddress,omitempty"`
+	BMCType           string            `json:"bmcType,omitempty"`
+	BootMACAddress    string            `json:"bootMACAddress,omitempty"`
+
+	// BMCCredentialsName is the Secret holding the BMC username and password
+	BMCCredentialsName             string `json:"bmcCredentialsName,omitempty"`
+	DisableCertificateVerification bool   `json:"disableCertificateVerification,omitempty"`
+
+	// HasInventory is false until the host was inspected
+	HasInventory bool   `json:"hasInventory"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+	ProductName  string `json:"productName,omitempty"`
+	CPUArch      string `json:"cpuArch,omitempty"`
+	CPUModel     string `json:"cpuModel,omitempty"`
+	CPUCount     int64  `json:"cpuCount,omitempty"`
+	RAMMebibytes int64  `json:"ramMebibytes,omitempty"`
+	Disks        []Disk `json:"disks,omitempty"`
+	NICs         []NIC  `json:"nics,omitempty"`
+	BIOSVendor   string `json:"biosVendor,omitempty"`
+	BIOSVersion  string `json:"biosVersion,omitempty"`
+
+	RootDeviceHints map[string]interface{} `json:"rootDeviceHints,omitempty"`
+	Component       *analyzer.Component    `json:"-"`
+}
+
+// Disk is a storage device found during inspection.
+type Disk struct {
+	Name           string   `json:"name"`
+	AlternateNames []string `json:"alternateNames,omitempty"`
+	SizeBytes      int64    `json:"sizeBytes"`
+	Rotational     bool     `json:"rotational"`
+	Type           string   `json:"type,omitempty"`
+	Model          string   `json:"model,omitempty"`
+	Vendor         string   `json:"vendor,omitempty"`
+	SerialNumber   string   `json:"serialNumber,omitempty"`
+	HCTL           string   `json:"hctl,omitempty"`
+	WWN            string   `json:"wwn,omitempty"`
+	WWNWithExt     string   `json:"wwnWithExtension,omitempty"`
+	WWNVendorExt   string   `json:"wwnVendorExtension,omitempty"`
+}
+
+// NIC is a network interface found during inspection.
+type NIC struct {
+	Name      string `json:"name"`
+	MAC       string `json:"mac"`
+	IP        string `json:"ip,omitempty"`
+	SpeedGbps int64  `json:"speedGbps,omitempty"`
+	PXE       bool   `json:"pxe,omitempty"`
+}
+
+// BuildInventory collects the hardware inventory of all BareMetalHosts. The
+// inventory is read from status.hardware, falling back to the HardwareData
+// object of the same name on newer baremetal-operator releases.
+func BuildInventory(components []*analyzer.Component) []*Host {
+	hardwareData := make(map[string]map[string]interface{})
+	for _, comp := range components {
+		if comp.Type == analyzer.HardwareDataType {
+			if spec, ok := comp.Metadata["spec"].(map[string]interface{}); ok {
+				if hardware, found, _ := unstructured.NestedMap(spec, "hardware"); found {
+					hardwareData[comp.Namespace+"/"+comp.Name] = hardware
+				}
+			}
+		}
+	}
+
+	var hosts []*Host
+	for _, comp := range components {
+		if comp.Type != analyzer.BareMetalHostType {
+			continue
+		}
+		host := newHost(comp)
+
+		status, _ := comp.Metadata["status"].(map[string]interface{})
+		hardware, found, _ := unstructured.NestedMap(status, "hardware")
+		if !found {
+			hardware, found = hardwareData[comp.Namespace+"/"+comp.Name]
+		}
+		if found {
+			host.setHardware(hardware)
+		}
+		hosts = append(hosts, host)
+	}
+
+	sort.Slice(hosts, func(i, j int) bool {
+		if hosts[i].Namespace != hosts[j].Namespace {
+			return hosts[i].Namespace < hosts[j].Namespace
+		}
+		return hosts[i].Name < hosts[j].Name
+	})
+	return hosts
+}
+
+// Available reports whether the host can be claimed by a Metal3Machine.
+func (h *Host) Available() bool {
+	if h.Consumer != "" || h.ErrorMessage != "" {
+		return false
+	}
+	return h.ProvisioningState == "available" || h.ProvisioningState == "ready"
+}
+
+// Model returns the manufacturer and product name of the host.
+func (h *Host) Model() string {
+	return strings.TrimSpace(h.Manufacturer + " " + h.ProductName)
+}
+
+// TotalDiskBytes returns the sum of all disk sizes.
+func (h *Host) TotalDiskBytes() int64 {
+	var total int64
+	for _, disk := range h.Disks {
+		total += disk.SizeBytes
+	}
+	return total
+}
+
+// BMCType returns the driver scheme of a BMC address, e.g. "ipmi" or
+// "redfish-virtualmedia". Addresses without a scheme use IPMI.
+func BMCType(address string) string {
+	if address == "" {
+		return ""
+	}
+	if scheme, _, found := strings.Cut(address, "://"); found {
+		return strings.ToLower(scheme)
+	}
+	return "ipmi"
+}
+
+func newHost(comp *analyzer.Component) *Host {
+	host := &Host{
+		Name:      comp.Name,
+		Namespace: comp.Namespace,
+		Component: comp,
+	}
+	host.Labels, _ = comp.Metadata["labels"].(map[string]string)
+
+	if spec, ok := comp.Metadata["spec"].(map[string]interface{}); ok {
+		host.Online, _, _ = unstructured.NestedBool(spec, "online")
+		host.BMCAddress, _, _ = unstructured.NestedString(spec, "bmc", "address")
+		host.BMCCredentialsName, _, _ = unstructured.NestedString(spec, "bmc", "credentialsName")
+		host.DisableCertificateVerification, _, _ = unstructured.NestedBool(spec, "bmc", "disableCertificateVerification")
+		host.BootMACAddress, _, _ = unstructured.NestedString(spec, "bootMACAddress")
+		host.RootDeviceHints, _, _ = unstructured.NestedMap(spec, "rootDeviceHints")
+	}
+	host.BMCType = BMCType(host.BMCAddress)
+
+	if kind, namespace, name := analyzer.HostConsumer(comp); name != "" {
+		host.Consumer = kind + "/" + name
+		if namespace != comp.Namespace {
+			host.Consumer = kind + "/" + namespace + "/" + name
+		}
+	}
+
+	if status, ok := comp.Metadata["status"].(map[string]interface{}); ok {
+		host.ProvisioningState, _, _ = unstructured.NestedString(status, "provisioning", "state")
+		host.OperationalStatus, _, _ = unstructured.NestedString(status, "operationalStatus")
+		host.ErrorMessage, _, _ = unstructured.NestedString(status, "errorMessage")
+		host.PoweredOn, _, _ = unstructured.NestedBool(status, "poweredOn")
+	}
+
+	return host
+}
+
+func (h *Host) setHardware(hardware map[string]interface{}) {
+	h.HasInventory = true
+	h.Manufacturer, _, _ = unstructured.NestedString(hardware, "systemVendor", "manufacturer")
+	h.ProductName, _, _ = unstructured.NestedString(hardware, "systemVendor", "productName")
+	h.CPUArch, _, _ = unstructured.NestedString(hardware, "cpu", "arch")
+	h.CPUModel, _, _ = unstructured.NestedString(hardware, "cpu", "model")
+	h.CPUCount = int64Field(hardware, "cpu", "count")
+	h.RAMMebibytes = int64Field(hardware, "ramMebibytes")
+	h.BIOSVendor, _, _ = unstructured.NestedString(hardware, "firmware", "bios", "vendor")
+	h.BIOSVersion, _, _ = unstructured.NestedString(hardware, "firmware", "bios", "version")
+
+	storage, _, _ := unstructured.NestedSlice(hardware, "storage")
+	for _, item := range storage {
+		diskMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		disk := Disk{SizeBytes: int64Field(diskMap, "sizeBytes")}
+		disk.Name, _ = diskMap["name"].(string)
+		disk.AlternateNames, _, _ = unstructured.NestedStringSlice(diskMap, "alternateNames")
+		disk.Rotational, _ = diskMap["rotational"].(bool)
+		disk.Type, _ = diskMap["type"].(string)
+		disk.Model, _ = diskMap["model"].(string)
+		disk.Vendor, _ = diskMap["vendor"].(string)
+		disk.SerialNumber, _ = diskMap["serialNumber"].(string)
+		disk.HCTL, _ = diskMap["hctl"].(string)
+		disk.WWN, _ = diskMap["wwn"].(string)
+		disk.WWNWithExt, _ = diskMap["wwnWithExtension"].(string)
+		disk.WWNVendorExt, _ = diskMap["wwnVendorExtension"].(string)
+		h.Disks = append(h.Disks, disk)
+	}
+
+	nics, _, _ := unstructured.NestedSlice(hardware, "nics")
+	for _, item := range nics {
+		nicMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		nic := NIC{SpeedGbps: int64Field(nicMap, "speedGbps")}
+		nic.Name, _ = nicMap["name"].(string)
+		nic.MAC, _ = nicMap["mac"].(string)
+		nic.IP, _ = nicMap["ip"].(string)
+		nic.PXE, _ = nicMap["pxe"].(bool)
+		h.NICs = append(h.NICs, nic)
+	}
+}
+
+// int64Field reads a number that may have been decoded as int64 or float64.
+func int64Field(obj map[string]interface{}, fields ...string) int64 {
+	value, found, _ := unstructured.NestedFieldNoCopy(obj, fields...)
+	if !found {
+		return 0
+	}
+	switch v := value.(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	case int:
+		return int64(v)
+	}
+	return 0
+}
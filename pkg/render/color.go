@@ -0,0 +1,59 @@
+package render
+
+import (
+	"capi-advisor/pkg/analyzer"
+)
+
+// ANSI escape sequences of the colours used.
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiBlue   = "\033[34m"
+	ansiCyan   = "\033[36m"
+	ansiGray   = "\033[90m"
+)
+
+func colorize(color, s string) string {
+	if !current.Color || s == "" {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+// Severity colours s by an issue severity.
+func Severity(severity analyzer.ConditionSeverity, s string) string {
+	switch severity {
+	case analyzer.SeverityCritical:
+		return colorize(ansiBold+ansiRed, s)
+	case analyzer.SeverityWarning:
+		return colorize(ansiYellow, s)
+	case analyzer.SeverityInfo:
+		return colorize(ansiBlue, s)
+	default:
+		return s
+	}
+}
+
+// Status colours s by a component status.
+func Status(status analyzer.ComponentStatus, s string) string {
+	switch status {
+	case analyzer.StatusHealthy:
+		return colorize(ansiGreen, s)
+	case analyzer.StatusDegraded:
+		return colorize(ansiYellow, s)
+	case analyzer.StatusFailed:
+		return colorize(ansiRed, s)
+	case analyzer.StatusPending:
+		return colorize(ansiCyan, s)
+	default:
+		return colorize(ansiGray, s)
+	}
+}
+
+// Heading renders a section heading in bold.
+func Heading(s string) string {
+	return colorize(ansiBold, s)
+}
@@ -0,0 +1,115 @@
+package render
+
+import (
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// icon is an emoji and the plain word it is rendered as without emoji.
+// Decorations have no plain word and are dropped.
+type icon struct {
+	emoji string
+	plain string
+}
+
+var icons = []icon{
+	// Statuses and severities
+	{"✅", "[OK]"},
+	{"⚠️", "[WARN]"},
+	{"❌", "[FAIL]"},
+	{"⏳", "[PENDING]"},
+	{"❓", "[UNKNOWN]"},
+	{"🔴", "[CRITICAL]"},
+	{"🟡", "[WARNING]"},
+	{"🔵", "[INFO]"},
+	{"⚪", "[-]"},
+	{"ℹ️", "[INFO]"},
+	{"🚫", "[FORBIDDEN]"},
+	{"⏱️", "[TIMEOUT]"},
+	{"✓", "+"},
+	{"✗", "-"},
+	// Decorations
+	{"🔍", ""},
+	{"🔎", ""},
+	{"🔬", ""},
+	{"🌳", ""},
+	{"📊", ""},
+	{"🏥", ""},
+	{"📡", ""},
+	{"🔗", ""},
+	{"💡", ""},
+	{"📍", ""},
+	{"📝", ""},
+	{"🎉", ""},
+	{"🚨", ""},
+	{"🔐", ""},
+	{"🖥️", ""},
+	{"🔧", ""},
+	{"📋", ""},
+	{"📰", ""},
+	{"📚", ""},
+	{"💥", ""},
+}
+
+// StatusIcon returns the icon of a component status.
+func StatusIcon(status analyzer.ComponentStatus) string {
+	switch status {
+	case analyzer.StatusHealthy:
+		return Text("✅")
+	case analyzer.StatusDegraded:
+		return Text("⚠️")
+	case analyzer.StatusFailed:
+		return Text("❌")
+	case analyzer.StatusPending:
+		return Text("⏳")
+	default:
+		return Text("❓")
+	}
+}
+
+// SeverityIcon returns the icon of an issue severity.
+func SeverityIcon(severity analyzer.ConditionSeverity) string {
+	switch severity {
+	case analyzer.SeverityCritical:
+		return Text("🔴")
+	case analyzer.SeverityWarning:
+		return Text("🟡")
+	case analyzer.SeverityInfo:
+		return Text("🔵")
+	default:
+		return Text("⚪")
+	}
+}
+
+// ConditionIcon returns the icon of a condition status.
+func ConditionIcon(status metav1.ConditionStatus) string {
+	switch status {
+	case metav1.ConditionTrue:
+		return Text("✓")
+	case metav1.ConditionFalse:
+		return Text("✗")
+	default:
+		return "?"
+	}
+}
+
+// DiscoveryIcon returns the icon of a kind that could not be listed.
+func DiscoveryIcon(outcome analyzer.DiscoveryOutcome) string {
+	switch outcome {
+	case analyzer.DiscoveryForbidden:
+		return Text("🚫")
+	case analyzer.DiscoveryTimeout:
+		return Text("⏱️")
+	default:
+		return Text("❌")
+	}
+}
+
+// Resolution renders the resolution of an issue, marking the guidance
+// specific to its condition with an icon.
+func Resolution(s string) string {
+	return strings.ReplaceAll(s, "\n"+analyzer.GuidanceHeading, "\n"+Text("💡 ")+analyzer.GuidanceHeading)
+}
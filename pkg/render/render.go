@@ -0,0 +1,142 @@
+// Package render decides how text output looks on the terminal: whether
+// icons are emoji or plain words, whether severities and statuses are
+// coloured, and how long lines wrap. All commands render through it, so the
+// output of log pipelines and serial consoles can be kept to plain ASCII.
+package render
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Colour modes of the --color flag.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Style is how text output is rendered.
+type Style struct {
+	// Emoji renders icons as emoji, otherwise as plain words
+	Emoji bool
+	// Color renders severities and statuses with ANSI colours
+	Color bool
+	// Width wraps long text at this many columns, 0 disables wrapping
+	Width int
+
+	// mode is the colour mode the style was detected with, writers to other
+	// files decide on colours by it too
+	mode string
+}
+
+var current = Style{Emoji: true}
+
+// Configure sets the style of all text output.
+func Configure(style Style) {
+	current = style
+}
+
+// Current returns the style of text output.
+func Current() Style {
+	return current
+}
+
+// Detect returns the style for writing to out. Colours follow mode: with
+// ColorAuto they are used when out is a terminal and NO_COLOR is not set.
+// Text wraps at the terminal width, or at $COLUMNS when out is no terminal
+// and the variable is set.
+func Detect(out *os.File, mode string, emoji bool) Style {
+	style := Style{Emoji: emoji, Color: colorEnabled(out, mode), mode: mode}
+
+	if term.IsTerminal(int(out.Fd())) {
+		if width, _, err := term.GetSize(int(out.Fd())); err == nil {
+			style.Width = width
+		}
+	} else if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		style.Width = columns
+	}
+	return style
+}
+
+// colorEnabled reports whether colours are used when writing to out.
+func colorEnabled(out *os.File, mode string) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	default:
+		_, noColor := os.LookupEnv("NO_COLOR")
+		return term.IsTerminal(int(out.Fd())) && !noColor && os.Getenv("TERM") != "dumb"
+	}
+}
+
+// writer renders the text written to it in the current style.
+type writer struct {
+	out io.Writer
+	// color is false when out takes no colours although the current style
+	// has them, e.g. stderr redirected to a file while stdout is a terminal
+	color bool
+}
+
+// NewWriter returns a writer that replaces the emoji in the text written to
+// it with plain words unless the current style renders emoji. A writer to a
+// file decides on colours by the file: it drops the colours of the current
+// style when the file is no terminal.
+func NewWriter(out io.Writer) io.Writer {
+	w := &writer{out: out, color: true}
+	if f, ok := out.(*os.File); ok && current.mode != "" {
+		w.color = colorEnabled(f, current.mode)
+	}
+	return w
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if current.Emoji && (w.color || !current.Color) {
+		return w.out.Write(p)
+	}
+	text := Text(string(p))
+	if current.Color && !w.color {
+		text = ansiPattern.ReplaceAllString(text, "")
+	}
+	if _, err := io.WriteString(w.out, text); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Text renders the emoji in s in the current style.
+func Text(s string) string {
+	if current.Emoji {
+		return s
+	}
+	return replacer.Replace(s)
+}
+
+var replacer = newReplacer()
+
+// ansiPattern matches the colour sequences of colorize.
+var ansiPattern = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// newReplacer replaces every emoji used in output with its plain word, or
+// drops it with its trailing spaces when it is only decoration.
+func newReplacer() *strings.Replacer {
+	var pairs []string
+	for _, icon := range icons {
+		if icon.plain == "" {
+			// Drop the spacing after a decoration too, longest first
+			pairs = append(pairs, icon.emoji+"  ", "", icon.emoji+" ", "")
+		} else {
+			// Emoji are followed by two spaces where they render narrow
+			pairs = append(pairs, icon.emoji+"  ", icon.plain+" ")
+		}
+		pairs = append(pairs, icon.emoji, icon.plain)
+	}
+	return strings.NewReplacer(pairs...)
+}
@@ -0,0 +1,155 @@
+package render
+
+import (
+	"strings"
+	"unicode"
+)
+
+// hangingIndent indents the continuation of a wrapped line past its
+// numbering, e.g. "1. ".
+const hangingIndent = "   "
+
+// Wrap returns s after prefix with the lines of s broken at spaces to fit
+// the width of the current style. Continuation lines of the first line start
+// with indent, those of later lines with their own indentation and a hanging
+// indent. Words longer than a line are not broken.
+func Wrap(prefix, s, indent string) string {
+	if current.Width <= 0 {
+		return prefix + s
+	}
+	offset := columns(Text(prefix))
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		column := 0
+		continuation := indent
+		if i == 0 {
+			column = offset
+		} else {
+			continuation = line[:len(line)-len(strings.TrimLeft(line, " "))] + hangingIndent
+		}
+		lines[i] = wrapLine(line, column, continuation, current.Width)
+	}
+	return prefix + strings.Join(lines, "\n")
+}
+
+// wide holds the runes terminals draw two columns wide: East Asian Wide
+// characters and emoji presented as emoji by default, such as the status
+// icons ✅, ❌, ⏳ and ❓. Symbols like ⚠ are one column unless followed by
+// the emoji variation selector.
+var wide = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x1100, Hi: 0x115F, Stride: 1},
+		{Lo: 0x231A, Hi: 0x231B, Stride: 1},
+		{Lo: 0x23E9, Hi: 0x23EC, Stride: 1},
+		{Lo: 0x23F0, Hi: 0x23F3, Stride: 3},
+		{Lo: 0x25FD, Hi: 0x25FE, Stride: 1},
+		{Lo: 0x2614, Hi: 0x2615, Stride: 1},
+		{Lo: 0x2648, Hi: 0x2653, Stride: 1},
+		{Lo: 0x267F, Hi: 0x267F, Stride: 1},
+		{Lo: 0x2693, Hi: 0x2693, Stride: 1},
+		{Lo: 0x26A1, Hi: 0x26A1, Stride: 1},
+		{Lo: 0x26AA, Hi: 0x26AB, Stride: 1},
+		{Lo: 0x26BD, Hi: 0x26BE, Stride: 1},
+		{Lo: 0x26C4, Hi: 0x26C5, Stride: 1},
+		{Lo: 0x26CE, Hi: 0x26CE, Stride: 1},
+		{Lo: 0x26D4, Hi: 0x26D4, Stride: 1},
+		{Lo: 0x26EA, Hi: 0x26EA, Stride: 1},
+		{Lo: 0x26F2, Hi: 0x26F3, Stride: 1},
+		{Lo: 0x26F5, Hi: 0x26F5, Stride: 1},
+		{Lo: 0x26FA, Hi: 0x26FA, Stride: 1},
+		{Lo: 0x26FD, Hi: 0x26FD, Stride: 1},
+		{Lo: 0x2705, Hi: 0x2705, Stride: 1},
+		{Lo: 0x270A, Hi: 0x270B, Stride: 1},
+		{Lo: 0x2728, Hi: 0x2728, Stride: 1},
+		{Lo: 0x274C, Hi: 0x274E, Stride: 2},
+		{Lo: 0x2753, Hi: 0x2755, Stride: 1},
+		{Lo: 0x2757, Hi: 0x2757, Stride: 1},
+		{Lo: 0x2795, Hi: 0x2797, Stride: 1},
+		{Lo: 0x27B0, Hi: 0x27BF, Stride: 15},
+		{Lo: 0x2B1B, Hi: 0x2B1C, Stride: 1},
+		{Lo: 0x2B50, Hi: 0x2B55, Stride: 5},
+		{Lo: 0x2E80, Hi: 0x303E, Stride: 1},
+		{Lo: 0x3041, Hi: 0x33FF, Stride: 1},
+		{Lo: 0x3400, Hi: 0x4DBF, Stride: 1},
+		{Lo: 0x4E00, Hi: 0x9FFF, Stride: 1},
+		{Lo: 0xA000, Hi: 0xA4CF, Stride: 1},
+		{Lo: 0xAC00, Hi: 0xD7A3, Stride: 1},
+		{Lo: 0xF900, Hi: 0xFAFF, Stride: 1},
+		{Lo: 0xFE30, Hi: 0xFE4F, Stride: 1},
+		{Lo: 0xFF00, Hi: 0xFF60, Stride: 1},
+		{Lo: 0xFFE0, Hi: 0xFFE6, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1F004, Hi: 0x1F004, Stride: 1},
+		{Lo: 0x1F0CF, Hi: 0x1F0CF, Stride: 1},
+		{Lo: 0x1F18E, Hi: 0x1F18E, Stride: 1},
+		{Lo: 0x1F191, Hi: 0x1F19A, Stride: 1},
+		{Lo: 0x1F200, Hi: 0x1F2FF, Stride: 1},
+		{Lo: 0x1F300, Hi: 0x1F64F, Stride: 1},
+		{Lo: 0x1F680, Hi: 0x1F6FF, Stride: 1},
+		{Lo: 0x1F7E0, Hi: 0x1F7EB, Stride: 1},
+		{Lo: 0x1F900, Hi: 0x1F9FF, Stride: 1},
+		{Lo: 0x1FA70, Hi: 0x1FAFF, Stride: 1},
+		{Lo: 0x20000, Hi: 0x3FFFD, Stride: 1},
+	},
+}
+
+// columns returns the number of terminal columns s takes, counting wide
+// runes as two columns and ANSI colour sequences as none.
+func columns(s string) int {
+	n := 0
+	escape := false
+	// previous is the width of the previous visible rune
+	previous := 0
+	for _, r := range s {
+		switch {
+		case escape:
+			// A colour sequence ends with its final letter, e.g. "\033[31m"
+			escape = r < 0x40 || r > 0x7E || r == '['
+		case r == '\033':
+			escape = true
+		case r == '\uFE0F':
+			// Presents the previous symbol as a two column emoji
+			n += 2 - previous
+			previous = 2
+		case unicode.Is(wide, r):
+			n += 2
+			previous = 2
+		default:
+			n++
+			previous = 1
+		}
+	}
+	return n
+}
+
+func wrapLine(line string, column int, continuation string, width int) string {
+	if column+columns(line) <= width {
+		return line
+	}
+
+	trimmed := strings.TrimLeft(line, " ")
+	var b strings.Builder
+	b.WriteString(line[:len(line)-len(trimmed)])
+	column += len(line) - len(trimmed)
+
+	start := true
+	for _, word := range strings.Fields(trimmed) {
+		length := columns(word)
+		if !start && column+1+length > width {
+			b.WriteString("\n")
+			b.WriteString(continuation)
+			column = columns(continuation)
+			start = true
+		}
+		if !start {
+			b.WriteString(" ")
+			column++
+		}
+		b.WriteString(word)
+		column += length
+		start = false
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"capi-advisor/pkg/analyzer"
+)
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want int
+	}{
+		{name: "ascii", s: "Ready", want: 5},
+		{name: "healthy", s: "✅", want: 2},
+		{name: "failed", s: "❌", want: 2},
+		{name: "pending", s: "⏳", want: 2},
+		{name: "unknown", s: "❓", want: 2},
+		{name: "critical", s: "🔴 Critical", want: 11},
+		{name: "variation selector", s: "⚠️ Warning", want: 10},
+		{name: "variation selector after wide rune", s: "✅️", want: 2},
+		{name: "text symbol", s: "✓ ok", want: 4},
+		{name: "east asian wide", s: "集群", want: 4},
+		{name: "colour", s: "\033[31mabc\033[0m", want: 3},
+		{name: "bold colour", s: "\033[1m\033[31m❌ abc\033[0m", want: 6},
+		{name: "empty", s: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columns(tt.s); got != tt.want {
+				t.Errorf("columns(%q) = %d, want %d", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	defer Configure(Current())
+
+	text := "Check the BMC address and the credentials Secret of the host\n1. kubectl get bmh -n metal3 worker-0 -o yaml"
+	tests := []struct {
+		name   string
+		prefix string
+		style  Style
+	}{
+		{name: "plain", prefix: "   Resolution: ", style: Style{Emoji: true, Width: 30}},
+		{name: "icon prefix", prefix: "   💡 Resolution: ", style: Style{Emoji: true, Width: 30}},
+		{name: "status icon prefix", prefix: "❌ ", style: Style{Emoji: true, Width: 24}},
+		{name: "colour", prefix: "   💡 Resolution: ", style: Style{Emoji: true, Color: true, Width: 30}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Configure(tt.style)
+			got := Wrap(tt.prefix, Severity(analyzer.SeverityCritical, text), "      ")
+
+			// Colour sequences take no columns, so coloured text wraps
+			// where plain text does
+			Configure(Style{Emoji: tt.style.Emoji, Width: tt.style.Width})
+			want := Wrap(tt.prefix, text, "      ")
+			if plain := ansiPattern.ReplaceAllString(got, ""); plain != want {
+				t.Errorf("Wrap() = %q, want %q", plain, want)
+			}
+
+			for _, line := range strings.Split(got, "\n") {
+				if n := columns(line); n > tt.style.Width {
+					t.Errorf("line %q takes %d columns, want at most %d", line, n, tt.style.Width)
+				}
+			}
+		})
+	}
+}
+
+func TestWrapDisabled(t *testing.T) {
+	defer Configure(Current())
+	Configure(Style{Emoji: true})
+
+	text := strings.Repeat("word ", 40)
+	if got := Wrap("❌ ", text, "   "); got != "❌ "+text {
+		t.Errorf("Wrap() = %q, want the text unchanged", got)
+	}
+}
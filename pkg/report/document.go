@@ -0,0 +1,235 @@
+// Package report defines the versioned document written by the JSON and
+// YAML output formats. Components are referenced by stable IDs and the
+// dependency tree is an adjacency list, so documents have no cycles and
+// every component is emitted once.
+package report
+
+import (
+	_ "embed"
+	"sort"
+	"strings"
+	"time"
+
+	"capi-advisor/pkg/analyzer"
+)
+
+// Document identity. The version changes whenever a field changes meaning
+// or is removed, adding fields keeps it.
+const (
+	APIVersion = "capi-advisor.io/v1alpha1"
+	Kind       = "AnalysisReport"
+)
+
+// Version of the advisor, set at build time with
+// -ldflags "-X capi-advisor/pkg/report.Version=v1.2.3"
+var Version = "dev"
+
+//go:embed schema/v1alpha1.json
+var jsonSchema []byte
+
+// JSONSchema returns the JSON Schema of the document.
+func JSONSchema() []byte {
+	return jsonSchema
+}
+
+type Document struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Metadata   Metadata    `json:"metadata"`
+	Summary    Summary     `json:"summary"`
+	Components []Component `json:"components"`
+	Tree       Tree        `json:"tree"`
+	Issues     []Issue     `json:"issues"`
+	Discovery  []Discovery `json:"discovery,omitempty"`
+}
+
+// Metadata describes how the analysis was run.
+type Metadata struct {
+	ToolVersion string    `json:"toolVersion"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	KubeContext string    `json:"kubeContext,omitempty"`
+	Filters     Filters   `json:"filters"`
+}
+
+// Filters are the options that limited the analysis.
+type Filters struct {
+	Namespace string `json:"namespace,omitempty"`
+	Cluster   string `json:"cluster,omitempty"`
+}
+
+type Summary struct {
+	TotalComponents int                                `json:"totalComponents"`
+	ClusterHealth   analyzer.ComponentStatus           `json:"clusterHealth"`
+	StatusCounts    map[analyzer.ComponentStatus]int   `json:"statusCounts"`
+	SeverityCounts  map[analyzer.ConditionSeverity]int `json:"severityCounts"`
+}
+
+type Component struct {
+	ID         string                   `json:"id"`
+	APIVersion string                   `json:"apiVersion"`
+	Kind       string                   `json:"kind"`
+	Namespace  string                   `json:"namespace,omitempty"`
+	Name       string                   `json:"name"`
+	Status     analyzer.ComponentStatus `json:"status"`
+	Conditions []Condition              `json:"conditions,omitempty"`
+	// Parent is the ID of the parent in the tree
+	Parent string `json:"parent,omitempty"`
+}
+
+type Condition struct {
+	Type               string     `json:"type"`
+	Status             string     `json:"status"`
+	Reason             string     `json:"reason,omitempty"`
+	Message            string     `json:"message,omitempty"`
+	LastTransitionTime *time.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// Tree is the dependency tree as an adjacency list of component IDs.
+type Tree struct {
+	Roots    []string            `json:"roots"`
+	Children map[string][]string `json:"children"`
+}
+
+type Issue struct {
+	RuleID string `json:"ruleId"`
+	// ComponentID may name a component that is not in the components list,
+	// e.g. "BareMetalHost.metal3.io/*" for a kind that could not be listed
+	ComponentID  string                     `json:"componentId"`
+	Severity     analyzer.ConditionSeverity `json:"severity"`
+	Description  string                     `json:"description"`
+	Cause        string                     `json:"cause"`
+	Resolution   string                     `json:"resolution"`
+	Condition    Condition                  `json:"condition"`
+	Dependencies []string                   `json:"dependencies,omitempty"`
+}
+
+// Discovery is the outcome of listing one kind.
+type Discovery struct {
+	APIVersion string                    `json:"apiVersion"`
+	Kind       string                    `json:"kind"`
+	Outcome    analyzer.DiscoveryOutcome `json:"outcome"`
+	Count      int                       `json:"count"`
+	Pages      int                       `json:"pages"`
+	DurationMs int64                     `json:"durationMs"`
+	Error      string                    `json:"error,omitempty"`
+}
+
+// ComponentID returns the stable ID of a component: its kind qualified by
+// API group, namespace and name, e.g. "Machine.cluster.x-k8s.io/default/m1".
+func ComponentID(comp *analyzer.Component) string {
+	kind := string(comp.Type)
+	if comp.GVK.Group != "" {
+		kind += "." + comp.GVK.Group
+	}
+	if comp.Namespace == "" {
+		return kind + "/" + comp.Name
+	}
+	return strings.Join([]string{kind, comp.Namespace, comp.Name}, "/")
+}
+
+// New builds the document of an analysis result and the roots of its
+// dependency tree.
+func New(result *analyzer.AnalysisResult, roots []*analyzer.Component, metadata Metadata) *Document {
+	doc := &Document{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Metadata:   metadata,
+		Summary: Summary{
+			TotalComponents: result.Summary.TotalComponents,
+			ClusterHealth:   result.Summary.ClusterHealth,
+			StatusCounts:    result.Summary.StatusCounts,
+			SeverityCounts:  result.Summary.SeverityCounts,
+		},
+		Components: make([]Component, 0, len(result.Components)),
+		Tree:       Tree{Roots: []string{}, Children: make(map[string][]string)},
+		Issues:     make([]Issue, 0, len(result.Issues)),
+	}
+	if doc.Metadata.ToolVersion == "" {
+		doc.Metadata.ToolVersion = Version
+	}
+
+	for _, comp := range result.Components {
+		doc.Components = append(doc.Components, newComponent(comp))
+		if len(comp.Children) > 0 {
+			children := make([]string, 0, len(comp.Children))
+			for _, child := range comp.Children {
+				children = append(children, ComponentID(child))
+			}
+			doc.Tree.Children[ComponentID(comp)] = children
+		}
+	}
+	sort.Slice(doc.Components, func(i, j int) bool {
+		return doc.Components[i].ID < doc.Components[j].ID
+	})
+
+	for _, root := range roots {
+		doc.Tree.Roots = append(doc.Tree.Roots, ComponentID(root))
+	}
+
+	for _, issue := range result.Issues {
+		doc.Issues = append(doc.Issues, newIssue(issue))
+	}
+
+	for _, outcome := range result.Discovery {
+		doc.Discovery = append(doc.Discovery, Discovery{
+			APIVersion: outcome.GVK.GroupVersion().String(),
+			Kind:       outcome.GVK.Kind,
+			Outcome:    outcome.Outcome,
+			Count:      outcome.Count,
+			Pages:      outcome.Pages,
+			DurationMs: outcome.DurationMs,
+			Error:      outcome.Error,
+		})
+	}
+
+	return doc
+}
+
+func newComponent(comp *analyzer.Component) Component {
+	c := Component{
+		ID:         ComponentID(comp),
+		APIVersion: comp.GVK.GroupVersion().String(),
+		Kind:       string(comp.Type),
+		Namespace:  comp.Namespace,
+		Name:       comp.Name,
+		Status:     comp.Status,
+	}
+	if comp.Parent != nil {
+		c.Parent = ComponentID(comp.Parent)
+	}
+	for _, condition := range comp.Conditions {
+		c.Conditions = append(c.Conditions, newCondition(condition.Type, string(condition.Status),
+			condition.Reason, condition.Message, condition.LastTransitionTime.Time))
+	}
+	return c
+}
+
+func newCondition(conditionType, status, reason, message string, lastTransition time.Time) Condition {
+	condition := Condition{
+		Type:    conditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+	if !lastTransition.IsZero() {
+		condition.LastTransitionTime = &lastTransition
+	}
+	return condition
+}
+
+func newIssue(issue *analyzer.Issue) Issue {
+	i := Issue{
+		RuleID:      issue.RuleID,
+		ComponentID: ComponentID(issue.Component),
+		Severity:    issue.Severity,
+		Description: issue.Description,
+		Cause:       issue.Cause,
+		Resolution:  issue.Resolution,
+		Condition: newCondition(issue.Condition.Type, string(issue.Condition.Status),
+			issue.Condition.Reason, issue.Condition.Message, issue.Condition.LastTransitionTime.Time),
+	}
+	for _, dep := range issue.Dependencies {
+		i.Dependencies = append(i.Dependencies, ComponentID(dep))
+	}
+	return i
+}
@@ -0,0 +1,334 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/tree"
+)
+
+// Ways of clustering the nodes of a graph.
+const (
+	GroupByNamespace = "namespace"
+	GroupByCluster   = "cluster"
+)
+
+// Graph is the dependency tree as nodes and labelled edges, the model behind
+// the DOT, Mermaid and JSON graph exports. A node is failing when it or one
+// of its descendants is Failed or Degraded, so the failing nodes and edges
+// form the paths from the roots to the broken components. Besides the tree
+// edges from each primary parent, the graph has an edge for every other
+// link, e.g. from the Cluster to a Machine owned by a MachineSet.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+type GraphNode struct {
+	ID        string                   `json:"id"`
+	Kind      string                   `json:"kind"`
+	Namespace string                   `json:"namespace,omitempty"`
+	Name      string                   `json:"name"`
+	Status    analyzer.ComponentStatus `json:"status"`
+	// Group is the namespace or Cluster the node is clustered in
+	Group   string `json:"group,omitempty"`
+	Failing bool   `json:"failing"`
+}
+
+type GraphEdge struct {
+	Source   string        `json:"source"`
+	Target   string        `json:"target"`
+	Relation tree.Relation `json:"relation"`
+	// Primary is set on the edges of the tree, from the parent a node is
+	// shown under
+	Primary bool `json:"primary"`
+	Failing bool `json:"failing"`
+}
+
+// NewGraph builds the graph of a dependency tree, clustering nodes by
+// GroupByNamespace, GroupByCluster or not at all when groupBy is empty.
+// Nodes are ordered depth first with siblings sorted by ID, so the same tree
+// always renders the same way. The tree edges come first, then the other
+// links between the nodes in the order of tb.Edges.
+func NewGraph(tb *tree.TreeBuilder, roots []*analyzer.Component, groupBy string) *Graph {
+	g := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	visited := make(map[*analyzer.Component]bool)
+
+	var visit func(comp *analyzer.Component) bool
+	visit = func(comp *analyzer.Component) bool {
+		visited[comp] = true
+		index := len(g.Nodes)
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID:        ComponentID(comp),
+			Kind:      string(comp.Type),
+			Namespace: comp.Namespace,
+			Name:      comp.Name,
+			Status:    comp.Status,
+			Group:     nodeGroup(comp, groupBy),
+		})
+
+		failing := isBroken(comp.Status)
+		for _, child := range sortedByID(comp.Children) {
+			if visited[child] {
+				continue
+			}
+			edge := len(g.Edges)
+			g.Edges = append(g.Edges, GraphEdge{
+				Source:   ComponentID(comp),
+				Target:   ComponentID(child),
+				Relation: tb.Relation(child),
+				Primary:  true,
+			})
+			if visit(child) {
+				g.Edges[edge].Failing = true
+				failing = true
+			}
+		}
+		g.Nodes[index].Failing = failing
+		return failing
+	}
+
+	for _, root := range sortedByID(roots) {
+		if !visited[root] {
+			visit(root)
+		}
+	}
+
+	failing := make(map[string]bool, len(g.Nodes))
+	for _, node := range g.Nodes {
+		failing[node.ID] = node.Failing
+	}
+	for _, edge := range tb.Edges() {
+		if edge.Primary || !visited[edge.Parent] || !visited[edge.Child] {
+			continue
+		}
+		target := ComponentID(edge.Child)
+		g.Edges = append(g.Edges, GraphEdge{
+			Source:   ComponentID(edge.Parent),
+			Target:   target,
+			Relation: edge.Relation,
+			Failing:  failing[target],
+		})
+	}
+	return g
+}
+
+// Groups returns the names of the groups of the graph, sorted.
+func (g *Graph) Groups() []string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, node := range g.Nodes {
+		if node.Group != "" && !seen[node.Group] {
+			seen[node.Group] = true
+			groups = append(groups, node.Group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// DOT renders the graph in the Graphviz DOT language, e.g. for
+// "dot -Tsvg". Groups become clusters.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph \"capi-advisor\" {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	writeNode := func(indent string, node GraphNode) {
+		colors := statusColors(node.Status)
+		fmt.Fprintf(&b, "%s%s [label=%s, fillcolor=%q, color=%q",
+			indent, dotQuote(node.ID), dotQuote(node.Kind+"\n"+node.Name+"\n("+string(node.Status)+")"),
+			colors.fill, colors.stroke)
+		if node.Failing {
+			b.WriteString(", penwidth=2")
+		}
+		b.WriteString("];\n")
+	}
+
+	for i, group := range g.Groups() {
+		fmt.Fprintf(&b, "\n  subgraph \"cluster_%d\" {\n", i)
+		fmt.Fprintf(&b, "    label=%s;\n", dotQuote(group))
+		b.WriteString("    style=dashed;\n")
+		for _, node := range g.Nodes {
+			if node.Group == group {
+				writeNode("    ", node)
+			}
+		}
+		b.WriteString("  }\n")
+	}
+	ungrouped := "\n"
+	for _, node := range g.Nodes {
+		if node.Group == "" {
+			b.WriteString(ungrouped)
+			ungrouped = ""
+			writeNode("  ", node)
+		}
+	}
+
+	b.WriteString("\n")
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s", dotQuote(edge.Source), dotQuote(edge.Target), dotQuote(string(edge.Relation)))
+		if !edge.Primary {
+			// Keep the layout of the tree, other links are drawn dashed
+			b.WriteString(", style=dashed, constraint=false")
+		}
+		if edge.Failing {
+			fmt.Fprintf(&b, ", color=%q, fontcolor=%q, penwidth=2", failingColor, failingColor)
+		}
+		b.WriteString("];\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart, which wikis and issue
+// trackers render inline. Groups become subgraphs.
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, status := range []analyzer.ComponentStatus{
+		analyzer.StatusHealthy, analyzer.StatusDegraded, analyzer.StatusFailed,
+		analyzer.StatusPending, analyzer.StatusUnknown,
+	} {
+		colors := statusColors(status)
+		fmt.Fprintf(&b, "  classDef %s fill:%s,stroke:%s\n", status, colors.fill, colors.stroke)
+	}
+	fmt.Fprintf(&b, "  classDef failingPath stroke:%s,stroke-width:3px\n", failingColor)
+
+	// Mermaid IDs must be plain words, so nodes are numbered
+	ids := make(map[string]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+	}
+	writeNode := func(indent string, node GraphNode) {
+		fmt.Fprintf(&b, "%s%s[\"%s<br/>%s\"]:::%s\n", indent, ids[node.ID],
+			mermaidEscape(node.Kind), mermaidEscape(node.Name), statusClass(node.Status))
+	}
+
+	for i, group := range g.Groups() {
+		fmt.Fprintf(&b, "  subgraph g%d[\"%s\"]\n", i, mermaidEscape(group))
+		for _, node := range g.Nodes {
+			if node.Group == group {
+				writeNode("    ", node)
+			}
+		}
+		b.WriteString("  end\n")
+	}
+	for _, node := range g.Nodes {
+		if node.Group == "" {
+			writeNode("  ", node)
+		}
+	}
+
+	var failingEdges []string
+	for i, edge := range g.Edges {
+		arrow := "-->"
+		if !edge.Primary {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", ids[edge.Source], arrow, edge.Relation, ids[edge.Target])
+		if edge.Failing {
+			failingEdges = append(failingEdges, fmt.Sprint(i))
+		}
+	}
+
+	var failingNodes []string
+	for _, node := range g.Nodes {
+		if node.Failing {
+			failingNodes = append(failingNodes, ids[node.ID])
+		}
+	}
+	if len(failingNodes) > 0 {
+		fmt.Fprintf(&b, "  class %s failingPath\n", strings.Join(failingNodes, ","))
+	}
+	if len(failingEdges) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:%s,stroke-width:3px\n", strings.Join(failingEdges, ","), failingColor)
+	}
+	return b.String()
+}
+
+// failingColor highlights the paths to broken components.
+const failingColor = "#dc3545"
+
+type colors struct {
+	fill   string
+	stroke string
+}
+
+func statusColors(status analyzer.ComponentStatus) colors {
+	switch status {
+	case analyzer.StatusHealthy:
+		return colors{fill: "#d4edda", stroke: "#28a745"}
+	case analyzer.StatusDegraded:
+		return colors{fill: "#fff3cd", stroke: "#ffc107"}
+	case analyzer.StatusFailed:
+		return colors{fill: "#f8d7da", stroke: "#dc3545"}
+	case analyzer.StatusPending:
+		return colors{fill: "#d1ecf1", stroke: "#17a2b8"}
+	default:
+		return colors{fill: "#e2e3e5", stroke: "#6c757d"}
+	}
+}
+
+// statusClass returns the Mermaid class of a status, Unknown for statuses
+// without one.
+func statusClass(status analyzer.ComponentStatus) analyzer.ComponentStatus {
+	switch status {
+	case analyzer.StatusHealthy, analyzer.StatusDegraded, analyzer.StatusFailed, analyzer.StatusPending:
+		return status
+	default:
+		return analyzer.StatusUnknown
+	}
+}
+
+func isBroken(status analyzer.ComponentStatus) bool {
+	return status == analyzer.StatusFailed || status == analyzer.StatusDegraded
+}
+
+// nodeGroup returns the namespace or the namespace/name of the Cluster a
+// component belongs to, found through its ancestors or its cluster-name
+// label.
+func nodeGroup(comp *analyzer.Component, groupBy string) string {
+	switch groupBy {
+	case GroupByNamespace:
+		return comp.Namespace
+	case GroupByCluster:
+		for ancestor := comp; ancestor != nil; ancestor = ancestor.Parent {
+			if ancestor.Type == analyzer.ClusterType {
+				return ancestor.Namespace + "/" + ancestor.Name
+			}
+		}
+		if labels, ok := comp.Metadata["labels"].(map[string]string); ok {
+			if name := labels["cluster.x-k8s.io/cluster-name"]; name != "" {
+				return comp.Namespace + "/" + name
+			}
+		}
+	}
+	return ""
+}
+
+func sortedByID(components []*analyzer.Component) []*analyzer.Component {
+	sorted := append([]*analyzer.Component(nil), components...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return ComponentID(sorted[i]) < ComponentID(sorted[j])
+	})
+	return sorted
+}
+
+// dotQuote quotes a DOT ID, escaping quotes and turning newlines into
+// centered line breaks.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
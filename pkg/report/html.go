@@ -0,0 +1,125 @@
+package report
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/tree"
+)
+
+//go:embed templates/report.html
+var htmlTemplate string
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"lower": func(v interface{}) string {
+		return strings.ToLower(fmt.Sprint(v))
+	},
+}).Parse(htmlTemplate))
+
+// htmlData is the view of a result rendered by the HTML template.
+type htmlData struct {
+	*Document
+	Statuses   []htmlCount
+	Severities []htmlCount
+	Issues     []htmlIssue
+	Tree       []*htmlNode
+	Incomplete []Discovery
+}
+
+type htmlCount struct {
+	Name  string
+	Count int
+}
+
+type htmlIssue struct {
+	Issue
+	Kind      string
+	Namespace string
+	Name      string
+	Events    []analyzer.Event
+}
+
+type htmlNode struct {
+	Component
+	Relation tree.Relation
+	Failing  bool
+	Issues   int
+	Children []*htmlNode
+}
+
+// HTML renders a result as a single self-contained HTML page with a summary
+// dashboard, the collapsible dependency tree and a filterable issue table.
+// The page has no external assets, so it can be mailed and opened offline.
+// Events resolved with analyzer.ResolveEvents are shown with their issues.
+func HTML(result *analyzer.AnalysisResult, tb *tree.TreeBuilder, roots []*analyzer.Component, metadata Metadata) ([]byte, error) {
+	doc := New(result, roots, metadata)
+	data := htmlData{Document: doc}
+
+	for _, status := range []analyzer.ComponentStatus{
+		analyzer.StatusHealthy, analyzer.StatusDegraded, analyzer.StatusFailed,
+		analyzer.StatusPending, analyzer.StatusUnknown,
+	} {
+		data.Statuses = append(data.Statuses, htmlCount{Name: string(status), Count: doc.Summary.StatusCounts[status]})
+	}
+	for _, severity := range []analyzer.ConditionSeverity{
+		analyzer.SeverityCritical, analyzer.SeverityWarning, analyzer.SeverityInfo,
+	} {
+		data.Severities = append(data.Severities, htmlCount{Name: string(severity), Count: doc.Summary.SeverityCounts[severity]})
+	}
+
+	issueCounts := make(map[string]int)
+	for i, issue := range result.Issues {
+		data.Issues = append(data.Issues, htmlIssue{
+			Issue:     doc.Issues[i],
+			Kind:      string(issue.Component.Type),
+			Namespace: issue.Component.Namespace,
+			Name:      issue.Component.Name,
+			Events:    analyzer.ComponentEvents(issue.Component),
+		})
+		issueCounts[doc.Issues[i].ComponentID]++
+	}
+
+	// The graph decides which nodes are on a failing path
+	failing := make(map[string]bool)
+	for _, node := range NewGraph(tb, roots, "").Nodes {
+		failing[node.ID] = node.Failing
+	}
+	visited := make(map[*analyzer.Component]bool)
+	var newNode func(comp *analyzer.Component) *htmlNode
+	newNode = func(comp *analyzer.Component) *htmlNode {
+		visited[comp] = true
+		node := &htmlNode{
+			Component: newComponent(comp),
+			Relation:  tb.Relation(comp),
+			Failing:   failing[ComponentID(comp)],
+			Issues:    issueCounts[ComponentID(comp)],
+		}
+		for _, child := range sortedByID(comp.Children) {
+			if !visited[child] {
+				node.Children = append(node.Children, newNode(child))
+			}
+		}
+		return node
+	}
+	for _, root := range sortedByID(roots) {
+		if !visited[root] {
+			data.Tree = append(data.Tree, newNode(root))
+		}
+	}
+
+	for _, outcome := range doc.Discovery {
+		if outcome.Outcome.Incomplete() {
+			data.Incomplete = append(data.Incomplete, outcome)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := htmlReport.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// JUnit renders a result as JUnit XML for test dashboards: a test suite per
+// kind, a test case per component and a failure per issue, typed by its
+// severity.
+func JUnit(result *analyzer.AnalysisResult) ([]byte, error) {
+	cases := make(map[string]*junitTestCase)
+	kinds := make(map[string][]string)
+
+	addCase := func(comp *analyzer.Component) *junitTestCase {
+		id := ComponentID(comp)
+		if testCase, found := cases[id]; found {
+			return testCase
+		}
+		testCase := &junitTestCase{Name: resourcePath(comp), ClassName: string(comp.Type)}
+		cases[id] = testCase
+		kinds[string(comp.Type)] = append(kinds[string(comp.Type)], id)
+		return testCase
+	}
+
+	for _, comp := range result.Components {
+		addCase(comp)
+	}
+	// Issues may concern objects that are not components, such as a kind
+	// that could not be listed
+	for _, issue := range result.Issues {
+		testCase := addCase(issue.Component)
+		testCase.Failures = append(testCase.Failures, junitFailure{
+			Message: issue.Description,
+			Type:    string(issue.Severity),
+			Text:    fmt.Sprintf("[%s] %s\n\n%s\n\nResolution:\n%s", issue.Severity, issue.RuleID, issue.Cause, issue.Resolution),
+		})
+	}
+
+	kindNames := make([]string, 0, len(kinds))
+	for kind := range kinds {
+		kindNames = append(kindNames, kind)
+	}
+	sort.Strings(kindNames)
+
+	suites := junitTestSuites{Name: "capi-advisor"}
+	for _, kind := range kindNames {
+		ids := kinds[kind]
+		sort.Strings(ids)
+
+		suite := junitTestSuite{Name: kind}
+		for _, id := range ids {
+			testCase := cases[id]
+			suite.TestCases = append(suite.TestCases, *testCase)
+			suite.Tests++
+			if len(testCase.Failures) > 0 {
+				suite.Failures++
+			}
+		}
+		suites.Suites = append(suites.Suites, suite)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.Join([]string{xml.Header, string(data), "\n"}, "")), nil
+}
@@ -0,0 +1,235 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"capi-advisor/pkg/advisor"
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/tree"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// otherComponents is the heading of the components that belong to no
+// Cluster, such as BareMetalHosts that are not provisioned yet.
+const otherComponents = "Other components"
+
+// markdownGroup is the part of a result that belongs to one Cluster.
+type markdownGroup struct {
+	name           string
+	components     int
+	statusCounts   map[analyzer.ComponentStatus]int
+	severityCounts map[analyzer.ConditionSeverity]int
+	issues         []*analyzer.Issue
+	roots          []*analyzer.Component
+}
+
+// Markdown renders a result for issue trackers and pull request comments:
+// a status summary table, then per Cluster its issues as collapsible
+// blocks and its dependency tree as nested lists.
+func Markdown(result *analyzer.AnalysisResult, tb *tree.TreeBuilder, roots []*analyzer.Component, metadata Metadata) string {
+	groups := make(map[string]*markdownGroup)
+	group := func(comp *analyzer.Component) *markdownGroup {
+		name := nodeGroup(comp, GroupByCluster)
+		if g, found := groups[name]; found {
+			return g
+		}
+		g := &markdownGroup{
+			name:           name,
+			statusCounts:   make(map[analyzer.ComponentStatus]int),
+			severityCounts: make(map[analyzer.ConditionSeverity]int),
+		}
+		groups[name] = g
+		return g
+	}
+	for _, comp := range result.Components {
+		g := group(comp)
+		g.components++
+		g.statusCounts[comp.Status]++
+	}
+	for _, issue := range result.Issues {
+		g := group(issue.Component)
+		g.issues = append(g.issues, issue)
+		g.severityCounts[issue.Severity]++
+	}
+	for _, root := range sortedByID(roots) {
+		g := group(root)
+		g.roots = append(g.roots, root)
+	}
+
+	// Clusters sorted by name, then the components of no Cluster
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, found := groups[""]; found {
+		names = append(names, "")
+	}
+
+	var b strings.Builder
+	b.WriteString("# Cluster API Advisor Report\n\n")
+	fmt.Fprintf(&b, "Generated %s", metadata.GeneratedAt.Format("2006-01-02 15:04:05 MST"))
+	if metadata.KubeContext != "" {
+		fmt.Fprintf(&b, " for context `%s`", metadata.KubeContext)
+	}
+	if metadata.Filters.Namespace != "" {
+		fmt.Fprintf(&b, ", namespace `%s`", metadata.Filters.Namespace)
+	}
+	if metadata.Filters.Cluster != "" {
+		fmt.Fprintf(&b, ", cluster `%s`", metadata.Filters.Cluster)
+	}
+	toolVersion := metadata.ToolVersion
+	if toolVersion == "" {
+		toolVersion = Version
+	}
+	fmt.Fprintf(&b, " by capi-advisor %s.\n\n", toolVersion)
+
+	// Summary
+	b.WriteString("## Summary\n\n")
+	fmt.Fprintf(&b, "**Overall health: %s**, %d components, %d issues.\n\n",
+		result.Summary.ClusterHealth, result.Summary.TotalComponents, len(result.Issues))
+	b.WriteString("| Cluster | Health | Components | Healthy | Degraded | Failed | Pending | Unknown | Critical | Warning | Info |\n")
+	b.WriteString("|---|---|--:|--:|--:|--:|--:|--:|--:|--:|--:|\n")
+	for _, name := range names {
+		g := groups[name]
+		fmt.Fprintf(&b, "| %s | %s | %d | %d | %d | %d | %d | %d | %d | %d | %d |\n",
+			markdownCell(g.title()), advisor.ClusterHealth(g.statusCounts, g.severityCounts), g.components,
+			g.statusCounts[analyzer.StatusHealthy], g.statusCounts[analyzer.StatusDegraded],
+			g.statusCounts[analyzer.StatusFailed], g.statusCounts[analyzer.StatusPending],
+			g.statusCounts[analyzer.StatusUnknown], g.severityCounts[analyzer.SeverityCritical],
+			g.severityCounts[analyzer.SeverityWarning], g.severityCounts[analyzer.SeverityInfo])
+	}
+	b.WriteString("\n")
+
+	var incomplete []analyzer.GVKDiscovery
+	for _, outcome := range result.Discovery {
+		if outcome.Outcome.Incomplete() {
+			incomplete = append(incomplete, outcome)
+		}
+	}
+	if len(incomplete) > 0 {
+		b.WriteString("> [!WARNING]\n")
+		b.WriteString("> The analysis is incomplete, these kinds could not be listed:\n")
+		for _, outcome := range incomplete {
+			fmt.Fprintf(&b, "> - `%s` (`%s`): %s\n", outcome.GVK.Kind, outcome.GVK.GroupVersion(), outcome.Outcome)
+		}
+		b.WriteString("\n")
+	}
+
+	for _, name := range names {
+		g := groups[name]
+		fmt.Fprintf(&b, "## %s\n\n", g.title())
+
+		b.WriteString("### Issues\n\n")
+		if len(g.issues) == 0 {
+			b.WriteString("No issues found.\n\n")
+		}
+		for _, issue := range g.issues {
+			writeMarkdownIssue(&b, issue)
+		}
+
+		if len(g.roots) > 0 {
+			b.WriteString("### Dependency tree\n\n")
+			for _, root := range g.roots {
+				writeMarkdownNode(&b, tb, root, 0, make(map[*analyzer.Component]bool))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// title is the heading of a group: the Cluster as namespace/name.
+func (g *markdownGroup) title() string {
+	if g.name == "" {
+		return otherComponents
+	}
+	return "Cluster " + g.name
+}
+
+func writeMarkdownIssue(b *strings.Builder, issue *analyzer.Issue) {
+	// The summary is HTML, so it is escaped instead of using Markdown
+	fmt.Fprintf(b, "<details>\n<summary><b>%s</b> %s: %s</summary>\n\n",
+		issue.Severity, html.EscapeString(resourcePath(issue.Component)), html.EscapeString(issue.Description))
+	fmt.Fprintf(b, "- **Rule:** `%s`\n", issue.RuleID)
+	fmt.Fprintf(b, "- **Condition:** `%s=%s`", issue.Condition.Type, issue.Condition.Status)
+	if issue.Condition.Reason != "" {
+		fmt.Fprintf(b, " (%s)", issue.Condition.Reason)
+	}
+	b.WriteString("\n")
+	if len(issue.Dependencies) > 0 {
+		deps := make([]string, 0, len(issue.Dependencies))
+		for _, dep := range issue.Dependencies {
+			deps = append(deps, fmt.Sprintf("`%s/%s` (%s)", dep.Type, dep.Name, dep.Status))
+		}
+		fmt.Fprintf(b, "- **Dependencies:** %s\n", strings.Join(deps, ", "))
+	}
+	// Keep the line breaks of the cause as Markdown hard breaks
+	fmt.Fprintf(b, "\n**Cause:** %s\n\n", strings.ReplaceAll(issue.Cause, "\n", "  \n"))
+	b.WriteString("**Resolution:**\n\n")
+	resolution := dedentResolution(issue.Resolution)
+	fence := markdownFence(resolution)
+	fmt.Fprintf(b, "%s\n%s\n%s\n\n", fence, resolution, fence)
+	b.WriteString("</details>\n\n")
+}
+
+func writeMarkdownNode(b *strings.Builder, tb *tree.TreeBuilder, comp *analyzer.Component, depth int, visited map[*analyzer.Component]bool) {
+	visited[comp] = true
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(b, "%s- **%s** `%s/%s`", indent, comp.Status, comp.Type, comp.Name)
+	if relation := tb.Relation(comp); relation != "" {
+		fmt.Fprintf(b, " _via %s_", relation)
+	}
+	b.WriteString("\n")
+
+	if lookupErr, ok := analyzer.ReferenceLookupError(comp); ok {
+		fmt.Fprintf(b, "%s  - %s: %s\n", indent, lookupErr.Reason, lookupErr.Message)
+	}
+	// Only conditions that are not met, the rest adds noise to tickets
+	for _, condition := range comp.Conditions {
+		if condition.Status == metav1.ConditionTrue {
+			continue
+		}
+		fmt.Fprintf(b, "%s  - `%s=%s`", indent, condition.Type, condition.Status)
+		if condition.Message != "" {
+			fmt.Fprintf(b, " %s", condition.Message)
+		}
+		b.WriteString("\n")
+	}
+
+	for _, child := range sortedByID(comp.Children) {
+		if !visited[child] {
+			writeMarkdownNode(b, tb, child, depth+1, visited)
+		}
+	}
+}
+
+// dedentResolution removes the indentation the knowledge base gives the
+// steps after the first to align them in the text report.
+func dedentResolution(resolution string) string {
+	lines := strings.Split(resolution, "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = strings.TrimPrefix(lines[i], "   ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownFence returns a code fence longer than any backtick run in s.
+func markdownFence(s string) string {
+	fence := "```"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	return fence
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
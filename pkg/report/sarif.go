@@ -0,0 +1,169 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"capi-advisor/pkg/advisor"
+	"capi-advisor/pkg/analyzer"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	Help                 sarifMessage       `json:"help"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// SARIF renders the issues of a result as a SARIF 2.1.0 log for code
+// scanning dashboards. Every issue is a result of its rule, located at the
+// component as kind/namespace/name. Rules are described by their knowledge
+// base entry in adv, the text naming the object is in the results only.
+func SARIF(result *analyzer.AnalysisResult, adv *advisor.Advisor) ([]byte, error) {
+	driver := sarifDriver{Name: "capi-advisor", Version: Version, Rules: []sarifRule{}}
+	ruleIndex := make(map[string]int)
+	results := []sarifResult{}
+
+	for _, issue := range result.Issues {
+		index, found := ruleIndex[issue.RuleID]
+		if !found {
+			index = len(driver.Rules)
+			ruleIndex[issue.RuleID] = index
+			driver.Rules = append(driver.Rules, newSARIFRule(issue, adv))
+		}
+
+		comp := issue.Component
+		results = append(results, sarifResult{
+			RuleID:    issue.RuleID,
+			RuleIndex: index,
+			Level:     sarifLevel(issue.Severity),
+			Message:   sarifMessage{Text: issue.Description + "\n" + issue.Cause + "\n\n" + issue.Resolution},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: resourcePath(comp)},
+				},
+				LogicalLocations: []sarifLogicalLocation{{
+					Name:               comp.Name,
+					FullyQualifiedName: ComponentID(comp),
+					Kind:               "resource",
+				}},
+			}},
+			PartialFingerprints: map[string]string{
+				"capiAdvisor/v1": issue.RuleID + ":" + ComponentID(comp),
+			},
+		})
+	}
+
+	return json.MarshalIndent(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}, "", "  ")
+}
+
+// newSARIFRule describes the rule of an issue without naming its object:
+// from the knowledge base entry, or from the kind and condition the check
+// reports on.
+func newSARIFRule(issue *analyzer.Issue, adv *advisor.Advisor) sarifRule {
+	rule := sarifRule{
+		ID:                   issue.RuleID,
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(issue.Severity)},
+	}
+
+	if entry, found := adv.Rule(issue.RuleID); found {
+		rule.ShortDescription.Text = entry.Condition
+		rule.Help.Text = entry.Cause + "\n\n" + entry.Resolution
+		return rule
+	}
+
+	condition := issue.Condition
+	rule.ShortDescription.Text = fmt.Sprintf("%s %s is %s", issue.Component.Type, condition.Type, condition.Status)
+	if condition.Reason != "" {
+		rule.ShortDescription.Text += ": " + condition.Reason
+	}
+	rule.Help.Text = fmt.Sprintf("Reported by the %s %s check. The message of each result names the affected object, the cause and the steps to resolve it.",
+		issue.Component.Type, condition.Type)
+	return rule
+}
+
+func sarifLevel(severity analyzer.ConditionSeverity) string {
+	switch severity {
+	case analyzer.SeverityCritical:
+		return "error"
+	case analyzer.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// resourcePath locates a component as kind/namespace/name, or kind/name for
+// cluster scoped kinds.
+func resourcePath(comp *analyzer.Component) string {
+	if comp.Namespace == "" {
+		return string(comp.Type) + "/" + comp.Name
+	}
+	return string(comp.Type) + "/" + comp.Namespace + "/" + comp.Name
+}
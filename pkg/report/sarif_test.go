@@ -0,0 +1,69 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"capi-advisor/pkg/advisor"
+	"capi-advisor/pkg/analyzer"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSARIFRules(t *testing.T) {
+	issue := func(ruleID, name string, condition metav1.Condition) *analyzer.Issue {
+		return &analyzer.Issue{
+			RuleID:      ruleID,
+			Component:   &analyzer.Component{Name: name, Namespace: "metal3", Type: analyzer.BareMetalHostType},
+			Condition:   condition,
+			Severity:    analyzer.SeverityCritical,
+			Description: "BMC credentials Secret " + name + "-bmc does not exist",
+			Cause:       "The Secret is missing",
+			Resolution:  "1. kubectl describe bmh " + name,
+		}
+	}
+	missingSecret := metav1.Condition{Type: "BMCConfigured", Status: metav1.ConditionFalse, Reason: "CredentialsSecretNotFound"}
+	result := &analyzer.AnalysisResult{Issues: []*analyzer.Issue{
+		issue("BareMetalHost.BMCConfigured.False.CredentialsSecretNotFound", "host-0", missingSecret),
+		issue("BareMetalHost.BMCConfigured.False.CredentialsSecretNotFound", "host-1", missingSecret),
+		issue("Machine.Ready.False", "worker-0", metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse}),
+	}}
+
+	adv := advisor.NewAdvisor()
+	data, err := SARIF(result, adv)
+	if err != nil {
+		t.Fatalf("SARIF() error = %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("SARIF() is not valid JSON: %v", err)
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(run.Tool.Driver.Rules))
+	}
+	check := run.Tool.Driver.Rules[0]
+	if want := "BareMetalHost BMCConfigured is False: CredentialsSecretNotFound"; check.ShortDescription.Text != want {
+		t.Errorf("check rule shortDescription = %q, want %q", check.ShortDescription.Text, want)
+	}
+	entry, _ := adv.Rule("Machine.Ready.False")
+	if knowledge := run.Tool.Driver.Rules[1]; knowledge.ShortDescription.Text != entry.Condition || !strings.Contains(knowledge.Help.Text, entry.Resolution) {
+		t.Errorf("knowledge base rule = %+v, want the description and resolution of %+v", knowledge, entry)
+	}
+
+	for _, rule := range run.Tool.Driver.Rules {
+		for _, name := range []string{"host-0", "host-1", "worker-0"} {
+			if strings.Contains(rule.ShortDescription.Text+rule.Help.Text, name) {
+				t.Errorf("rule %s names object %s: %+v", rule.ID, name, rule)
+			}
+		}
+	}
+	for i, res := range run.Results {
+		name := result.Issues[i].Component.Name
+		if !strings.Contains(res.Message.Text, "kubectl describe bmh "+name) {
+			t.Errorf("result %d message %q lacks the resolution for %s", i, res.Message.Text, name)
+		}
+	}
+}
@@ -1,43 +1,62 @@
 package tree
 
 import (
-	"fmt"
+	"sort"
 	"strings"
 
 	"capi-advisor/pkg/analyzer"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 type TreeBuilder struct {
+	// PrintOptions filters and condenses the output of PrintTree
+	PrintOptions PrintOptions
+
+	// components holds the components by namespace, name and kind, index
+	// holds them by owner, Cluster and consumer
 	components map[string]*analyzer.Component
+	index      index
+	// relations records how each child is linked to its primary parent
+	relations map[string]Relation
+	// edges are all links between components, edgeKeys deduplicates them
+	edges       []Edge
+	edgeKeys    map[string]bool
+	parentEdges map[*analyzer.Component][]Edge
+	childEdges  map[*analyzer.Component][]Edge
 }
 
 func NewTreeBuilder() *TreeBuilder {
 	return &TreeBuilder{
-		components: make(map[string]*analyzer.Component),
+		components:  make(map[string]*analyzer.Component),
+		relations:   make(map[string]Relation),
+		edgeKeys:    make(map[string]bool),
+		parentEdges: make(map[*analyzer.Component][]Edge),
+		childEdges:  make(map[*analyzer.Component][]Edge),
 	}
 }
 
 func (tb *TreeBuilder) BuildDependencyTree(components []*analyzer.Component) []*analyzer.Component {
-	// Index all components by name and namespace
-	for _, comp := range components {
-		key := tb.getComponentKey(comp)
-		tb.components[key] = comp
-	}
+	// Index all components up front, so that linking them takes a lookup per
+	// link rather than a scan of all components
+	tb.buildIndex(components)
 
-	// Build relationships
+	// Build relationships, then show every component under one parent
 	for _, comp := range components {
 		tb.buildRelationships(comp)
 	}
+	tb.linkPrimaryParents()
 
-	// Return root components (those without parents)
+	// Return root components (those without parents), sorted so the output
+	// does not depend on the order components were discovered in
 	var roots []*analyzer.Component
 	for _, comp := range components {
 		if comp.Parent == nil {
 			roots = append(roots, comp)
 		}
 	}
+	sort.SliceStable(roots, func(i, j int) bool {
+		return tb.getComponentKey(roots[i]) < tb.getComponentKey(roots[j])
+	})
 
 	return roots
 }
@@ -52,202 +71,249 @@ func (tb *TreeBuilder) buildRelationships(comp *analyzer.Component) {
 		tb.buildMachineDeploymentRelationships(comp)
 	case analyzer.Metal3MachineType:
 		tb.buildMetal3MachineRelationships(comp)
+	case analyzer.BareMetalHostType:
+		tb.buildBareMetalHostRelationships(comp)
+	case analyzer.HostFirmwareSettingsType:
+		tb.buildHostFirmwareSettingsRelationships(comp)
 	case analyzer.ClusterType:
 		tb.buildClusterRelationships(comp)
 	case analyzer.KubeadmControlPlaneType:
 		tb.buildKubeadmControlPlaneRelationships(comp)
+	case analyzer.MachinePoolType:
+		tb.buildMachinePoolRelationships(comp)
+	case analyzer.ClusterClassType:
+		tb.buildClusterClassRelationships(comp)
+	case analyzer.Metal3DataClaimType:
+		tb.buildMetal3DataClaimRelationships(comp)
+	case analyzer.Metal3DataType:
+		tb.buildMetal3DataRelationships(comp)
+	case analyzer.IPClaimType:
+		tb.buildIPClaimRelationships(comp)
+	case analyzer.IPPoolType, analyzer.Metal3DataTemplateType:
+		// Linked to the Cluster named in spec.clusterName by
+		// buildClusterRelationships
+	default:
+		if analyzer.ReferencedBy(comp) != "" {
+			tb.buildReferencedRelationships(comp)
+		} else {
+			tb.buildProviderRelationships(comp)
+		}
 	}
 }
 
 func (tb *TreeBuilder) buildMachineRelationships(machine *analyzer.Component) {
-	if spec, ok := machine.Metadata["spec"].(map[string]interface{}); ok {
-		// Link to cluster
-		if clusterName, found, _ := unstructured.NestedString(spec, "clusterName"); found {
-			if cluster := tb.findComponent(clusterName, machine.Namespace, analyzer.ClusterType); cluster != nil {
-				tb.setParentChild(cluster, machine)
-			}
-		}
-
-		// Link to infrastructure (Metal3Machine)
-		if infraRef, found, _ := unstructured.NestedMap(spec, "infrastructureRef"); found {
-			if name, ok := infraRef["name"].(string); ok {
-				if infraMachine := tb.findComponent(name, machine.Namespace, analyzer.Metal3MachineType); infraMachine != nil {
-					tb.setParentChild(machine, infraMachine)
-				}
-			}
-		}
+	if _, ok := machine.Metadata["spec"].(map[string]interface{}); ok {
+		// Link to infrastructure machine of any provider
+		tb.linkRef(machine, RelationInfrastructureRef, "infrastructureRef")
 
-		// Link to bootstrap config (KubeadmConfig)
-		if bootstrapRef, found, _ := unstructured.NestedMap(spec, "bootstrap", "configRef"); found {
-			if name, ok := bootstrapRef["name"].(string); ok {
-				if bootstrapConfig := tb.findComponent(name, machine.Namespace, analyzer.KubeadmConfigType); bootstrapConfig != nil {
-					tb.setParentChild(machine, bootstrapConfig)
-				}
-			}
-		}
+		// Link to bootstrap config of any provider
+		tb.linkRef(machine, RelationBootstrap, "bootstrap", "configRef")
 	}
 }
 
 func (tb *TreeBuilder) buildMachineSetRelationships(machineSet *analyzer.Component) {
 	// Find machines that belong to this MachineSet
-	for _, comp := range tb.components {
-		if comp.Type == analyzer.MachineType {
-			if tb.isOwnedBy(comp, machineSet.Name, "MachineSet") {
-				tb.setParentChild(machineSet, comp)
-			}
-		}
+	for _, comp := range tb.ownedBy(machineSet, analyzer.MachineType, false) {
+		tb.setParentChild(machineSet, comp, RelationOwner)
 	}
 }
 
 func (tb *TreeBuilder) buildMachineDeploymentRelationships(machineDeployment *analyzer.Component) {
 	// Find MachineSets that belong to this MachineDeployment
-	for _, comp := range tb.components {
-		if comp.Type == analyzer.MachineSetType {
-			if tb.isOwnedBy(comp, machineDeployment.Name, "MachineDeployment") {
-				tb.setParentChild(machineDeployment, comp)
-			}
-		}
+	for _, comp := range tb.ownedBy(machineDeployment, analyzer.MachineSetType, false) {
+		tb.setParentChild(machineDeployment, comp, RelationOwner)
 	}
 }
 
 func (tb *TreeBuilder) buildMetal3MachineRelationships(metal3Machine *analyzer.Component) {
-	if spec, ok := metal3Machine.Metadata["spec"].(map[string]interface{}); ok {
-		// Link to BareMetalHost
-		if hostSelector, found, _ := unstructured.NestedMap(spec, "hostSelector"); found {
-			// Find BareMetalHost by labels/name
-			bmh := tb.findBareMetalHostBySelector(hostSelector, metal3Machine.Namespace)
-			if bmh != nil {
-				tb.setParentChild(metal3Machine, bmh)
-			}
-		}
+	// Link to the associated BareMetalHost
+	if bmh := tb.findBareMetalHostForMachine(metal3Machine); bmh != nil {
+		tb.setParentChild(metal3Machine, bmh, RelationConsumerRef)
 	}
 }
 
-func (tb *TreeBuilder) buildClusterRelationships(cluster *analyzer.Component) {
-	if spec, ok := cluster.Metadata["spec"].(map[string]interface{}); ok {
-		// Link to infrastructure (Metal3Cluster)
-		if infraRef, found, _ := unstructured.NestedMap(spec, "infrastructureRef"); found {
-			if name, ok := infraRef["name"].(string); ok {
-				if infraCluster := tb.findComponent(name, cluster.Namespace, analyzer.Metal3ClusterType); infraCluster != nil {
-					tb.setParentChild(cluster, infraCluster)
-				}
-			}
+func (tb *TreeBuilder) buildBareMetalHostRelationships(bmh *analyzer.Component) {
+	// Link to the hardware inventory and firmware objects of the host,
+	// which share the name of the host
+	for _, compType := range []analyzer.ComponentType{
+		analyzer.HardwareDataType,
+		analyzer.HostFirmwareSettingsType,
+		analyzer.HostFirmwareComponentsType,
+	} {
+		if comp := tb.findComponent(bmh.Name, bmh.Namespace, compType); comp != nil {
+			tb.setParentChild(bmh, comp, RelationOwner)
 		}
+	}
+}
 
-		// Link to control plane
-		if cpRef, found, _ := unstructured.NestedMap(spec, "controlPlaneRef"); found {
-			if name, ok := cpRef["name"].(string); ok {
-				if controlPlane := tb.findComponent(name, cluster.Namespace, analyzer.KubeadmControlPlaneType); controlPlane != nil {
-					tb.setParentChild(cluster, controlPlane)
-				}
+func (tb *TreeBuilder) buildHostFirmwareSettingsRelationships(settings *analyzer.Component) {
+	// Link to the FirmwareSchema the settings are validated against
+	if status, ok := settings.Metadata["status"].(map[string]interface{}); ok {
+		if name, found, _ := unstructured.NestedString(status, "schema", "name"); found {
+			namespace, _, _ := unstructured.NestedString(status, "schema", "namespace")
+			if namespace == "" {
+				namespace = settings.Namespace
+			}
+			if schema := tb.findComponent(name, namespace, analyzer.FirmwareSchemaType); schema != nil {
+				tb.setParentChild(settings, schema, RelationReference)
 			}
 		}
 	}
 }
 
-func (tb *TreeBuilder) buildKubeadmControlPlaneRelationships(kcp *analyzer.Component) {
-	// Find machines that belong to this KubeadmControlPlane
-	for _, comp := range tb.components {
-		if comp.Type == analyzer.MachineType {
-			if tb.isOwnedBy(comp, kcp.Name, "KubeadmControlPlane") {
-				tb.setParentChild(kcp, comp)
-			}
+func (tb *TreeBuilder) buildMetal3DataClaimRelationships(dataClaim *analyzer.Component) {
+	// Link to the Metal3Machine that created the claim
+	for _, ref := range ownerRefs(dataClaim) {
+		if ref.kind != string(analyzer.Metal3MachineType) {
+			continue
+		}
+		if comp := tb.findComponent(ref.name, dataClaim.Namespace, analyzer.Metal3MachineType); comp != nil {
+			tb.setParentChild(comp, dataClaim, RelationOwner)
 		}
 	}
-}
 
-func (tb *TreeBuilder) findComponent(name, namespace string, compType analyzer.ComponentType) *analyzer.Component {
-	for _, comp := range tb.components {
-		if comp.Name == name && comp.Namespace == namespace && comp.Type == compType {
-			return comp
+	// Link to the rendered Metal3Data
+	if status, ok := dataClaim.Metadata["status"].(map[string]interface{}); ok {
+		if name, found, _ := unstructured.NestedString(status, "renderedData", "name"); found {
+			if data := tb.findComponent(name, dataClaim.Namespace, analyzer.Metal3DataType); data != nil {
+				tb.setParentChild(dataClaim, data, RelationReference)
+			}
 		}
 	}
-	return nil
 }
 
-func (tb *TreeBuilder) findBareMetalHostBySelector(selector map[string]interface{}, namespace string) *analyzer.Component {
-	// Simplified: look for BareMetalHost with matching name or labels
-	for _, comp := range tb.components {
-		if comp.Type == analyzer.BareMetalHostType && comp.Namespace == namespace {
-			// In a real implementation, this would match labels
-			return comp
+func (tb *TreeBuilder) buildMetal3DataRelationships(data *analyzer.Component) {
+	// Link to the claim the data was rendered for
+	if spec, ok := data.Metadata["spec"].(map[string]interface{}); ok {
+		if name, found, _ := unstructured.NestedString(spec, "claim", "name"); found {
+			if dataClaim := tb.findComponent(name, data.Namespace, analyzer.Metal3DataClaimType); dataClaim != nil {
+				tb.setParentChild(dataClaim, data, RelationReference)
+			}
 		}
 	}
-	return nil
+
+	// Find IPClaims created while rendering the data
+	for _, comp := range tb.ownedBy(data, analyzer.IPClaimType, false) {
+		tb.setParentChild(data, comp, RelationOwner)
+	}
 }
 
-func (tb *TreeBuilder) isOwnedBy(comp *analyzer.Component, ownerName, ownerKind string) bool {
-	if metadata, ok := comp.Metadata["metadata"].(map[string]interface{}); ok {
-		if ownerRefs, found, _ := unstructured.NestedSlice(metadata, "ownerReferences"); found {
-			for _, ref := range ownerRefs {
-				if refMap, ok := ref.(map[string]interface{}); ok {
-					if name, ok := refMap["name"].(string); ok && name == ownerName {
-						if kind, ok := refMap["kind"].(string); ok && kind == ownerKind {
-							return true
-						}
-					}
-				}
+func (tb *TreeBuilder) buildIPClaimRelationships(ipClaim *analyzer.Component) {
+	// Link to the allocated IPAddress
+	if status, ok := ipClaim.Metadata["status"].(map[string]interface{}); ok {
+		if name, found, _ := unstructured.NestedString(status, "address", "name"); found {
+			if address := tb.findComponent(name, ipClaim.Namespace, analyzer.IPAddressType); address != nil {
+				tb.setParentChild(ipClaim, address, RelationReference)
 			}
 		}
 	}
-	return false
 }
 
-func (tb *TreeBuilder) setParentChild(parent, child *analyzer.Component) {
-	if child.Parent == nil {
-		child.Parent = parent
-		parent.Children = append(parent.Children, child)
+func (tb *TreeBuilder) buildClusterRelationships(cluster *analyzer.Component) {
+	// Link to infrastructure cluster of any provider
+	tb.linkRef(cluster, RelationInfrastructureRef, "infrastructureRef")
+
+	// Link to control plane of any provider
+	tb.linkRef(cluster, RelationControlPlaneRef, "controlPlaneRef")
+
+	// Link to the ClusterClass of a managed topology
+	if className, classNamespace := analyzer.ClusterClassRef(cluster); className != "" {
+		if clusterClass := tb.findComponent(className, classNamespace, analyzer.ClusterClassType); clusterClass != nil {
+			tb.setParentChild(cluster, clusterClass, RelationReference)
+		}
+	}
+
+	// Link to the objects naming the cluster in spec.clusterName. Machines
+	// managed by a MachineSet, control plane or MachinePool are shown under
+	// their owner instead
+	for _, member := range tb.index.byCluster.Members(cluster.Namespace, cluster.Name) {
+		if !clusterNameKinds[member.Type] {
+			continue
+		}
+		spec, _ := member.Metadata["spec"].(map[string]interface{})
+		if clusterName, _, _ := unstructured.NestedString(spec, "clusterName"); clusterName == cluster.Name {
+			tb.setParentChild(cluster, member, RelationCluster)
+		}
 	}
 }
 
-func (tb *TreeBuilder) getComponentKey(comp *analyzer.Component) string {
-	return comp.Namespace + "/" + comp.Name + "/" + string(comp.Type)
+// clusterNameKinds are the kinds linked to the Cluster named in their
+// spec.clusterName.
+var clusterNameKinds = map[analyzer.ComponentType]bool{
+	analyzer.MachineType:            true,
+	analyzer.MachinePoolType:        true,
+	analyzer.IPPoolType:             true,
+	analyzer.Metal3DataTemplateType: true,
 }
 
-func (tb *TreeBuilder) PrintTree(components []*analyzer.Component) string {
-	var result strings.Builder
-	for _, comp := range components {
-		tb.printComponent(&result, comp, 0)
+func (tb *TreeBuilder) buildClusterClassRelationships(clusterClass *analyzer.Component) {
+	// Link to the templates referenced by the ClusterClass
+	for _, ref := range analyzer.ClusterClassTemplateRefs(clusterClass) {
+		if template := tb.findComponent(ref.Name, ref.Namespace, analyzer.ComponentType(ref.Kind)); template != nil {
+			tb.setParentChild(clusterClass, template, RelationReference)
+		}
 	}
-	return result.String()
 }
 
-func (tb *TreeBuilder) printComponent(result *strings.Builder, comp *analyzer.Component, depth int) {
-	indent := strings.Repeat("  ", depth)
-	statusIcon := tb.getStatusIcon(comp.Status)
+func (tb *TreeBuilder) buildKubeadmControlPlaneRelationships(kcp *analyzer.Component) {
+	// Find machines that belong to this KubeadmControlPlane
+	for _, comp := range tb.ownedBy(kcp, analyzer.MachineType, false) {
+		tb.setParentChild(kcp, comp, RelationOwner)
+	}
+}
 
-	result.WriteString(fmt.Sprintf("%s%s %s/%s (%s)\n",
-		indent, statusIcon, comp.Type, comp.Name, comp.Status))
+func (tb *TreeBuilder) buildMachinePoolRelationships(machinePool *analyzer.Component) {
+	if _, ok := machinePool.Metadata["spec"].(map[string]interface{}); ok {
+		// Link to infrastructure machine pool (DockerMachinePool, AWSMachinePool, ...)
+		tb.linkRef(machinePool, RelationInfrastructureRef, "template", "spec", "infrastructureRef")
 
-	// Print conditions
-	for _, condition := range comp.Conditions {
-		conditionIcon := "?"
-		if condition.Status == metav1.ConditionTrue {
-			conditionIcon = "✓"
-		} else if condition.Status == metav1.ConditionFalse {
-			conditionIcon = "✗"
-		}
-		result.WriteString(fmt.Sprintf("%s  %s %s: %s\n",
-			indent, conditionIcon, condition.Type, condition.Message))
+		// Link to bootstrap config of any provider
+		tb.linkRef(machinePool, RelationBootstrap, "template", "spec", "bootstrap", "configRef")
 	}
 
-	// Print children
-	for _, child := range comp.Children {
-		tb.printComponent(result, child, depth+1)
+	// Find MachinePool machines that belong to this MachinePool
+	for _, comp := range tb.ownedBy(machinePool, analyzer.MachineType, false) {
+		tb.setParentChild(machinePool, comp, RelationOwner)
 	}
 }
 
-func (tb *TreeBuilder) getStatusIcon(status analyzer.ComponentStatus) string {
-	switch status {
-	case analyzer.StatusHealthy:
-		return "✅"
-	case analyzer.StatusDegraded:
-		return "⚠️"
-	case analyzer.StatusFailed:
-		return "❌"
-	case analyzer.StatusPending:
-		return "⏳"
-	default:
-		return "❓"
+func (tb *TreeBuilder) findComponent(name, namespace string, compType analyzer.ComponentType) *analyzer.Component {
+	return tb.components[namespace+"/"+name+"/"+string(compType)]
+}
+
+func (tb *TreeBuilder) findBareMetalHostForMachine(metal3Machine *analyzer.Component) *analyzer.Component {
+	// CAPM3 annotates the Metal3Machine with the associated host
+	if namespace, name := analyzer.AnnotatedHost(metal3Machine); name != "" {
+		if bmh := tb.findComponent(name, namespace, analyzer.BareMetalHostType); bmh != nil {
+			return bmh
+		}
 	}
-}
\ No newline at end of file
+
+	// Fall back to the consumerRef of the host
+	return tb.index.hostConsumers[tb.getComponentKey(metal3Machine)]
+}
+
+// setParentChild records a link from parent to child. The tree is linked
+// once all links are known, see linkPrimaryParents.
+func (tb *TreeBuilder) setParentChild(parent, child *analyzer.Component, relation Relation) {
+	tb.addEdge(parent, child, relation)
+}
+
+// Relation returns how a component is linked to its primary parent, empty
+// for roots.
+func (tb *TreeBuilder) Relation(child *analyzer.Component) Relation {
+	return tb.relations[tb.getComponentKey(child)]
+}
+
+func (tb *TreeBuilder) getComponentKey(comp *analyzer.Component) string {
+	return comp.Namespace + "/" + comp.Name + "/" + string(comp.Type)
+}
+
+// PrintTree renders the trees below the given roots as text, filtered and
+// condensed as set in PrintOptions.
+func (tb *TreeBuilder) PrintTree(components []*analyzer.Component) string {
+	var result strings.Builder
+	p := newTreePrinter(tb.PrintOptions)
+	p.printChildren(&result, components, 0)
+	return result.String()
+}
@@ -0,0 +1,129 @@
+package tree
+
+import (
+	"sort"
+
+	"capi-advisor/pkg/analyzer"
+)
+
+// Edge is a labelled link from a parent to a child. Components may have
+// several parents, e.g. a Machine is owned by its MachineSet and belongs to
+// its Cluster, so the links form a graph. The tree shows each component
+// under one of them, its primary parent.
+type Edge struct {
+	Parent   *analyzer.Component
+	Child    *analyzer.Component
+	Relation Relation
+	// Primary marks the edge from the parent the child is shown under
+	Primary bool
+}
+
+// relationPriority orders the parents of a component to pick the primary
+// one: the most specific link wins, the Cluster a component belongs to is
+// the fallback.
+var relationPriority = map[Relation]int{
+	RelationOwner:             0,
+	RelationControlPlaneRef:   1,
+	RelationInfrastructureRef: 2,
+	RelationBootstrap:         3,
+	RelationConsumerRef:       4,
+	RelationReference:         5,
+	RelationCluster:           6,
+}
+
+// addEdge records a link, once per parent, child and relation.
+func (tb *TreeBuilder) addEdge(parent, child *analyzer.Component, relation Relation) {
+	if parent == child {
+		return
+	}
+	key := tb.getComponentKey(parent) + "|" + tb.getComponentKey(child) + "|" + string(relation)
+	if tb.edgeKeys[key] {
+		return
+	}
+	tb.edgeKeys[key] = true
+	tb.edges = append(tb.edges, Edge{Parent: parent, Child: child, Relation: relation})
+}
+
+// linkPrimaryParents sorts the edges and sets the Parent and Children of
+// every component from its primary parent. Parents are ranked by
+// relationPriority, then by key, and skipped when they would close a cycle,
+// so the tree is the same however the components were ordered.
+func (tb *TreeBuilder) linkPrimaryParents() {
+	sort.SliceStable(tb.edges, func(i, j int) bool {
+		a, b := tb.edges[i], tb.edges[j]
+		if keyA, keyB := tb.getComponentKey(a.Child), tb.getComponentKey(b.Child); keyA != keyB {
+			return keyA < keyB
+		}
+		if a.Relation != b.Relation {
+			return relationPriority[a.Relation] < relationPriority[b.Relation]
+		}
+		return tb.getComponentKey(a.Parent) < tb.getComponentKey(b.Parent)
+	})
+
+	for i := range tb.edges {
+		edge := &tb.edges[i]
+		if edge.Child.Parent != nil || tb.isAncestor(edge.Child, edge.Parent) {
+			continue
+		}
+		edge.Primary = true
+		edge.Child.Parent = edge.Parent
+		edge.Parent.Children = append(edge.Parent.Children, edge.Child)
+		tb.relations[tb.getComponentKey(edge.Child)] = edge.Relation
+	}
+
+	for _, edge := range tb.edges {
+		if edge.Primary {
+			tb.parentEdges[edge.Child] = append([]Edge{edge}, tb.parentEdges[edge.Child]...)
+		} else {
+			tb.parentEdges[edge.Child] = append(tb.parentEdges[edge.Child], edge)
+		}
+		tb.childEdges[edge.Parent] = append(tb.childEdges[edge.Parent], edge)
+	}
+}
+
+// isAncestor reports whether comp is desc or one of its primary parents.
+func (tb *TreeBuilder) isAncestor(comp, desc *analyzer.Component) bool {
+	for current := desc; current != nil; current = current.Parent {
+		if current == comp {
+			return true
+		}
+	}
+	return false
+}
+
+// Edges returns all links between the components, ordered by child, then
+// by the rank of the parent. Exporters use them to draw every relationship
+// rather than only the tree.
+func (tb *TreeBuilder) Edges() []Edge {
+	return tb.edges
+}
+
+// ParentEdges returns the links from all parents of a component, the
+// primary parent first.
+func (tb *TreeBuilder) ParentEdges(comp *analyzer.Component) []Edge {
+	return tb.parentEdges[comp]
+}
+
+// ChildEdges returns the links to all children of a component.
+func (tb *TreeBuilder) ChildEdges(comp *analyzer.Component) []Edge {
+	return tb.childEdges[comp]
+}
+
+// Parents returns all parents of a component, the primary parent first.
+func (tb *TreeBuilder) Parents(comp *analyzer.Component) []*analyzer.Component {
+	var parents []*analyzer.Component
+	for _, edge := range tb.parentEdges[comp] {
+		parents = append(parents, edge.Parent)
+	}
+	return parents
+}
+
+// Children returns all children of a component, including those shown
+// under another parent.
+func (tb *TreeBuilder) Children(comp *analyzer.Component) []*analyzer.Component {
+	var children []*analyzer.Component
+	for _, edge := range tb.childEdges[comp] {
+		children = append(children, edge.Child)
+	}
+	return children
+}
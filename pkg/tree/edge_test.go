@@ -0,0 +1,106 @@
+package tree
+
+import (
+	"reflect"
+	"testing"
+
+	"capi-advisor/pkg/analyzer"
+)
+
+func TestLinkPrimaryParents(t *testing.T) {
+	type link struct {
+		parent, child string
+		relation      Relation
+	}
+	type primary struct {
+		parent   string
+		relation Relation
+	}
+
+	tests := []struct {
+		name  string
+		links []link
+		want  map[string]primary
+	}{
+		{
+			name: "owner before cluster",
+			links: []link{
+				{"cluster", "machine", RelationCluster},
+				{"machineset", "machine", RelationOwner},
+			},
+			want: map[string]primary{"machine": {"machineset", RelationOwner}, "cluster": {}, "machineset": {}},
+		},
+		{
+			name: "consumerRef before reference",
+			links: []link{
+				{"provider", "host", RelationReference},
+				{"metal3machine", "host", RelationConsumerRef},
+			},
+			want: map[string]primary{"host": {"metal3machine", RelationConsumerRef}, "provider": {}, "metal3machine": {}},
+		},
+		{
+			name: "tie broken by parent key",
+			links: []link{
+				{"b", "child", RelationReference},
+				{"a", "child", RelationReference},
+			},
+			want: map[string]primary{"child": {"a", RelationReference}, "a": {}, "b": {}},
+		},
+		{
+			name: "cycle of two",
+			links: []link{
+				{"a", "b", RelationOwner},
+				{"b", "a", RelationReference},
+			},
+			want: map[string]primary{"a": {"b", RelationReference}, "b": {}},
+		},
+		{
+			name: "cycle of three",
+			links: []link{
+				{"a", "b", RelationOwner},
+				{"b", "c", RelationOwner},
+				{"c", "a", RelationOwner},
+			},
+			want: map[string]primary{"a": {"c", RelationOwner}, "b": {"a", RelationOwner}, "c": {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The primary parents do not depend on the order links are found in
+			for _, reverse := range []bool{false, true} {
+				tb := NewTreeBuilder()
+				components := make(map[string]*analyzer.Component)
+				component := func(name string) *analyzer.Component {
+					if components[name] == nil {
+						components[name] = &analyzer.Component{Name: name, Namespace: "default", Type: "Widget"}
+					}
+					return components[name]
+				}
+				for i := range tt.links {
+					l := tt.links[i]
+					if reverse {
+						l = tt.links[len(tt.links)-1-i]
+					}
+					tb.addEdge(component(l.parent), component(l.child), l.relation)
+				}
+				tb.linkPrimaryParents()
+
+				got := make(map[string]primary)
+				for name, comp := range components {
+					if comp.Parent == nil {
+						got[name] = primary{}
+						continue
+					}
+					got[name] = primary{comp.Parent.Name, tb.Relation(comp)}
+					if edges := tb.ParentEdges(comp); !edges[0].Primary || edges[0].Parent != comp.Parent {
+						t.Errorf("ParentEdges(%s) do not start with the primary parent: %+v", name, edges)
+					}
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("reverse=%t: primary parents = %+v, want %+v", reverse, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package tree
+
+import (
+	"sort"
+
+	"capi-advisor/pkg/analyzer"
+)
+
+// ownerRef is an ownerReference of a component.
+type ownerRef struct {
+	kind       string
+	name       string
+	uid        string
+	controller bool
+}
+
+// owned is a component with the ownerReference that points to the owner it
+// is indexed under.
+type owned struct {
+	comp *analyzer.Component
+	ref  ownerRef
+}
+
+// index gives the lookups the relationship builders need without scanning
+// all components, which is quadratic on management clusters with thousands
+// of objects. Lists are sorted by component key so lookups do not depend on
+// the order components were discovered in.
+type index struct {
+	// byOwnerUID holds components by the UID of their owners
+	byOwnerUID map[string][]owned
+	// byOwnerName holds components by the namespace/name/kind of owners, for
+	// ownerReferences without a UID and owners whose UID is unknown
+	byOwnerName map[string][]owned
+	// byCluster holds components by the Cluster named in their
+	// spec.clusterName or cluster-name label
+	byCluster *analyzer.ClusterIndex
+	// hostConsumers holds BareMetalHosts by the key of their consumer
+	hostConsumers map[string]*analyzer.Component
+}
+
+// buildIndex indexes the components by key, owner, Cluster and consumer.
+func (tb *TreeBuilder) buildIndex(components []*analyzer.Component) {
+	sorted := append([]*analyzer.Component(nil), components...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return tb.getComponentKey(sorted[i]) < tb.getComponentKey(sorted[j])
+	})
+
+	tb.index = index{
+		byOwnerUID:    make(map[string][]owned),
+		byOwnerName:   make(map[string][]owned),
+		byCluster:     analyzer.NewClusterIndex(sorted),
+		hostConsumers: make(map[string]*analyzer.Component),
+	}
+	for _, comp := range sorted {
+		tb.components[tb.getComponentKey(comp)] = comp
+
+		for _, ref := range ownerRefs(comp) {
+			if ref.uid != "" {
+				tb.index.byOwnerUID[ref.uid] = append(tb.index.byOwnerUID[ref.uid], owned{comp, ref})
+			}
+			key := comp.Namespace + "/" + ref.name + "/" + ref.kind
+			tb.index.byOwnerName[key] = append(tb.index.byOwnerName[key], owned{comp, ref})
+		}
+
+		if comp.Type == analyzer.BareMetalHostType {
+			if kind, namespace, name := analyzer.HostConsumer(comp); kind != "" && name != "" {
+				key := namespace + "/" + name + "/" + kind
+				if _, found := tb.index.hostConsumers[key]; !found {
+					tb.index.hostConsumers[key] = comp
+				}
+			}
+		}
+	}
+}
+
+// ownedBy returns the components of a type owned by a component, matched by
+// the UID of the owner or, for ownerReferences without a UID and owners
+// whose UID is unknown, by its kind and name. An ownerReference to an older
+// object of the same name does not match. With controller set only
+// controlling owners count.
+func (tb *TreeBuilder) ownedBy(owner *analyzer.Component, compType analyzer.ComponentType, controller bool) []*analyzer.Component {
+	var candidates []owned
+	byName := tb.index.byOwnerName[owner.Namespace+"/"+owner.Name+"/"+string(owner.Type)]
+	if uid := analyzer.UID(owner); uid != "" {
+		candidates = append(candidates, tb.index.byOwnerUID[uid]...)
+		for _, candidate := range byName {
+			if candidate.ref.uid == "" {
+				candidates = append(candidates, candidate)
+			}
+		}
+	} else {
+		candidates = byName
+	}
+
+	var result []*analyzer.Component
+	for _, candidate := range candidates {
+		if compType != "" && candidate.comp.Type != compType {
+			continue
+		}
+		if candidate.ref.kind != string(owner.Type) || (controller && !candidate.ref.controller) {
+			continue
+		}
+		result = append(result, candidate.comp)
+	}
+	return result
+}
+
+// ownerRefs returns the ownerReferences of a component.
+func ownerRefs(comp *analyzer.Component) []ownerRef {
+	metadata, _ := comp.Metadata["metadata"].(map[string]interface{})
+	refs, _ := metadata["ownerReferences"].([]interface{})
+
+	var result []ownerRef
+	for _, ref := range refs {
+		refMap, ok := ref.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _ := refMap["kind"].(string)
+		name, _ := refMap["name"].(string)
+		uid, _ := refMap["uid"].(string)
+		controller, _ := refMap["controller"].(bool)
+		if kind == "" || name == "" {
+			continue
+		}
+		result = append(result, ownerRef{kind: kind, name: name, uid: uid, controller: controller})
+	}
+	return result
+}
@@ -0,0 +1,179 @@
+package tree
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"capi-advisor/pkg/analyzer"
+)
+
+// machinesPerCluster is the number of Machines of a synthetic Cluster, each
+// with a Metal3Machine and a BareMetalHost.
+const machinesPerCluster = 10
+
+// componentsPerCluster is the number of components of a synthetic Cluster:
+// the Cluster, a MachineDeployment, a MachineSet and the Machines with their
+// Metal3Machine and BareMetalHost.
+const componentsPerCluster = 3 + 3*machinesPerCluster
+
+func newComponent(compType analyzer.ComponentType, namespace, name string) *analyzer.Component {
+	return &analyzer.Component{
+		Name:      name,
+		Namespace: namespace,
+		Type:      compType,
+		GVK:       analyzer.SupportedGVKs[compType],
+		Status:    analyzer.StatusHealthy,
+		Metadata: map[string]interface{}{
+			"metadata": map[string]interface{}{"uid": "uid-" + namespace + "-" + string(compType) + "-" + name},
+			"spec":     map[string]interface{}{},
+		},
+	}
+}
+
+func setOwner(comp, owner *analyzer.Component, uid string) {
+	ref := map[string]interface{}{
+		"apiVersion": owner.GVK.GroupVersion().String(),
+		"kind":       string(owner.Type),
+		"name":       owner.Name,
+		"controller": true,
+	}
+	if uid != "" {
+		ref["uid"] = uid
+	}
+	metadata := comp.Metadata["metadata"].(map[string]interface{})
+	refs, _ := metadata["ownerReferences"].([]interface{})
+	metadata["ownerReferences"] = append(refs, ref)
+}
+
+func objectRef(comp *analyzer.Component) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": comp.GVK.GroupVersion().String(),
+		"kind":       string(comp.Type),
+		"name":       comp.Name,
+		"namespace":  comp.Namespace,
+	}
+}
+
+// syntheticComponents returns at least n components of Clusters with a
+// MachineDeployment, a MachineSet and Metal3 Machines, one namespace per
+// Cluster. The BareMetalHosts are linked through their consumerRef only.
+func syntheticComponents(n int) []*analyzer.Component {
+	var components []*analyzer.Component
+	for c := 0; len(components) < n; c++ {
+		namespace := fmt.Sprintf("ns-%d", c)
+		cluster := newComponent(analyzer.ClusterType, namespace, "cluster")
+		md := newComponent(analyzer.MachineDeploymentType, namespace, "workers")
+		ms := newComponent(analyzer.MachineSetType, namespace, "workers-abc")
+		setOwner(ms, md, analyzer.UID(md))
+		components = append(components, cluster, md, ms)
+
+		for m := 0; m < machinesPerCluster; m++ {
+			name := fmt.Sprintf("workers-abc-%d", m)
+			machine := newComponent(analyzer.MachineType, namespace, name)
+			metal3Machine := newComponent(analyzer.Metal3MachineType, namespace, name)
+			host := newComponent(analyzer.BareMetalHostType, namespace, fmt.Sprintf("host-%d", m))
+
+			setOwner(machine, ms, analyzer.UID(ms))
+			machine.Metadata["spec"] = map[string]interface{}{
+				"clusterName":       cluster.Name,
+				"infrastructureRef": objectRef(metal3Machine),
+			}
+			host.Metadata["spec"] = map[string]interface{}{"consumerRef": objectRef(metal3Machine)}
+			components = append(components, machine, metal3Machine, host)
+		}
+	}
+	return components
+}
+
+func BenchmarkBuildTree(b *testing.B) {
+	for _, n := range []int{1000, 10000, 50000} {
+		b.Run(fmt.Sprintf("components=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				components := syntheticComponents(n)
+				b.StartTimer()
+				NewTreeBuilder().BuildDependencyTree(components)
+			}
+		})
+	}
+}
+
+func TestBuildTreeLinksSyntheticClusters(t *testing.T) {
+	components := syntheticComponents(10000)
+	tb := NewTreeBuilder()
+	roots := tb.BuildDependencyTree(components)
+
+	clusters := len(components) / componentsPerCluster
+	// Clusters and MachineDeployments, which have no parent
+	if len(roots) != 2*clusters {
+		t.Errorf("got %d roots, want %d", len(roots), 2*clusters)
+	}
+	for _, comp := range components {
+		switch comp.Type {
+		case analyzer.MachineType:
+			if comp.Parent == nil || comp.Parent.Type != analyzer.MachineSetType || tb.Relation(comp) != RelationOwner {
+				t.Fatalf("Machine %s/%s is not owned by its MachineSet", comp.Namespace, comp.Name)
+			}
+		case analyzer.BareMetalHostType:
+			if comp.Parent == nil || comp.Parent.Type != analyzer.Metal3MachineType || tb.Relation(comp) != RelationConsumerRef {
+				t.Fatalf("BareMetalHost %s/%s is not linked to its Metal3Machine", comp.Namespace, comp.Name)
+			}
+		}
+	}
+}
+
+func TestBuildTreeIsDeterministic(t *testing.T) {
+	build := func(components []*analyzer.Component) string {
+		tb := NewTreeBuilder()
+		roots := tb.BuildDependencyTree(components)
+		var out strings.Builder
+		out.WriteString(tb.PrintTree(roots))
+		for _, edge := range tb.Edges() {
+			fmt.Fprintf(&out, "%s -> %s %s %t\n", tb.getComponentKey(edge.Parent), tb.getComponentKey(edge.Child), edge.Relation, edge.Primary)
+		}
+		return out.String()
+	}
+
+	want := build(syntheticComponents(2000))
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 5; i++ {
+		components := syntheticComponents(2000)
+		random.Shuffle(len(components), func(i, j int) {
+			components[i], components[j] = components[j], components[i]
+		})
+		if got := build(components); got != want {
+			t.Fatalf("shuffle %d: output differs from the output for the ordered components", i)
+		}
+	}
+}
+
+func TestOwnerMatching(t *testing.T) {
+	tests := []struct {
+		name      string
+		ownerUID  string
+		refUID    string
+		wantOwned bool
+	}{
+		{name: "reference to the owner UID", ownerUID: "uid-1", refUID: "uid-1", wantOwned: true},
+		{name: "reference to a recreated owner of the same name", ownerUID: "uid-2", refUID: "uid-1", wantOwned: false},
+		{name: "reference without UID", ownerUID: "uid-1", refUID: "", wantOwned: true},
+		{name: "owner without UID", ownerUID: "", refUID: "uid-1", wantOwned: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			machineSet := newComponent(analyzer.MachineSetType, "default", "workers")
+			machineSet.Metadata["metadata"].(map[string]interface{})["uid"] = tt.ownerUID
+			machine := newComponent(analyzer.MachineType, "default", "workers-0")
+			setOwner(machine, machineSet, tt.refUID)
+
+			tb := NewTreeBuilder()
+			tb.BuildDependencyTree([]*analyzer.Component{machine, machineSet})
+			if owned := machine.Parent == machineSet; owned != tt.wantOwned {
+				t.Errorf("Machine owned by MachineSet = %t, want %t", owned, tt.wantOwned)
+			}
+		})
+	}
+}
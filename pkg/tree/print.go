@@ -0,0 +1,180 @@
+package tree
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/render"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition display modes of PrintOptions.
+const (
+	ShowConditionsAll     = "all"
+	ShowConditionsFailing = "failing"
+	ShowConditionsNone    = "none"
+)
+
+// PrintOptions make large trees readable. The zero value prints every
+// component with all its conditions.
+type PrintOptions struct {
+	// OnlyFailing prints only the paths leading to components that are not Healthy
+	OnlyFailing bool
+	// CollapseHealthy replaces subtrees without any unhealthy component by a count
+	CollapseHealthy bool
+	// Depth limits the number of levels printed, 0 prints all
+	Depth int
+	// ShowConditions is ShowConditionsAll, ShowConditionsFailing or
+	// ShowConditionsNone; empty shows all
+	ShowConditions string
+	// Types prints only the paths leading to components of these kinds
+	Types []analyzer.ComponentType
+	// ExcludeTypes hides components of these kinds along with their subtrees
+	ExcludeTypes []analyzer.ComponentType
+}
+
+// treePrinter renders a tree with PrintOptions, memoizing per subtree
+// whether it is printed and whether it is healthy.
+type treePrinter struct {
+	options PrintOptions
+	types   map[analyzer.ComponentType]bool
+	exclude map[analyzer.ComponentType]bool
+	visible map[*analyzer.Component]bool
+	healthy map[*analyzer.Component]bool
+}
+
+func newTreePrinter(options PrintOptions) *treePrinter {
+	p := &treePrinter{
+		options: options,
+		types:   make(map[analyzer.ComponentType]bool),
+		exclude: make(map[analyzer.ComponentType]bool),
+		visible: make(map[*analyzer.Component]bool),
+		healthy: make(map[*analyzer.Component]bool),
+	}
+	for _, compType := range options.Types {
+		p.types[compType] = true
+	}
+	for _, compType := range options.ExcludeTypes {
+		p.exclude[compType] = true
+	}
+	return p
+}
+
+// isVisible reports whether a component is printed: it matches the filters
+// itself or lies on the path to a component that does.
+func (p *treePrinter) isVisible(comp *analyzer.Component) bool {
+	if visible, found := p.visible[comp]; found {
+		return visible
+	}
+	visible := false
+	if !p.exclude[comp.Type] {
+		visible = (len(p.types) == 0 || p.types[comp.Type]) &&
+			(!p.options.OnlyFailing || comp.Status != analyzer.StatusHealthy)
+		// Children are checked even when the component matches to fill the memo
+		for _, child := range comp.Children {
+			if p.isVisible(child) {
+				visible = true
+			}
+		}
+	}
+	p.visible[comp] = visible
+	return visible
+}
+
+// isHealthy reports whether a component and its whole subtree are Healthy.
+func (p *treePrinter) isHealthy(comp *analyzer.Component) bool {
+	if healthy, found := p.healthy[comp]; found {
+		return healthy
+	}
+	healthy := comp.Status == analyzer.StatusHealthy
+	for _, child := range comp.Children {
+		if !p.isHealthy(child) {
+			healthy = false
+		}
+	}
+	p.healthy[comp] = healthy
+	return healthy
+}
+
+// printChildren prints the visible components of one level, with the
+// healthy subtrees among them collapsed into a single line.
+func (p *treePrinter) printChildren(result *strings.Builder, components []*analyzer.Component, depth int) {
+	collapsed := make(map[analyzer.ComponentType]int)
+	for _, comp := range components {
+		if !p.isVisible(comp) {
+			continue
+		}
+		if p.options.CollapseHealthy && p.isHealthy(comp) {
+			p.countSubtree(comp, collapsed)
+			continue
+		}
+		p.printComponent(result, comp, depth)
+	}
+
+	if len(collapsed) > 0 {
+		total := 0
+		types := make([]string, 0, len(collapsed))
+		for compType, count := range collapsed {
+			total += count
+			types = append(types, fmt.Sprintf("%s: %d", compType, count))
+		}
+		sort.Strings(types)
+		result.WriteString(fmt.Sprintf("%s%s %d healthy components collapsed (%s)\n",
+			strings.Repeat("  ", depth), render.StatusIcon(analyzer.StatusHealthy), total, strings.Join(types, ", ")))
+	}
+}
+
+func (p *treePrinter) countSubtree(comp *analyzer.Component, counts map[analyzer.ComponentType]int) {
+	counts[comp.Type]++
+	for _, child := range comp.Children {
+		if !p.exclude[child.Type] {
+			p.countSubtree(child, counts)
+		}
+	}
+}
+
+func (p *treePrinter) printComponent(result *strings.Builder, comp *analyzer.Component, depth int) {
+	indent := strings.Repeat("  ", depth)
+	statusIcon := render.StatusIcon(comp.Status)
+
+	result.WriteString(fmt.Sprintf("%s%s %s/%s (%s)\n",
+		indent, statusIcon, comp.Type, comp.Name, render.Status(comp.Status, string(comp.Status))))
+
+	// Print why a referenced object could not be fetched
+	if lookupErr, ok := analyzer.ReferenceLookupError(comp); ok {
+		result.WriteString(fmt.Sprintf("%s  %s %s: %s\n", indent, render.ConditionIcon(metav1.ConditionFalse), lookupErr.Reason, lookupErr.Message))
+	}
+
+	// Print conditions
+	for _, condition := range comp.Conditions {
+		switch p.options.ShowConditions {
+		case ShowConditionsNone:
+			continue
+		case ShowConditionsFailing:
+			if condition.Status == metav1.ConditionTrue {
+				continue
+			}
+		}
+		conditionIcon := render.ConditionIcon(condition.Status)
+		result.WriteString(fmt.Sprintf("%s  %s %s: %s\n",
+			indent, conditionIcon, condition.Type, condition.Message))
+	}
+
+	// Print children, or how many are hidden below the depth limit
+	if p.options.Depth > 0 && depth+1 >= p.options.Depth {
+		hidden := 0
+		for _, child := range comp.Children {
+			if p.isVisible(child) {
+				hidden++
+			}
+		}
+		if hidden > 0 {
+			result.WriteString(fmt.Sprintf("%s  ... %d children below depth %d\n", indent, hidden, p.options.Depth))
+		}
+		return
+	}
+	p.printChildren(result, comp.Children, depth+1)
+}
@@ -0,0 +1,132 @@
+package tree
+
+import (
+	"testing"
+
+	"capi-advisor/pkg/analyzer"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// printTree returns a Cluster with a healthy control plane and a degraded
+// MachineDeployment with one failed Machine, linked by Children only.
+func printTree() []*analyzer.Component {
+	node := func(compType analyzer.ComponentType, name string, status analyzer.ComponentStatus, children ...*analyzer.Component) *analyzer.Component {
+		comp := &analyzer.Component{Name: name, Namespace: "default", Type: compType, Status: status, Children: children}
+		for _, child := range children {
+			child.Parent = comp
+		}
+		return comp
+	}
+
+	cp0 := node(analyzer.MachineType, "cp-0", analyzer.StatusHealthy)
+	cp0.Conditions = []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, Message: "ready"}}
+	worker0 := node(analyzer.MachineType, "worker-0", analyzer.StatusFailed, node(analyzer.Metal3MachineType, "worker-0", analyzer.StatusFailed))
+	worker0.Conditions = []metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionFalse, Message: "host deprovisioned"},
+		{Type: "BootstrapReady", Status: metav1.ConditionTrue, Message: "bootstrapped"},
+	}
+	worker1 := node(analyzer.MachineType, "worker-1", analyzer.StatusHealthy, node(analyzer.Metal3MachineType, "worker-1", analyzer.StatusHealthy))
+
+	return []*analyzer.Component{node(analyzer.ClusterType, "cluster", analyzer.StatusDegraded,
+		node(analyzer.KubeadmControlPlaneType, "cp", analyzer.StatusHealthy, cp0),
+		node(analyzer.MachineDeploymentType, "workers", analyzer.StatusDegraded,
+			node(analyzer.MachineSetType, "workers-abc", analyzer.StatusHealthy, worker0, worker1)),
+	)}
+}
+
+func TestPrintTreeOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options PrintOptions
+		want    string
+	}{
+		{
+			name: "all components",
+			want: `⚠️ Cluster/cluster (Degraded)
+  ✅ KubeadmControlPlane/cp (Healthy)
+    ✅ Machine/cp-0 (Healthy)
+      ✓ Ready: ready
+  ⚠️ MachineDeployment/workers (Degraded)
+    ✅ MachineSet/workers-abc (Healthy)
+      ❌ Machine/worker-0 (Failed)
+        ✗ Ready: host deprovisioned
+        ✓ BootstrapReady: bootstrapped
+        ❌ Metal3Machine/worker-0 (Failed)
+      ✅ Machine/worker-1 (Healthy)
+        ✅ Metal3Machine/worker-1 (Healthy)
+`,
+		},
+		{
+			name:    "only failing",
+			options: PrintOptions{OnlyFailing: true, ShowConditions: ShowConditionsNone},
+			want: `⚠️ Cluster/cluster (Degraded)
+  ⚠️ MachineDeployment/workers (Degraded)
+    ✅ MachineSet/workers-abc (Healthy)
+      ❌ Machine/worker-0 (Failed)
+        ❌ Metal3Machine/worker-0 (Failed)
+`,
+		},
+		{
+			name:    "collapse healthy",
+			options: PrintOptions{CollapseHealthy: true, ShowConditions: ShowConditionsFailing},
+			want: `⚠️ Cluster/cluster (Degraded)
+  ⚠️ MachineDeployment/workers (Degraded)
+    ✅ MachineSet/workers-abc (Healthy)
+      ❌ Machine/worker-0 (Failed)
+        ✗ Ready: host deprovisioned
+        ❌ Metal3Machine/worker-0 (Failed)
+      ✅ 2 healthy components collapsed (Machine: 1, Metal3Machine: 1)
+  ✅ 2 healthy components collapsed (KubeadmControlPlane: 1, Machine: 1)
+`,
+		},
+		{
+			name:    "depth",
+			options: PrintOptions{Depth: 2, ShowConditions: ShowConditionsNone},
+			want: `⚠️ Cluster/cluster (Degraded)
+  ✅ KubeadmControlPlane/cp (Healthy)
+    ... 1 children below depth 2
+  ⚠️ MachineDeployment/workers (Degraded)
+    ... 1 children below depth 2
+`,
+		},
+		{
+			name:    "types",
+			options: PrintOptions{Types: []analyzer.ComponentType{analyzer.Metal3MachineType}, ShowConditions: ShowConditionsNone},
+			want: `⚠️ Cluster/cluster (Degraded)
+  ⚠️ MachineDeployment/workers (Degraded)
+    ✅ MachineSet/workers-abc (Healthy)
+      ❌ Machine/worker-0 (Failed)
+        ❌ Metal3Machine/worker-0 (Failed)
+      ✅ Machine/worker-1 (Healthy)
+        ✅ Metal3Machine/worker-1 (Healthy)
+`,
+		},
+		{
+			name:    "exclude types",
+			options: PrintOptions{ExcludeTypes: []analyzer.ComponentType{analyzer.MachineSetType, analyzer.KubeadmControlPlaneType}, ShowConditions: ShowConditionsNone},
+			want: `⚠️ Cluster/cluster (Degraded)
+  ⚠️ MachineDeployment/workers (Degraded)
+`,
+		},
+		{
+			name:    "types and only failing",
+			options: PrintOptions{Types: []analyzer.ComponentType{analyzer.MachineType}, OnlyFailing: true, ShowConditions: ShowConditionsNone},
+			want: `⚠️ Cluster/cluster (Degraded)
+  ⚠️ MachineDeployment/workers (Degraded)
+    ✅ MachineSet/workers-abc (Healthy)
+      ❌ Machine/worker-0 (Failed)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := NewTreeBuilder()
+			tb.PrintOptions = tt.options
+			if got := tb.PrintTree(printTree()); got != tt.want {
+				t.Errorf("PrintTree() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
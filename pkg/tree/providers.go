@@ -0,0 +1,62 @@
+package tree
+
+import (
+	"capi-advisor/pkg/analyzer"
+)
+
+// Relationships is implemented by providers whose objects are linked by
+// ownerReferences only, e.g. a VSphereVM created for a VSphereMachine.
+// Objects referenced through infrastructureRef and similar fields are linked
+// generically and need no provider support.
+type Relationships interface {
+	// OwnedKinds maps an owner kind to the kinds of the objects it owns
+	OwnedKinds() map[analyzer.ComponentType][]analyzer.ComponentType
+}
+
+// buildProviderRelationships links the objects owned by a provider kind.
+func (tb *TreeBuilder) buildProviderRelationships(owner *analyzer.Component) {
+	provider, ok := analyzer.ProviderFor(owner.Type).(Relationships)
+	if !ok {
+		return
+	}
+
+	for _, ownedType := range provider.OwnedKinds()[owner.Type] {
+		for _, comp := range tb.ownedBy(owner, ownedType, false) {
+			tb.setParentChild(owner, comp, RelationOwner)
+		}
+	}
+}
+
+// buildReferencedRelationships links the objects controlled by a component
+// of an unknown kind that was fetched through a reference, e.g. the Machines
+// of a control plane provider the advisor has no support for, and the
+// unknown objects it references in turn.
+func (tb *TreeBuilder) buildReferencedRelationships(owner *analyzer.Component) {
+	for _, comp := range tb.ownedBy(owner, "", true) {
+		tb.setParentChild(owner, comp, RelationOwner)
+	}
+
+	for _, ref := range analyzer.References(owner) {
+		if child := tb.findRef(ref); child != nil && analyzer.ReferencedBy(child) != "" {
+			tb.setParentChild(owner, child, RelationReference)
+		}
+	}
+}
+
+// findRef returns the component a reference points to.
+func (tb *TreeBuilder) findRef(ref analyzer.ObjectRef) *analyzer.Component {
+	if comp := tb.findComponent(ref.Name, ref.Namespace, analyzer.ComponentType(ref.Kind)); comp != nil && ref.Matches(comp) {
+		return comp
+	}
+	return nil
+}
+
+// linkRef links the object referenced at the given spec path of a component
+// as its child.
+func (tb *TreeBuilder) linkRef(comp *analyzer.Component, relation Relation, fields ...string) {
+	if ref, found := analyzer.NestedRef(comp, fields...); found {
+		if child := tb.findRef(ref); child != nil {
+			tb.setParentChild(comp, child, relation)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package tree
+
+import (
+	"fmt"
+	"strings"
+
+	"capi-advisor/pkg/analyzer"
+	"capi-advisor/pkg/render"
+)
+
+// PathTo returns the chain of parents from a component up to its Cluster,
+// or up to its root when it belongs to no Cluster. The component is first.
+func (tb *TreeBuilder) PathTo(comp *analyzer.Component) []*analyzer.Component {
+	path := []*analyzer.Component{comp}
+	for current := comp; current.Type != analyzer.ClusterType && current.Parent != nil; current = current.Parent {
+		path = append(path, current.Parent)
+	}
+	return path
+}
+
+// PrintPath renders a path from PathTo top-down, labelling each step with
+// how the object is linked to the one above it.
+func (tb *TreeBuilder) PrintPath(path []*analyzer.Component) string {
+	var result strings.Builder
+	for depth := 0; depth < len(path); depth++ {
+		comp := path[len(path)-1-depth]
+		indent := strings.Repeat("  ", depth)
+		link := ""
+		if depth > 0 {
+			link = fmt.Sprintf("via %s: ", tb.Relation(comp))
+		}
+		result.WriteString(fmt.Sprintf("%s%s%s %s/%s (%s)\n", indent, link, render.StatusIcon(comp.Status),
+			comp.Type, comp.Name, render.Status(comp.Status, string(comp.Status))))
+	}
+	return result.String()
+}
+
+// Impact is what is affected when a component fails or is deleted.
+type Impact struct {
+	Component *analyzer.Component
+	// Dependents are the components linking to the component directly or
+	// indirectly, e.g. the Metal3Machine, Machine and control plane of a
+	// BareMetalHost
+	Dependents []*analyzer.Component
+	// Descendants are the objects below the component, which lose what they
+	// are linked to and are garbage collected when they are owned
+	Descendants []*analyzer.Component
+	// Siblings are the other replicas of a Machine in the chain, which carry
+	// its load or quorum while it is missing
+	Siblings []*analyzer.Component
+	// Notes describe the effect on replica counts and etcd quorum
+	Notes []string
+}
+
+// Impact computes the blast radius of a component from the links between
+// components: everything linking to it depends on it, everything it links
+// to hangs off it, and the replicas next to a Machine in the chain take its
+// place.
+func (tb *TreeBuilder) Impact(comp *analyzer.Component) *Impact {
+	impact := &Impact{Component: comp}
+
+	// Everything that links to the component, directly or indirectly, over
+	// all links rather than the tree only
+	seen := map[*analyzer.Component]bool{comp: true}
+	queue := tb.Parents(comp)
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		if seen[parent] {
+			continue
+		}
+		seen[parent] = true
+		impact.Dependents = append(impact.Dependents, parent)
+		queue = append(queue, tb.Parents(parent)...)
+	}
+
+	var walk func(parent *analyzer.Component)
+	walk = func(parent *analyzer.Component) {
+		for _, child := range tb.Children(parent) {
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			impact.Descendants = append(impact.Descendants, child)
+			walk(child)
+		}
+	}
+	walk(comp)
+
+	// The Machine in the chain, i.e. the component or one of its dependents
+	for _, machine := range append([]*analyzer.Component{comp}, impact.Dependents...) {
+		if machine.Type != analyzer.MachineType || machine.Parent == nil || tb.Relation(machine) != RelationOwner {
+			continue
+		}
+		owner := machine.Parent
+		replicas, healthy := 0, 0
+		for _, sibling := range owner.Children {
+			if sibling.Type != analyzer.MachineType {
+				continue
+			}
+			replicas++
+			if sibling.Status == analyzer.StatusHealthy {
+				healthy++
+			}
+			if sibling != machine {
+				impact.Siblings = append(impact.Siblings, sibling)
+			}
+		}
+
+		remaining := healthy
+		if machine.Status == analyzer.StatusHealthy {
+			remaining--
+		}
+		if strings.HasSuffix(string(owner.Type), "ControlPlane") {
+			quorum := replicas/2 + 1
+			if remaining < quorum {
+				impact.Notes = append(impact.Notes, fmt.Sprintf("%s/%s loses etcd quorum: %d of %d members healthy without %s, %d needed",
+					owner.Type, owner.Name, remaining, replicas, machine.Name, quorum))
+			} else {
+				impact.Notes = append(impact.Notes, fmt.Sprintf("%s/%s keeps etcd quorum: %d of %d members healthy without %s, %d needed",
+					owner.Type, owner.Name, remaining, replicas, machine.Name, quorum))
+			}
+		} else {
+			impact.Notes = append(impact.Notes, fmt.Sprintf("%s/%s runs on %d of %d healthy replicas until %s is replaced",
+				owner.Type, owner.Name, remaining, replicas, machine.Name))
+		}
+		break
+	}
+
+	return impact
+}
+
+// PrintImpact renders an impact as text.
+func (tb *TreeBuilder) PrintImpact(impact *Impact) string {
+	var result strings.Builder
+	section := func(title string, components []*analyzer.Component) {
+		result.WriteString(title + "\n")
+		if len(components) == 0 {
+			result.WriteString("  none\n")
+		}
+		for _, comp := range components {
+			result.WriteString(fmt.Sprintf("  %s %s/%s (%s)\n", render.StatusIcon(comp.Status), comp.Type, comp.Name,
+				render.Status(comp.Status, string(comp.Status))))
+		}
+	}
+
+	section("Dependents that fail or degrade with it:", impact.Dependents)
+	section("Descendants that lose their parent:", impact.Descendants)
+	section("Sibling replicas:", impact.Siblings)
+	for _, note := range impact.Notes {
+		result.WriteString(fmt.Sprintf("\n💡 %s\n", note))
+	}
+	return result.String()
+}
@@ -0,0 +1,171 @@
+package tree
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"capi-advisor/pkg/analyzer"
+)
+
+// controlPlaneComponents returns a Cluster with a KubeadmControlPlane of
+// three Metal3 Machines and a MachineDeployment with two, each Machine with
+// a Metal3Machine and a BareMetalHost. Machines listed in failed are Failed.
+func controlPlaneComponents(failed ...string) map[string]*analyzer.Component {
+	components := make(map[string]*analyzer.Component)
+	add := func(comp *analyzer.Component) *analyzer.Component {
+		components[string(comp.Type)+"/"+comp.Name] = comp
+		return comp
+	}
+
+	cluster := add(newComponent(analyzer.ClusterType, "default", "cluster"))
+	kcp := add(newComponent(analyzer.KubeadmControlPlaneType, "default", "cp"))
+	cluster.Metadata["spec"] = map[string]interface{}{"controlPlaneRef": objectRef(kcp)}
+	md := add(newComponent(analyzer.MachineDeploymentType, "default", "workers"))
+	ms := add(newComponent(analyzer.MachineSetType, "default", "workers-abc"))
+	setOwner(ms, md, analyzer.UID(md))
+
+	machines := map[*analyzer.Component][]string{kcp: {"cp-0", "cp-1", "cp-2"}, ms: {"workers-abc-0", "workers-abc-1"}}
+	for owner, names := range machines {
+		for i, name := range names {
+			machine := add(newComponent(analyzer.MachineType, "default", name))
+			metal3Machine := add(newComponent(analyzer.Metal3MachineType, "default", name))
+			host := add(newComponent(analyzer.BareMetalHostType, "default", fmt.Sprintf("%s-host-%d", owner.Name, i)))
+			setOwner(machine, owner, analyzer.UID(owner))
+			machine.Metadata["spec"] = map[string]interface{}{
+				"clusterName":       cluster.Name,
+				"infrastructureRef": objectRef(metal3Machine),
+			}
+			host.Metadata["spec"] = map[string]interface{}{"consumerRef": objectRef(metal3Machine)}
+		}
+	}
+	for _, name := range failed {
+		components["Machine/"+name].Status = analyzer.StatusFailed
+	}
+	return components
+}
+
+func buildQueryTree(components map[string]*analyzer.Component) *TreeBuilder {
+	var list []*analyzer.Component
+	for _, comp := range components {
+		list = append(list, comp)
+	}
+	tb := NewTreeBuilder()
+	tb.BuildDependencyTree(list)
+	return tb
+}
+
+func componentNames(components []*analyzer.Component) []string {
+	var names []string
+	for _, comp := range components {
+		names = append(names, string(comp.Type)+"/"+comp.Name)
+	}
+	return names
+}
+
+func TestPathTo(t *testing.T) {
+	tests := []struct {
+		name      string
+		component string
+		want      []string
+	}{
+		{
+			name:      "host of a control plane Machine",
+			component: "BareMetalHost/cp-host-1",
+			want:      []string{"BareMetalHost/cp-host-1", "Metal3Machine/cp-1", "Machine/cp-1", "KubeadmControlPlane/cp", "Cluster/cluster"},
+		},
+		{
+			name:      "worker Machine",
+			component: "Machine/workers-abc-0",
+			want:      []string{"Machine/workers-abc-0", "MachineSet/workers-abc", "MachineDeployment/workers"},
+		},
+		{
+			name:      "Cluster",
+			component: "Cluster/cluster",
+			want:      []string{"Cluster/cluster"},
+		},
+	}
+
+	components := controlPlaneComponents()
+	tb := buildQueryTree(components)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := componentNames(tb.PathTo(components[tt.component])); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PathTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImpact(t *testing.T) {
+	tests := []struct {
+		name            string
+		component       string
+		failed          []string
+		wantDependents  []string
+		wantDescendants []string
+		wantSiblings    []string
+		wantNotes       []string
+	}{
+		{
+			name:           "host of a control plane Machine",
+			component:      "BareMetalHost/cp-host-0",
+			wantDependents: []string{"Metal3Machine/cp-0", "Machine/cp-0", "KubeadmControlPlane/cp", "Cluster/cluster"},
+			wantSiblings:   []string{"Machine/cp-1", "Machine/cp-2"},
+			wantNotes:      []string{"KubeadmControlPlane/cp keeps etcd quorum: 2 of 3 members healthy without cp-0, 2 needed"},
+		},
+		{
+			name:            "control plane Machine with a failed sibling",
+			component:       "Machine/cp-0",
+			failed:          []string{"cp-2"},
+			wantDependents:  []string{"KubeadmControlPlane/cp", "Cluster/cluster"},
+			wantDescendants: []string{"Metal3Machine/cp-0", "BareMetalHost/cp-host-0"},
+			wantSiblings:    []string{"Machine/cp-1", "Machine/cp-2"},
+			wantNotes:       []string{"KubeadmControlPlane/cp loses etcd quorum: 1 of 3 members healthy without cp-0, 2 needed"},
+		},
+		{
+			name:            "failed control plane Machine",
+			component:       "Machine/cp-0",
+			failed:          []string{"cp-0"},
+			wantDependents:  []string{"KubeadmControlPlane/cp", "Cluster/cluster"},
+			wantDescendants: []string{"Metal3Machine/cp-0", "BareMetalHost/cp-host-0"},
+			wantSiblings:    []string{"Machine/cp-1", "Machine/cp-2"},
+			wantNotes:       []string{"KubeadmControlPlane/cp keeps etcd quorum: 2 of 3 members healthy without cp-0, 2 needed"},
+		},
+		{
+			name:      "worker Machine",
+			component: "Machine/workers-abc-1",
+			// The Cluster is linked through spec.clusterName
+			wantDependents:  []string{"MachineSet/workers-abc", "Cluster/cluster", "MachineDeployment/workers"},
+			wantDescendants: []string{"Metal3Machine/workers-abc-1", "BareMetalHost/workers-abc-host-1"},
+			wantSiblings:    []string{"Machine/workers-abc-0"},
+			wantNotes:       []string{"MachineSet/workers-abc runs on 1 of 2 healthy replicas until workers-abc-1 is replaced"},
+		},
+		{
+			name:            "MachineSet",
+			component:       "MachineSet/workers-abc",
+			wantDependents:  []string{"MachineDeployment/workers"},
+			wantDescendants: []string{"Machine/workers-abc-0", "Metal3Machine/workers-abc-0", "BareMetalHost/workers-abc-host-0", "Machine/workers-abc-1", "Metal3Machine/workers-abc-1", "BareMetalHost/workers-abc-host-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			components := controlPlaneComponents(tt.failed...)
+			impact := buildQueryTree(components).Impact(components[tt.component])
+
+			if got := componentNames(impact.Dependents); !reflect.DeepEqual(got, tt.wantDependents) {
+				t.Errorf("Dependents = %v, want %v", got, tt.wantDependents)
+			}
+			if got := componentNames(impact.Descendants); !reflect.DeepEqual(got, tt.wantDescendants) {
+				t.Errorf("Descendants = %v, want %v", got, tt.wantDescendants)
+			}
+			if got := componentNames(impact.Siblings); !reflect.DeepEqual(got, tt.wantSiblings) {
+				t.Errorf("Siblings = %v, want %v", got, tt.wantSiblings)
+			}
+			if !reflect.DeepEqual(impact.Notes, tt.wantNotes) {
+				t.Errorf("Notes = %q, want %q", impact.Notes, tt.wantNotes)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package tree
+
+// Relation is the kind of link between a parent and a child in the tree.
+type Relation string
+
+const (
+	// RelationOwner links an object to its owner, e.g. a Machine to its MachineSet
+	RelationOwner Relation = "owner"
+	// RelationCluster links an object to the Cluster named in its spec.clusterName
+	RelationCluster Relation = "cluster"
+	// RelationInfrastructureRef links a Cluster or Machine to its infrastructure object
+	RelationInfrastructureRef Relation = "infrastructureRef"
+	// RelationBootstrap links a Machine to its bootstrap config
+	RelationBootstrap Relation = "bootstrap"
+	// RelationControlPlaneRef links a Cluster to its control plane
+	RelationControlPlaneRef Relation = "controlPlaneRef"
+	// RelationConsumerRef links a Metal3Machine to the BareMetalHost it consumes
+	RelationConsumerRef Relation = "consumerRef"
+	// RelationReference links an object to any other object it references by name
+	RelationReference Relation = "reference"
+)